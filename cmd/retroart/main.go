@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 
@@ -8,7 +9,12 @@ import (
 )
 
 func main() {
-	application := app.New()
+	var options app.Options
+	flag.StringVar(&options.RecordInput, "record-input", "", "write the input events of every frame to this file")
+	flag.StringVar(&options.PlaybackInput, "playback-input", "", "replay a recording made with -record-input instead of the devices")
+	flag.Parse()
+
+	application := app.New(options)
 	if err := application.Init(); err != nil {
 		fmt.Printf("Error initializing application: %v\n", err)
 		os.Exit(1)
@@ -7,10 +7,10 @@ toolchain go1.24.6
 require (
 	github.com/TotallyGamerJet/clay v0.0.7
 	github.com/veandco/go-sdl2 v0.4.40
+	golang.org/x/image v0.29.0
 )
 
 require (
 	github.com/ebitengine/purego v0.9.0-alpha.9 // indirect
 	github.com/gotranspile/cxgo v0.5.2 // indirect
-	golang.org/x/image v0.29.0 // indirect
 )
@@ -2,29 +2,71 @@ package app
 
 import (
 	"fmt"
+	"log"
+	"os"
+	"time"
 
 	"github.com/veandco/go-sdl2/sdl"
 	"github.com/veandco/go-sdl2/ttf"
 
+	"retroart-sdl2/internal/config"
 	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/download"
+	"retroart-sdl2/internal/httpclient"
 	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/job"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/library/dat"
+	"retroart-sdl2/internal/library/hash"
+	"retroart-sdl2/internal/mix"
+	"retroart-sdl2/internal/output"
+	"retroart-sdl2/internal/review"
+	"retroart-sdl2/internal/scrapedb"
+	"retroart-sdl2/internal/scraper"
 	"retroart-sdl2/internal/screen"
 	"retroart-sdl2/internal/theme"
 	"retroart-sdl2/internal/ui"
 )
 
+// Options are the command line settings of a run
+type Options struct {
+	RecordInput   string // File the input events of every frame are written to
+	PlaybackInput string // Recording replayed instead of the devices
+}
+
 type App struct {
-	window    *sdl.Window
-	renderer  *sdl.Renderer
-	running   bool
-	screenMgr *screen.Manager
+	window     *sdl.Window
+	renderer   *sdl.Renderer
+	running    bool
+	screenMgr  *screen.Manager
+	config     *config.Config
+	scrapeDB   *scrapedb.DB
+	translator *input.Translator
+	options    Options
+	frame      uint64 // Frames since the start of Run
+	recorder   *input.Recorder
+	playback   *input.Playback
 }
 
-func New() *App {
-	return &App{}
+func New(options Options) *App {
+	return &App{options: options}
 }
 
 func (app *App) Init() error {
+	cfg, err := config.Load(config.DefaultPath())
+	if err != nil {
+		log.Printf("Error loading config, using defaults: %v", err)
+	}
+	app.config = cfg
+	core.SetWindowSize(cfg.Window.Width, cfg.Window.Height)
+
+	// The environment overrides the ROMs root for a run without touching
+	// the config file
+	romsRoot := cfg.RomsRoot
+	if root := os.Getenv("RETROART_ROMS_ROOT"); root != "" {
+		romsRoot = root
+	}
+
 	if err := sdl.Init(sdl.INIT_VIDEO | sdl.INIT_JOYSTICK | sdl.INIT_GAMECONTROLLER); err != nil {
 		return fmt.Errorf("error initializing SDL: %v", err)
 	}
@@ -54,6 +96,7 @@ func (app *App) Init() error {
 
 	// Create and initialize font system
 	fontSystem := theme.NewFontSystem()
+	fontSystem.SetPreferredFont(cfg.Theme.FontPath)
 	if err := fontSystem.InitializeFonts(); err != nil {
 		return fmt.Errorf("error initializing font system: %v", err)
 	}
@@ -64,9 +107,131 @@ func (app *App) Init() error {
 	}
 
 	app.screenMgr = screen.NewManager(layout)
+	app.translator = input.NewTranslator(cfg.Input)
+	if err := app.openInputFiles(); err != nil {
+		return err
+	}
+
+	datIndex, err := dat.LoadDir(cfg.DatDir, cfg.CachePath("dats.gob"))
+	if err != nil {
+		log.Printf("Error loading DAT files: %v", err)
+	}
+
+	scanner := library.NewScanner(romsRoot)
+	scanner.SetDatIndex(datIndex)
+
+	systems, err := scanner.Scan()
+	if err != nil {
+		log.Printf("Error scanning ROMs: %v", err)
+	}
+
+	maxRetries := cfg.HTTP.MaxRetries
+	if maxRetries == 0 {
+		maxRetries = -1
+	}
+	httpClient := httpclient.New(httpclient.Options{
+		UserAgent:  "RetroArt",
+		Limit:      httpclient.Limit{Rate: cfg.HTTP.RequestsPerSecond, Burst: 2},
+		MaxRetries: maxRetries,
+		CacheDir:   cfg.CachePath("http"),
+		CacheTTL:   time.Duration(cfg.HTTP.CacheTTLHours) * time.Hour,
+	})
+	downloader := download.New(httpClient.HTTPClient(), cfg.CachePath("downloads"))
+	go func() {
+		// Expired entries are only kept for revalidation for a while
+		if removed, err := httpClient.PruneCache(4 * time.Duration(cfg.HTTP.CacheTTLHours) * time.Hour); err != nil {
+			log.Printf("Error pruning HTTP cache: %v", err)
+		} else if removed > 0 {
+			log.Printf("Pruned %d HTTP cache entries", removed)
+		}
+		if removed, err := downloader.Prune(7 * 24 * time.Hour); err != nil {
+			log.Printf("Error pruning partial downloads: %v", err)
+		} else if removed > 0 {
+			log.Printf("Pruned %d partial downloads", removed)
+		}
+	}()
+
+	provider, err := newProvider(cfg, httpClient)
+	if err != nil {
+		return fmt.Errorf("error creating scraper provider: %v", err)
+	}
 
-	app.screenMgr.AddScreen("home", screen.NewHome())
+	mediaTypes := make([]scraper.MediaType, 0, len(cfg.MediaTypes))
+	for _, mediaType := range cfg.MediaTypes {
+		mediaTypes = append(mediaTypes, scraper.MediaType(mediaType))
+	}
+
+	primary := scraper.MediaBoxFront
+	if len(mediaTypes) > 0 {
+		primary = mediaTypes[0]
+	}
+	artLayout, err := output.NewLayout(cfg.OutputLayout, output.Options{ArtDir: cfg.ArtDir, Primary: primary})
+	if err != nil {
+		log.Printf("Error creating output layout, using %s: %v", output.LayoutTrimUI, err)
+		artLayout, _ = output.NewLayout(output.LayoutTrimUI, output.Options{Primary: primary})
+	}
+
+	var mixer job.Mixer
+	templates, err := mix.LoadTemplates(cfg.TemplateDir)
+	if err != nil {
+		log.Printf("Error loading mix templates: %v", err)
+	}
+	if tmpl, ok := templates[cfg.MixTemplate]; ok {
+		mixer = mix.NewMixer(tmpl)
+	} else if tmpl, ok := templates[mix.DefaultTemplate]; ok {
+		log.Printf("Mix template %q not found, using %s", cfg.MixTemplate, mix.DefaultTemplate)
+		mixer = mix.NewMixer(tmpl)
+	}
+
+	reviewStore, err := review.Load(cfg.CachePath("review.json"))
+	if err != nil {
+		log.Printf("Error loading review decisions: %v", err)
+	}
+
+	scrapeDB, err := scrapedb.Open(cfg.CachePath("scrape.db"))
+	if err != nil {
+		log.Printf("Error opening scrape database, previous results will not be reused: %v", err)
+	}
+	app.scrapeDB = scrapeDB
+
+	writer := output.NewWriter(artLayout, cfg.Overwrite)
+	regions := cfg.RegionResolver()
+	var exporters []job.Exporter
+	if cfg.ExportGamelist {
+		exporters = append(exporters, output.NewGamelist(writer, romsRoot, cfg.CachePath("gamelists"), cfg.MetadataPrefs(), regions))
+	}
+
+	engine := job.NewEngine(job.Config{
+		Workers:        cfg.Workers,
+		ProviderLimits: map[string]int{config.ProviderScreenScraper: cfg.ScreenScraper.Threads},
+		Providers:      []scraper.Provider{provider},
+		Identifier: &scraper.Identifier{
+			Hasher: hash.NewHasher(cfg.CachePath("hashes.json")),
+			Index:  datIndex,
+		},
+		MediaTypes:     mediaTypes,
+		Regions:        regions,
+		Sink:           writer,
+		Mixer:          mixer,
+		MatchThreshold: cfg.MatchThreshold,
+		Decisions:      reviewStore,
+		DB:             scrapeDB,
+		HTTPClient:     httpClient.HTTPClient(),
+		Downloader:     downloader,
+		QueuePath:      cfg.CachePath("queue.json"),
+		Exporters:      exporters,
+	})
+	app.screenMgr.SetEngine(engine)
+
+	details := screen.NewGameDetail(systems, engine, writer, cfg.MetadataPrefs(), regions)
+	app.screenMgr.AddScreen("home", screen.NewHome(systems, engine, details))
 	app.screenMgr.AddScreen("second", screen.NewSecond())
+	app.screenMgr.AddScreen("game", details)
+	app.screenMgr.AddScreen("progress", screen.NewProgress(engine, httpClient.Quotas()))
+	app.screenMgr.AddScreen("settings", screen.NewSettings(cfg, config.DefaultPath()))
+	app.screenMgr.AddScreen("regions", screen.NewRegionOrder(cfg, config.DefaultPath()))
+	app.screenMgr.AddScreen("review", screen.NewReview(reviewStore, engine, details))
+	app.screenMgr.AddScreen("audit", screen.NewAudit(systems, engine, writer, mediaTypes))
 
 	app.screenMgr.SetCurrentScreen("home")
 
@@ -74,14 +239,33 @@ func (app *App) Init() error {
 	return nil
 }
 
+// newProvider creates the scraper provider selected in the config
+func newProvider(cfg *config.Config, client *httpclient.Client) (scraper.Provider, error) {
+	switch cfg.Provider {
+	case config.ProviderLibretro:
+		return scraper.NewLibretro(scraper.LibretroConfig{
+			BaseURL: cfg.Libretro.BaseURL,
+			Client:  client,
+		})
+	default:
+		return scraper.NewScreenScraper(scraper.ScreenScraperConfig{
+			BaseURL:     cfg.ScreenScraper.BaseURL,
+			DevID:       cfg.ScreenScraper.DevID,
+			DevPassword: cfg.ScreenScraper.DevPassword,
+			Username:    cfg.ScreenScraper.Username,
+			Password:    cfg.ScreenScraper.Password,
+			Client:      client,
+		}), nil
+	}
+}
+
 func (app *App) Run() {
 	// targetFrameTime := uint64(1000 / core.FPS) // ms por frame
-	inputCh := input.Initialize()
 
 	for app.running {
 		// frameStart := sdl.GetTicks64()
 
-		app.handleEvents(inputCh)
+		app.handleEvents()
 		app.update()
 		app.render()
 
@@ -93,22 +277,80 @@ func (app *App) Run() {
 	}
 }
 
-func (app *App) handleEvents(inputCh <-chan input.InputEvent) {
-	select {
-	case inputEvent := <-inputCh:
-		if inputEvent.Pressed {
-			// Passar diretamente o InputType sem conversão desnecessária
-			app.screenMgr.HandleInput(inputEvent.Type)
+// openInputFiles prepara a gravação ou a reprodução dos inputs pedidas na
+// linha de comando
+func (app *App) openInputFiles() error {
+	if app.options.PlaybackInput != "" {
+		records, err := input.LoadRecording(app.options.PlaybackInput)
+		if err != nil {
+			return err
 		}
-	default:
+		app.playback = input.NewPlayback(records)
+		log.Printf("Input: replaying %d events from %s", len(records), app.options.PlaybackInput)
+	}
+	if app.options.RecordInput != "" {
+		recorder, err := input.NewRecorder(app.options.RecordInput)
+		if err != nil {
+			return err
+		}
+		app.recorder = recorder
+		log.Printf("Input: recording to %s", app.options.RecordInput)
 	}
+	return nil
+}
 
+// handleEvents bombeia todos os eventos SDL pendentes e entrega às telas
+// todos os inputs do frame. Durante uma reprodução os dispositivos são
+// ignorados e os inputs vêm da gravação, presos ao número do frame.
+func (app *App) handleEvents() {
 	for event := sdl.PollEvent(); event != nil; event = sdl.PollEvent() {
 		switch event.(type) {
 		case *sdl.QuitEvent:
 			app.running = false
+		default:
+			app.translator.Translate(event)
 		}
 	}
+	app.translator.Tick(sdl.GetTicks64())
+	frame := app.frame
+	app.frame++
+
+	for _, device := range app.translator.DrainDevices() {
+		if device.Connected {
+			app.screenMgr.ShowToast("Controller connected: " + device.Name)
+		} else {
+			app.screenMgr.ShowToast("Controller disconnected: " + device.Name)
+		}
+	}
+
+	events := app.translator.Drain()
+	if app.playback != nil {
+		events = app.playback.Next()
+		if app.playback.Done() {
+			log.Println("Input: playback finished, devices are live again")
+			app.playback = nil
+		}
+	}
+	if app.recorder != nil {
+		if err := app.recorder.Record(frame, events); err != nil {
+			log.Printf("Input: %v, recording stopped", err)
+			app.closeRecorder()
+		}
+	}
+
+	for _, inputEvent := range events {
+		app.screenMgr.HandleEvent(inputEvent)
+	}
+}
+
+func (app *App) closeRecorder() {
+	if app.recorder == nil {
+		return
+	}
+	if err := app.recorder.Close(); err != nil {
+		log.Printf("Error closing input recording: %v", err)
+	}
+	app.recorder = nil
 }
 
 func (app *App) update() {
@@ -123,6 +365,15 @@ func (app *App) render() {
 }
 
 func (app *App) Cleanup() {
+	if app.scrapeDB != nil {
+		if err := app.scrapeDB.Close(); err != nil {
+			log.Printf("Error closing scrape database: %v", err)
+		}
+	}
+	app.closeRecorder()
+	if app.translator != nil {
+		app.translator.Close()
+	}
 	if app.renderer != nil {
 		app.renderer.Destroy()
 	}
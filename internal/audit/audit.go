@@ -0,0 +1,255 @@
+// Package audit checks the artwork of the library against the active output
+// layout: images left behind by deleted ROMs, games without art, and files
+// that are empty, unreadable or not sized for the frontend.
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/job"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/output"
+	"retroart-sdl2/internal/scraper"
+)
+
+// mediaExtensions are the files the audit considers artwork
+var mediaExtensions = []string{".png", ".jpg", ".jpeg", ".webp", ".gif", ".mp4"}
+
+// Missing lists the media types a game lacks
+type Missing struct {
+	Game   library.Game
+	System string // Display name of the system
+	Types  []scraper.MediaType
+}
+
+// File is an artwork file with a problem
+type File struct {
+	Path      string
+	Game      library.Game
+	MediaType scraper.MediaType
+	Reason    string // Human readable, e.g. "empty file" or "640x480, expected 500x500"
+}
+
+// Report is the result of an audit
+type Report struct {
+	Games     int
+	Orphans   []string // Files in media folders that belong to no ROM
+	Missing   []Missing
+	Broken    []File // Empty or undecodable files
+	WrongSize []File // Images not sized for the layout
+}
+
+// MissingCount returns how many games lack the media type
+func (r *Report) MissingCount(mediaType scraper.MediaType) int {
+	count := 0
+	for _, missing := range r.Missing {
+		if slices.Contains(missing.Types, mediaType) {
+			count++
+		}
+	}
+	return count
+}
+
+// Tasks returns a job task for every game with missing media
+func (r *Report) Tasks() []job.Task {
+	tasks := make([]job.Task, 0, len(r.Missing))
+	for i, missing := range r.Missing {
+		tasks = append(tasks, job.Task{ID: i, Game: missing.Game, System: missing.System})
+	}
+	return tasks
+}
+
+// Options configures an audit
+type Options struct {
+	Writer     *output.Writer      // Layout the art is checked against
+	MediaTypes []scraper.MediaType // Media every game should have
+}
+
+// Run audits the art of the given systems. It reads every image, so it is
+// meant to run in the background; ctx cancels it.
+func Run(ctx context.Context, systems []library.System, opts Options) (*Report, error) {
+	layout := opts.Writer.Layout
+	report := &Report{}
+
+	// Every path a media of a ROM may use, orphans are the other files
+	expected := make(map[string]bool)
+	var dirs []string
+	addDir := func(dir string) {
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	allTypes := append(slices.Clone(scraper.AllMediaTypes), scraper.MediaMixed)
+
+	for _, system := range systems {
+		for _, dir := range layout.Dirs(system) {
+			addDir(dir)
+		}
+
+		for _, game := range system.Games {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+			report.Games++
+
+			for _, mediaType := range allTypes {
+				for _, path := range layout.Paths(game, mediaType, "") {
+					expected[path] = true
+				}
+			}
+
+			var missing []scraper.MediaType
+			for _, mediaType := range opts.MediaTypes {
+				if len(layout.Paths(game, mediaType, "")) == 0 {
+					continue
+				}
+				path, ok := opts.Writer.Find(game, mediaType)
+				if !ok {
+					missing = append(missing, mediaType)
+					continue
+				}
+				reason, broken := checkFile(path, layout, mediaType)
+				file := File{Path: path, Game: game, MediaType: mediaType, Reason: reason}
+				switch {
+				case broken:
+					report.Broken = append(report.Broken, file)
+				case reason != "":
+					report.WrongSize = append(report.WrongSize, file)
+				}
+			}
+			if len(missing) > 0 {
+				report.Missing = append(report.Missing, Missing{Game: game, System: system.Name(), Types: missing})
+			}
+		}
+	}
+
+	for _, dir := range dirs {
+		entries, err := os.ReadDir(dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			path := filepath.Join(dir, entry.Name())
+			if entry.IsDir() || expected[path] || !isMedia(entry.Name()) {
+				continue
+			}
+			report.Orphans = append(report.Orphans, path)
+		}
+	}
+	slices.Sort(report.Orphans)
+
+	return report, nil
+}
+
+// checkFile returns why a media file is broken or, when broken is false and
+// the reason is not empty, why its size does not suit the layout
+func checkFile(path string, layout output.Layout, mediaType scraper.MediaType) (reason string, broken bool) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err.Error(), true
+	}
+	if info.Size() == 0 {
+		return "empty file", true
+	}
+
+	opts, isImage := layout.Image(mediaType)
+	if !isImage {
+		return "", false
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err.Error(), true
+	}
+	defer file.Close()
+	img, _, err := imaging.Decode(file)
+	if err != nil {
+		return "cannot be decoded", true
+	}
+
+	bounds := img.Bounds()
+	if !fitsBox(bounds.Dx(), bounds.Dy(), opts) {
+		return fmt.Sprintf("%dx%d, expected %s", bounds.Dx(), bounds.Dy(), boxName(opts)), false
+	}
+	return "", false
+}
+
+// fitsBox reports whether an image of w x h pixels is what the imaging
+// options produce: exactly the box when padded, inside it otherwise
+func fitsBox(w, h int, opts imaging.Options) bool {
+	boxW, boxH := opts.Width, opts.Height
+	if boxW <= 0 {
+		boxW = w
+	}
+	if boxH <= 0 {
+		boxH = h
+	}
+
+	if opts.Mode == imaging.ModePad {
+		return w == boxW && h == boxH
+	}
+	if w > boxW || h > boxH {
+		return false
+	}
+	// Upscaled images touch the box on at least one side
+	return !opts.AllowUpscale || w == boxW || h == boxH
+}
+
+func boxName(opts imaging.Options) string {
+	switch {
+	case opts.Width <= 0:
+		return fmt.Sprintf("height %d", opts.Height)
+	case opts.Height <= 0:
+		return fmt.Sprintf("width %d", opts.Width)
+	case opts.Mode == imaging.ModePad:
+		return fmt.Sprintf("%dx%d", opts.Width, opts.Height)
+	default:
+		return fmt.Sprintf("up to %dx%d", opts.Width, opts.Height)
+	}
+}
+
+func isMedia(name string) bool {
+	return slices.Contains(mediaExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// DeleteOrphans removes the orphaned files of the report and returns how
+// many were deleted
+func (r *Report) DeleteOrphans() (int, error) {
+	deleted, err := remove(r.Orphans)
+	r.Orphans = r.Orphans[deleted:]
+	return deleted, err
+}
+
+// DeleteBroken removes the empty and undecodable files of the report, so
+// the next scrape downloads them again whatever the overwrite policy
+func (r *Report) DeleteBroken() (int, error) {
+	paths := make([]string, len(r.Broken))
+	for i, file := range r.Broken {
+		paths[i] = file.Path
+	}
+	deleted, err := remove(paths)
+	r.Broken = r.Broken[deleted:]
+	return deleted, err
+}
+
+// remove deletes paths in order and stops at the first failure. Files
+// already gone count as deleted.
+func remove(paths []string) (int, error) {
+	for i, path := range paths {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return i, fmt.Errorf("failed to delete %s: %w", path, err)
+		}
+	}
+	return len(paths), nil
+}
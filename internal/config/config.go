@@ -0,0 +1,389 @@
+// Package config loads and saves the RetroArt configuration file stored next
+// to the binary.
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/fsutil"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/match"
+	"retroart-sdl2/internal/scraper"
+)
+
+// FileName is the name of the configuration file next to the binary
+const FileName = "config.json"
+
+// Version is the current configuration schema version
+const Version = 1
+
+// Provider names accepted in Config.Provider
+const (
+	ProviderScreenScraper = "screenscraper"
+	ProviderLibretro      = "libretro"
+)
+
+// OverwritePolicy decides what happens when an artwork file already exists
+type OverwritePolicy string
+
+const (
+	OverwriteNever     OverwritePolicy = "never"
+	OverwriteAlways    OverwritePolicy = "always"
+	OverwriteIfSmaller OverwritePolicy = "only-if-smaller"
+	OverwriteIfMissing OverwritePolicy = "only-if-missing"
+)
+
+// OverwritePolicies lists the accepted overwrite policies in display order
+var OverwritePolicies = []OverwritePolicy{OverwriteIfMissing, OverwriteIfSmaller, OverwriteAlways, OverwriteNever}
+
+// ScreenScraperConfig holds the ScreenScraper endpoint and credentials
+type ScreenScraperConfig struct {
+	BaseURL     string `json:"base_url,omitempty"`
+	DevID       string `json:"dev_id"`
+	DevPassword string `json:"dev_password"`
+	Username    string `json:"username"`
+	Password    string `json:"password"`
+	Threads     int    `json:"threads"`
+}
+
+// LibretroConfig holds the libretro-thumbnails mirror
+type LibretroConfig struct {
+	BaseURL string `json:"base_url,omitempty"`
+}
+
+// WindowConfig holds the window size in pixels
+type WindowConfig struct {
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// ThemeConfig holds visual settings
+type ThemeConfig struct {
+	FontPath string `json:"font_path,omitempty"` // Tried before the bundled fonts
+}
+
+// Acceleration curves accepted in InputConfig.RepeatCurve. They shape how
+// the repeat interval of a held direction shrinks towards RepeatMinMs.
+const (
+	RepeatCurveNone   = "none"    // Repeats stay at DirectionalThrottleMs
+	RepeatCurveLinear = "linear"  // Speeds up evenly
+	RepeatCurveEaseIn = "ease-in" // Speeds up slowly at first, then faster
+)
+
+// InputConfig holds input settings
+type InputConfig struct {
+	DirectionalThrottleMs uint64 `json:"directional_throttle_ms"` // Interval of the first repeats of a held direction
+	RepeatDelayMs         uint64 `json:"repeat_delay_ms"`         // Hold time before a direction starts repeating
+	RepeatMinMs           uint64 `json:"repeat_min_ms"`           // Fastest repeat interval
+	RepeatAccelMs         uint64 `json:"repeat_accel_ms"`         // Repeating time until the fastest interval
+	RepeatCurve           string `json:"repeat_curve"`            // One of the RepeatCurve constants
+	LongPressMs           uint64 `json:"long_press_ms"`           // Hold time of a long press
+	AxisThreshold         int    `json:"axis_threshold"`          // Stick or trigger travel that presses, up to 32767
+	AxisDeadzone          int    `json:"axis_deadzone"`           // Travel below which a pressed stick is released
+}
+
+// HTTPConfig tunes the HTTP client shared by the providers
+type HTTPConfig struct {
+	RequestsPerSecond float64 `json:"requests_per_second"` // Per host
+	MaxRetries        int     `json:"max_retries"`
+	CacheTTLHours     int     `json:"cache_ttl_hours"` // Age below which cached API answers are reused
+}
+
+// MetadataConfig orders the languages and regions of localized metadata,
+// per field. Empty region lists follow Regions.
+type MetadataConfig struct {
+	TitleRegions       []string `json:"title_regions"`
+	SynopsisLanguages  []string `json:"synopsis_languages"`
+	GenreLanguages     []string `json:"genre_languages"`
+	ReleaseDateRegions []string `json:"release_date_regions"`
+}
+
+// Config is the full RetroArt configuration
+type Config struct {
+	Version         int                 `json:"version"`
+	RomsRoot        string              `json:"roms_root"`
+	ArtDir          string              `json:"art_dir"`
+	DatDir          string              `json:"dat_dir"`
+	TemplateDir     string              `json:"template_dir"` // Mix templates overriding the built-in ones
+	CacheDir        string              `json:"cache_dir"`
+	Provider        string              `json:"provider"`
+	ScreenScraper   ScreenScraperConfig `json:"screenscraper"`
+	Libretro        LibretroConfig      `json:"libretro"`
+	Regions         []string            `json:"regions"`          // Region priority of media and titles
+	RegionFallbacks map[string][]string `json:"region_fallbacks"` // Regions tried after a region
+	Metadata        MetadataConfig      `json:"metadata"`
+	MediaTypes      []string            `json:"media_types"`
+	MixTemplate     string              `json:"mix_template"`
+	MatchThreshold  float64             `json:"match_threshold"` // Minimum confidence of name matches, 0-1
+	OutputLayout    string              `json:"output_layout"`   // Frontend the artwork is written for
+	ExportGamelist  bool                `json:"export_gamelist"` // Also merge metadata into gamelist.xml
+	Overwrite       OverwritePolicy     `json:"overwrite"`
+	Workers         int                 `json:"workers"`
+	Window          WindowConfig        `json:"window"`
+	Theme           ThemeConfig         `json:"theme"`
+	Input           InputConfig         `json:"input"`
+	HTTP            HTTPConfig          `json:"http"`
+}
+
+// DefaultPath returns the config file path next to the binary
+func DefaultPath() string {
+	return core.DataPath(FileName)
+}
+
+// Default returns the configuration used when no file exists
+func Default() *Config {
+	return &Config{
+		Version:     Version,
+		RomsRoot:    library.DefaultRomsRoot,
+		ArtDir:      core.DataPath("media"),
+		DatDir:      core.DataPath("dats"),
+		TemplateDir: core.DataPath("templates"),
+		CacheDir:    core.DataPath("cache"),
+		Provider:    ProviderScreenScraper,
+		ScreenScraper: ScreenScraperConfig{
+			Threads: 1,
+		},
+		Regions:        []string{"us", "wor", "eu", "jp", "ss"},
+		MediaTypes:     []string{"box-front"},
+		MixTemplate:    "classic",
+		MatchThreshold: match.DefaultThreshold,
+		OutputLayout:   "trimui",
+		Overwrite:      OverwriteIfMissing,
+		Workers:        2,
+		Window: WindowConfig{
+			Width:  core.DEFAULT_WINDOW_WIDTH,
+			Height: core.DEFAULT_WINDOW_HEIGHT,
+		},
+		Input: InputConfig{
+			DirectionalThrottleMs: 150,
+			RepeatDelayMs:         300,
+			RepeatMinMs:           40,
+			RepeatAccelMs:         2000,
+			RepeatCurve:           RepeatCurveEaseIn,
+			LongPressMs:           600,
+			AxisThreshold:         16000,
+			AxisDeadzone:          8000,
+		},
+		HTTP: HTTPConfig{
+			RequestsPerSecond: 2,
+			MaxRetries:        4,
+			CacheTTLHours:     7 * 24,
+		},
+		Metadata: MetadataConfig{
+			SynopsisLanguages: []string{"en"},
+			GenreLanguages:    []string{"en"},
+		},
+		RegionFallbacks: map[string][]string{
+			"us":  {"wor"},
+			"eu":  {"wor"},
+			"jp":  {"wor"},
+			"ca":  {"us"},
+			"br":  {"us"},
+			"uk":  {"eu"},
+			"fr":  {"eu"},
+			"de":  {"eu"},
+			"sp":  {"eu"},
+			"it":  {"eu"},
+			"nl":  {"eu"},
+			"se":  {"eu"},
+			"ru":  {"eu"},
+			"au":  {"eu"},
+			"asi": {"jp"},
+			"kr":  {"asi"},
+			"cn":  {"asi"},
+			"tw":  {"asi"},
+		},
+	}
+}
+
+// Load reads the configuration at path. A missing file yields the defaults;
+// fields absent from the file keep their default values. A file that cannot
+// be parsed is moved aside and the defaults are returned with the error, so
+// saving them later does not destroy the user's edits.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return cfg, nil
+		}
+		return cfg, fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		err = fmt.Errorf("failed to parse config %s: %w", path, err)
+		if renameErr := os.Rename(path, brokenPath(path)); renameErr != nil {
+			return Default(), errors.Join(err, fmt.Errorf("failed to back up config: %w", renameErr))
+		}
+		return Default(), fmt.Errorf("%w (moved to %s)", err, brokenPath(path))
+	}
+
+	cfg.normalize()
+	return cfg, nil
+}
+
+// brokenPath returns where Load moves an unparsable config file
+func brokenPath(path string) string {
+	return path + ".broken"
+}
+
+// normalize replaces invalid values with defaults
+func (cfg *Config) normalize() {
+	defaults := Default()
+
+	if cfg.Version == 0 {
+		cfg.Version = Version
+	}
+	if !cfg.Overwrite.Valid() {
+		cfg.Overwrite = defaults.Overwrite
+	}
+	if cfg.Provider != ProviderScreenScraper && cfg.Provider != ProviderLibretro {
+		cfg.Provider = defaults.Provider
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = defaults.Workers
+	}
+	if cfg.Window.Width <= 0 || cfg.Window.Height <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.ScreenScraper.Threads <= 0 {
+		cfg.ScreenScraper.Threads = defaults.ScreenScraper.Threads
+	}
+	if cfg.Input.DirectionalThrottleMs == 0 {
+		cfg.Input.DirectionalThrottleMs = defaults.Input.DirectionalThrottleMs
+	}
+	if cfg.Input.RepeatDelayMs == 0 {
+		cfg.Input.RepeatDelayMs = defaults.Input.RepeatDelayMs
+	}
+	if cfg.Input.RepeatMinMs == 0 || cfg.Input.RepeatMinMs > cfg.Input.DirectionalThrottleMs {
+		cfg.Input.RepeatMinMs = min(defaults.Input.RepeatMinMs, cfg.Input.DirectionalThrottleMs)
+	}
+	if cfg.Input.RepeatAccelMs == 0 {
+		cfg.Input.RepeatAccelMs = defaults.Input.RepeatAccelMs
+	}
+	switch cfg.Input.RepeatCurve {
+	case RepeatCurveNone, RepeatCurveLinear, RepeatCurveEaseIn:
+	default:
+		cfg.Input.RepeatCurve = defaults.Input.RepeatCurve
+	}
+	if cfg.Input.LongPressMs == 0 {
+		cfg.Input.LongPressMs = defaults.Input.LongPressMs
+	}
+	if cfg.Input.AxisThreshold <= 0 || cfg.Input.AxisThreshold > 32767 {
+		cfg.Input.AxisThreshold = defaults.Input.AxisThreshold
+	}
+	if cfg.Input.AxisDeadzone < 0 || cfg.Input.AxisDeadzone >= cfg.Input.AxisThreshold {
+		cfg.Input.AxisDeadzone = cfg.Input.AxisThreshold / 2
+	}
+	if cfg.CacheDir == "" {
+		cfg.CacheDir = defaults.CacheDir
+	}
+	if cfg.TemplateDir == "" {
+		cfg.TemplateDir = defaults.TemplateDir
+	}
+	if cfg.MixTemplate == "" {
+		cfg.MixTemplate = defaults.MixTemplate
+	}
+	if cfg.MatchThreshold <= 0 || cfg.MatchThreshold > 1 {
+		cfg.MatchThreshold = defaults.MatchThreshold
+	}
+	if cfg.OutputLayout == "" {
+		cfg.OutputLayout = defaults.OutputLayout
+	}
+	if cfg.HTTP.RequestsPerSecond <= 0 {
+		cfg.HTTP.RequestsPerSecond = defaults.HTTP.RequestsPerSecond
+	}
+	if cfg.HTTP.MaxRetries < 0 {
+		cfg.HTTP.MaxRetries = 0
+	}
+	if cfg.HTTP.CacheTTLHours <= 0 {
+		cfg.HTTP.CacheTTLHours = defaults.HTTP.CacheTTLHours
+	}
+	if cfg.RegionFallbacks == nil {
+		cfg.RegionFallbacks = defaults.RegionFallbacks
+	}
+	if len(cfg.Metadata.SynopsisLanguages) == 0 {
+		cfg.Metadata.SynopsisLanguages = defaults.Metadata.SynopsisLanguages
+	}
+	if len(cfg.Metadata.GenreLanguages) == 0 {
+		cfg.Metadata.GenreLanguages = defaults.Metadata.GenreLanguages
+	}
+}
+
+// MetadataPrefs returns the per-field language and region order of the
+// localized metadata
+func (cfg *Config) MetadataPrefs() scraper.MetadataPrefs {
+	regions := func(field []string) []string {
+		if len(field) == 0 {
+			field = cfg.Regions
+		}
+		return normalizeCodes(field)
+	}
+	return scraper.MetadataPrefs{
+		TitleRegions:       regions(cfg.Metadata.TitleRegions),
+		SynopsisLanguages:  normalizeCodes(cfg.Metadata.SynopsisLanguages),
+		GenreLanguages:     normalizeCodes(cfg.Metadata.GenreLanguages),
+		ReleaseDateRegions: regions(cfg.Metadata.ReleaseDateRegions),
+	}
+}
+
+// RegionResolver returns the resolver ordering regions for media and
+// titles
+func (cfg *Config) RegionResolver() *scraper.RegionResolver {
+	fallbacks := make(map[string][]string, len(cfg.RegionFallbacks))
+	for code, next := range cfg.RegionFallbacks {
+		fallbacks[scraper.NormalizeCode(code)] = normalizeCodes(next)
+	}
+	return &scraper.RegionResolver{
+		Order:     normalizeCodes(cfg.Regions),
+		Fallbacks: fallbacks,
+	}
+}
+
+func normalizeCodes(codes []string) []string {
+	normalized := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if code = scraper.NormalizeCode(code); code != "" {
+			normalized = append(normalized, code)
+		}
+	}
+	return normalized
+}
+
+// Valid reports whether the policy is one of the known values
+func (p OverwritePolicy) Valid() bool {
+	for _, policy := range OverwritePolicies {
+		if p == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// CachePath returns a path inside the cache directory
+func (cfg *Config) CachePath(name string) string {
+	return filepath.Join(cfg.CacheDir, name)
+}
+
+// Save writes the configuration atomically: the data is written and synced
+// to a temporary file in the same directory, renamed over the old file and
+// the directory is synced, so a power loss leaves either the old or the new
+// file but never a truncated one. The file holds provider passwords, so only
+// the owner can read it.
+func Save(path string, cfg *Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := fsutil.WriteFileAtomic(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	return nil
+}
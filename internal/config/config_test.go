@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMovesBrokenFileAside(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	broken := []byte(`{"roms_root": "/mnt/SDCARD/Roms",`)
+	if err := os.WriteFile(path, broken, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(path)
+	if err == nil {
+		t.Fatal("Load accepted a broken file")
+	}
+	if cfg == nil {
+		t.Fatal("Load returned no defaults")
+	}
+
+	// Saving the defaults must not destroy the user's file
+	if err := Save(path, cfg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(brokenPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(broken) {
+		t.Errorf("backup = %q, want the broken file", data)
+	}
+
+	if _, err := Load(path); err != nil {
+		t.Errorf("Load of the saved defaults: %v", err)
+	}
+}
+
+func TestLoadMissingFileUsesDefaults(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Workers != Default().Workers {
+		t.Errorf("Workers = %d, want the default", cfg.Workers)
+	}
+}
@@ -1,7 +1,24 @@
 package core
 
 const (
-	WINDOW_WIDTH  = 1280
-	WINDOW_HEIGHT = 720
-	FPS           = 60
+	FPS = 60
+
+	// Default window size, used when the config does not set one
+	DEFAULT_WINDOW_WIDTH  = 1280
+	DEFAULT_WINDOW_HEIGHT = 720
+)
+
+// WINDOW_WIDTH and WINDOW_HEIGHT hold the window size. SetWindowSize updates
+// them from the config before the window and the layout are created.
+var (
+	WINDOW_WIDTH  int32 = DEFAULT_WINDOW_WIDTH
+	WINDOW_HEIGHT int32 = DEFAULT_WINDOW_HEIGHT
 )
+
+// SetWindowSize sets the window size; non-positive values keep the default
+func SetWindowSize(width, height int) {
+	if width > 0 && height > 0 {
+		WINDOW_WIDTH = int32(width)
+		WINDOW_HEIGHT = int32(height)
+	}
+}
@@ -0,0 +1,24 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// ExecutableDir returns the directory containing the running binary, falling
+// back to the working directory when it cannot be resolved
+func ExecutableDir() string {
+	exe, err := os.Executable()
+	if err != nil {
+		return "."
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	return filepath.Dir(exe)
+}
+
+// DataPath returns a path relative to the directory of the binary
+func DataPath(elem ...string) string {
+	return filepath.Join(append([]string{ExecutableDir()}, elem...)...)
+}
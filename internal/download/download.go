@@ -0,0 +1,404 @@
+// Package download fetches media files resumably. Bodies are streamed to a
+// ".part" file in a staging directory; an interrupted transfer continues
+// from where it stopped with an HTTP Range request, and the file is renamed
+// to its final name only once its length and checksums were verified.
+package download
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"retroart-sdl2/internal/fsutil"
+	"retroart-sdl2/internal/scraper"
+)
+
+var (
+	// ErrLength means the downloaded file does not have the announced size
+	ErrLength = errors.New("downloaded file has the wrong length")
+	// ErrChecksum means the downloaded file does not match the provider checksum
+	ErrChecksum = errors.New("downloaded file checksum mismatch")
+	// ErrNotFound means the server has no file at the URL
+	ErrNotFound = errors.New("file not found")
+)
+
+// maxResumes is how many times a broken transfer is resumed before giving up
+const maxResumes = 3
+
+// Request describes a file to download. Size and checksums are optional.
+type Request struct {
+	URL  string
+	Size int64
+	MD5  string
+	SHA1 string
+}
+
+// File is a verified download. Remove must be called once the file was
+// consumed; files outside the staging directory are left alone.
+type File struct {
+	Path         string
+	Size         int64
+	ETag         string
+	LastModified string
+	staged       bool
+}
+
+// Remove deletes a staged file
+func (f *File) Remove() {
+	if f.staged {
+		os.Remove(f.Path)
+	}
+}
+
+// Downloader downloads files into a staging directory. It is safe for
+// concurrent use; concurrent downloads of the same URL are serialised.
+type Downloader struct {
+	client *http.Client
+	dir    string
+
+	mu     sync.Mutex
+	active map[string]*sync.Mutex
+}
+
+// New creates a downloader staging files in dir
+func New(client *http.Client, dir string) *Downloader {
+	return &Downloader{client: client, dir: dir, active: make(map[string]*sync.Mutex)}
+}
+
+// lock serialises the downloads sharing a staging file
+func (d *Downloader) lock(base string) func() {
+	d.mu.Lock()
+	m, ok := d.active[base]
+	if !ok {
+		m = &sync.Mutex{}
+		d.active[base] = m
+	}
+	d.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
+
+// Prune removes staged files older than maxAge, left behind by downloads
+// that never completed
+func (d *Downloader) Prune(maxAge time.Duration) (int, error) {
+	entries, err := os.ReadDir(d.dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	removed := 0
+	cutoff := time.Now().Add(-maxAge)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || entry.IsDir() || !info.ModTime().Before(cutoff) {
+			continue
+		}
+		if os.Remove(filepath.Join(d.dir, entry.Name())) == nil {
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// partMeta is stored next to a .part file, so a resumed transfer only
+// continues the same version of the file
+type partMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Total        int64  `json:"total"` // -1 when unknown
+}
+
+// validator returns the value sent in If-Range, empty when the server gave
+// no strong validator
+func (m partMeta) validator() string {
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		return m.ETag
+	}
+	return m.LastModified
+}
+
+// Fetch downloads a file, resuming a previous partial transfer of the same
+// URL. file:// URLs are verified in place.
+func (d *Downloader) Fetch(ctx context.Context, req Request) (*File, error) {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", scraper.RedactError(err))
+	}
+	if u.Scheme == "file" {
+		return fetchLocal(filepath.FromSlash(u.Path), req)
+	}
+
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create download dir: %w", err)
+	}
+
+	sum := sha256.Sum256([]byte(req.URL))
+	base := filepath.Join(d.dir, hex.EncodeToString(sum[:16]))
+	part := base + ".part"
+	defer d.lock(base)()
+
+	var meta partMeta
+	for attempt := 0; ; attempt++ {
+		meta, err = d.transfer(ctx, req, part)
+		if err == nil {
+			break
+		}
+		// Keep the partial file when the transfer broke, so the next
+		// attempt or the next run continues it
+		if ctx.Err() != nil || attempt >= maxResumes || !resumable(err) {
+			return nil, err
+		}
+		log.Printf("Download: resuming %s after: %v", scraper.RedactURL(req.URL), err)
+	}
+
+	size, err := verify(part, meta.Total, req)
+	if err != nil {
+		removePart(part)
+		return nil, err
+	}
+
+	// Unique, so a second download of the URL never replaces a file that
+	// is still being consumed
+	final := fmt.Sprintf("%s.%d.done", base, time.Now().UnixNano())
+	if err := os.Rename(part, final); err != nil {
+		return nil, fmt.Errorf("failed to finish download: %w", err)
+	}
+	os.Remove(part + ".json")
+
+	return &File{
+		Path:         final,
+		Size:         size,
+		ETag:         meta.ETag,
+		LastModified: meta.LastModified,
+		staged:       true,
+	}, nil
+}
+
+// transfer appends the missing bytes of the file to part
+func (d *Downloader) transfer(ctx context.Context, req Request, part string) (partMeta, error) {
+	meta, offset := loadPart(part, req.URL)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, req.URL, nil)
+	if err != nil {
+		return meta, fmt.Errorf("failed to create request: %w", scraper.RedactError(err))
+	}
+	if offset > 0 {
+		httpReq.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+		httpReq.Header.Set("If-Range", meta.validator())
+	}
+
+	resp, err := d.client.Do(httpReq)
+	if err != nil {
+		return meta, &transferError{scraper.RedactError(err)}
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_WRONLY | os.O_CREATE
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		start, total, ok := parseContentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			removePart(part)
+			return meta, &transferError{fmt.Errorf("unexpected content range %q", resp.Header.Get("Content-Range"))}
+		}
+		meta.Total = total
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// Full body: the server ignored the range or the file changed
+		meta = partMeta{
+			URL:          scraper.RedactURL(req.URL),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+			Total:        resp.ContentLength,
+		}
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The previous run stopped right after the last byte
+		if meta.Total >= 0 && offset == meta.Total {
+			return meta, nil
+		}
+		removePart(part)
+		return meta, &transferError{errors.New("partial download no longer matches the server")}
+	case http.StatusNotFound:
+		removePart(part)
+		return meta, ErrNotFound
+	default:
+		return meta, fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	if err := writeMeta(part, meta); err != nil {
+		return meta, err
+	}
+
+	file, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return meta, fmt.Errorf("failed to open partial download: %w", err)
+	}
+	_, copyErr := io.Copy(file, resp.Body)
+	syncErr := file.Sync()
+	closeErr := file.Close()
+
+	switch {
+	case copyErr != nil:
+		return meta, &transferError{copyErr}
+	case syncErr != nil:
+		return meta, fmt.Errorf("failed to sync partial download: %w", syncErr)
+	case closeErr != nil:
+		return meta, fmt.Errorf("failed to close partial download: %w", closeErr)
+	}
+	return meta, nil
+}
+
+// transferError is a network failure worth resuming
+type transferError struct {
+	err error
+}
+
+func (e *transferError) Error() string { return "transfer interrupted: " + e.err.Error() }
+func (e *transferError) Unwrap() error { return e.err }
+
+func resumable(err error) bool {
+	var transfer *transferError
+	return errors.As(err, &transfer)
+}
+
+// loadPart returns the metadata and size of an existing partial download of
+// the URL, or a fresh state
+func loadPart(part, rawURL string) (partMeta, int64) {
+	rawURL = scraper.RedactURL(rawURL)
+	fresh := partMeta{URL: rawURL, Total: -1}
+
+	data, err := os.ReadFile(part + ".json")
+	if err != nil {
+		return fresh, 0
+	}
+	var meta partMeta
+	if json.Unmarshal(data, &meta) != nil || meta.URL != rawURL || meta.validator() == "" {
+		// Without a validator a resumed file could mix two versions
+		return fresh, 0
+	}
+
+	info, err := os.Stat(part)
+	if err != nil {
+		return fresh, 0
+	}
+	return meta, info.Size()
+}
+
+func writeMeta(part string, meta partMeta) error {
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+	if err := fsutil.WriteFileAtomic(part+".json", data, 0o644); err != nil {
+		return fmt.Errorf("failed to save download state: %w", err)
+	}
+	return nil
+}
+
+func removePart(part string) {
+	os.Remove(part)
+	os.Remove(part + ".json")
+}
+
+// parseContentRange reads "bytes start-end/total"; total is -1 for "*"
+func parseContentRange(value string) (start, total int64, ok bool) {
+	value, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	span, size, found := strings.Cut(value, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(span, "-")
+	if !found {
+		return 0, 0, false
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	total = -1
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, total, true
+}
+
+// verify checks the length announced by the server, or by the provider when
+// the server did not send one, and the provider checksums
+func verify(path string, total int64, req Request) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open download: %w", err)
+	}
+	defer file.Close()
+
+	hashes := map[string]hash.Hash{}
+	if req.MD5 != "" {
+		hashes[req.MD5] = md5.New()
+	}
+	if req.SHA1 != "" {
+		hashes[req.SHA1] = sha1.New()
+	}
+	writers := make([]io.Writer, 0, len(hashes))
+	for _, h := range hashes {
+		writers = append(writers, h)
+	}
+
+	size, err := io.Copy(io.MultiWriter(writers...), file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read download: %w", err)
+	}
+
+	// Both the length announced by the server and the one reported by the
+	// provider must hold
+	for _, want := range []int64{total, req.Size} {
+		if want > 0 && size != want {
+			return 0, fmt.Errorf("%w: got %d bytes, want %d", ErrLength, size, want)
+		}
+	}
+	for expected, h := range hashes {
+		if got := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(got, expected) {
+			return 0, fmt.Errorf("%w: got %s, want %s", ErrChecksum, got, strings.ToLower(expected))
+		}
+	}
+	return size, nil
+}
+
+// fetchLocal verifies a file of a local mirror without copying it
+func fetchLocal(path string, req Request) (*File, error) {
+	size, err := verify(path, -1, req)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &File{Path: path, Size: size}, nil
+}
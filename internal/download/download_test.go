@@ -0,0 +1,167 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+var content = bytes.Repeat([]byte("0123456789abcdef"), 4096)
+
+// flakyServer serves content with range support. The first transfers are
+// cut, like a dropped Wi-Fi connection: a full one after half the body, a
+// resumed one before any byte.
+type flakyServer struct {
+	mu     sync.Mutex
+	breaks int
+	ranges []string
+}
+
+func (s *flakyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	cut := s.breaks > 0
+	if cut {
+		s.breaks--
+	}
+	if value := r.Header.Get("Range"); value != "" {
+		s.ranges = append(s.ranges, value)
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", `"v1"`)
+	if cut {
+		if r.Header.Get("Range") != "" {
+			panic(http.ErrAbortHandler)
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+		w.WriteHeader(http.StatusOK)
+		w.Write(content[:len(content)/2])
+		w.(http.Flusher).Flush()
+		panic(http.ErrAbortHandler)
+	}
+	http.ServeContent(w, r, "media.png", time.Time{}, bytes.NewReader(content))
+}
+
+func sums() (string, string) {
+	md5Sum := md5.Sum(content)
+	sha1Sum := sha1.Sum(content)
+	return hex.EncodeToString(md5Sum[:]), hex.EncodeToString(sha1Sum[:])
+}
+
+func TestFetchResumesBrokenTransfer(t *testing.T) {
+	handler := &flakyServer{breaks: 1}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	md5Sum, sha1Sum := sums()
+	downloader := New(server.Client(), t.TempDir())
+	file, err := downloader.Fetch(context.Background(), Request{
+		URL:  server.URL + "/media.png",
+		Size: int64(len(content)),
+		MD5:  md5Sum,
+		SHA1: sha1Sum,
+	})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	defer file.Remove()
+
+	want := "bytes=" + strconv.Itoa(len(content)/2) + "-"
+	if len(handler.ranges) != 1 || handler.ranges[0] != want {
+		t.Errorf("range requests = %v, want [%s]", handler.ranges, want)
+	}
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("resumed file differs from the original (%d bytes, want %d)", len(data), len(content))
+	}
+	if file.ETag != `"v1"` {
+		t.Errorf("ETag = %q", file.ETag)
+	}
+}
+
+func TestFetchVerifiesDownload(t *testing.T) {
+	server := httptest.NewServer(&flakyServer{})
+	defer server.Close()
+
+	md5Sum, sha1Sum := sums()
+	tests := []struct {
+		name string
+		req  Request
+		want error
+	}{
+		{"valid", Request{Size: int64(len(content)), MD5: md5Sum, SHA1: sha1Sum}, nil},
+		{"upper case checksum", Request{MD5: strings.ToUpper(md5Sum)}, nil},
+		{"wrong size", Request{Size: int64(len(content)) + 1}, ErrLength},
+		{"wrong md5", Request{MD5: strings.Repeat("0", 32)}, ErrChecksum},
+		{"wrong sha1", Request{SHA1: strings.Repeat("0", 40)}, ErrChecksum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tt.req.URL = server.URL + "/media.png"
+			file, err := New(server.Client(), dir).Fetch(context.Background(), tt.req)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Fetch error = %v, want %v", err, tt.want)
+			}
+			if file != nil {
+				file.Remove()
+			}
+
+			// Nothing is left behind: a bad file is never resumed
+			entries, _ := os.ReadDir(dir)
+			if len(entries) != 0 {
+				t.Errorf("staging dir not empty: %v", entries)
+			}
+		})
+	}
+}
+
+func TestFetchKeepsNoCredentials(t *testing.T) {
+	// Every transfer breaks, so the partial state stays on disk
+	server := httptest.NewServer(&flakyServer{breaks: maxResumes + 1})
+	defer server.Close()
+
+	dir := t.TempDir()
+	rawURL := server.URL + "/media.png?devid=dev&devpassword=devsecret&ssid=user&sspassword=usersecret&media=box-2D"
+	if _, err := New(server.Client(), dir).Fetch(context.Background(), Request{URL: rawURL}); err == nil {
+		t.Fatal("Fetch succeeded, want a transfer error")
+	}
+
+	metas, _ := filepath.Glob(filepath.Join(dir, "*.part.json"))
+	if len(metas) != 1 {
+		t.Fatalf("download state files = %v, want one", metas)
+	}
+	data, err := os.ReadFile(metas[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"devsecret", "usersecret", "ssid", "devid"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("download state contains %q: %s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), "media=box-2D") {
+		t.Errorf("download state lost the media parameters: %s", data)
+	}
+
+	// The same URL still resumes from the stripped state
+	if meta, offset := loadPart(strings.TrimSuffix(metas[0], ".json"), rawURL); offset == 0 || meta.Total != int64(len(content)) {
+		t.Errorf("loadPart = %+v, offset %d, want a resumable state", meta, offset)
+	}
+}
@@ -0,0 +1,107 @@
+// Package fsutil holds file helpers shared by the packages that write to the
+// SD card, where power can be cut at any time.
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is a temporary file that replaces its target on Commit. Until
+// then the target is left untouched.
+type AtomicFile struct {
+	*os.File
+	path string
+	perm os.FileMode
+	done bool
+}
+
+// CreateAtomic creates a temporary file next to path, creating the parent
+// directories as needed
+func CreateAtomic(path string, perm os.FileMode) (*AtomicFile, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	return &AtomicFile{File: tmp, path: path, perm: perm}, nil
+}
+
+// Path returns the final path of the file
+func (f *AtomicFile) Path() string {
+	return f.path
+}
+
+// Commit syncs the temporary file and renames it over the target. The
+// rename is durable once Commit returns.
+func (f *AtomicFile) Commit() error {
+	if f.done {
+		return os.ErrClosed
+	}
+	f.done = true
+
+	tmpPath := f.Name()
+	if err := f.Sync(); err != nil {
+		f.File.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := f.File.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, f.perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := os.Rename(tmpPath, f.path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+
+	return syncDir(filepath.Dir(f.path))
+}
+
+// Abort discards the temporary file. It is a no-op after Commit, so it can
+// be deferred right after CreateAtomic.
+func (f *AtomicFile) Abort() {
+	if f.done {
+		return
+	}
+	f.done = true
+	f.File.Close()
+	os.Remove(f.Name())
+}
+
+// WriteFileAtomic replaces path with data so readers never observe a partial
+// file, even if power is lost mid-write
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	file, err := CreateAtomic(path, perm)
+	if err != nil {
+		return err
+	}
+	defer file.Abort()
+
+	if _, err := file.Write(data); err != nil {
+		return err
+	}
+	return file.Commit()
+}
+
+// syncDir flushes directory entries so the rename itself is durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	// Some filesystems (e.g. FAT on SD cards) do not support syncing
+	// directories; the rename is still atomic there.
+	_ = d.Sync()
+	return nil
+}
@@ -0,0 +1,173 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"retroart-sdl2/internal/fsutil"
+)
+
+// maxCacheBody bounds the responses kept in the cache
+const maxCacheBody = 1 << 20
+
+// cacheEntry is the header line of a cache file; the body follows it
+type cacheEntry struct {
+	Status int         `json:"status"`
+	Header http.Header `json:"header"`
+	Stored time.Time   `json:"stored"`
+}
+
+// cache stores responses on disk, one file per method and URL. File names
+// are hashes, so credentials in query strings never appear in the tree.
+type cache struct {
+	dir string
+	ttl time.Duration
+}
+
+func (c *cache) path(req *http.Request) string {
+	sum := sha256.Sum256([]byte(req.Method + " " + req.URL.String()))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(c.dir, name[:2], name)
+}
+
+// get returns the cached entry and body of a request
+func (c *cache) get(req *http.Request) (*cacheEntry, []byte, bool) {
+	data, err := os.ReadFile(c.path(req))
+	if err != nil {
+		return nil, nil, false
+	}
+
+	meta, body, ok := bytes.Cut(data, []byte{'\n'})
+	if !ok {
+		return nil, nil, false
+	}
+	var entry cacheEntry
+	if err := json.Unmarshal(meta, &entry); err != nil {
+		return nil, nil, false
+	}
+	return &entry, body, true
+}
+
+// fresh reports whether an entry can be served without asking the server
+func (c *cache) fresh(entry *cacheEntry, now time.Time) bool {
+	return now.Sub(entry.Stored) < c.ttl
+}
+
+// put stores a response with its body
+func (c *cache) put(req *http.Request, entry *cacheEntry, body []byte) error {
+	meta, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, 0, len(meta)+1+len(body))
+	data = append(append(append(data, meta...), '\n'), body...)
+	return fsutil.WriteFileAtomic(c.path(req), data, 0o644)
+}
+
+// prune removes entries older than maxAge
+func (c *cache) prune(maxAge time.Duration) (int, error) {
+	removed := 0
+	cutoff := time.Now().Add(-maxAge)
+	err := filepath.WalkDir(c.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err == nil && info.ModTime().Before(cutoff) {
+			if os.Remove(path) == nil {
+				removed++
+			}
+		}
+		return nil
+	})
+	return removed, err
+}
+
+// response rebuilds an http.Response from a cache entry. Answers served
+// from disk carry an "X-Cache: HIT" header, as their body may be old.
+func (entry *cacheEntry) response(req *http.Request, body []byte, hit bool) *http.Response {
+	header := entry.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	if hit {
+		header.Set("X-Cache", "HIT")
+	}
+
+	resp := &http.Response{
+		Status:        strconv.Itoa(entry.Status) + " " + http.StatusText(entry.Status),
+		StatusCode:    entry.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		ContentLength: int64(len(body)),
+		Request:       req,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+	}
+	if req.Method == http.MethodHead {
+		resp.Body = http.NoBody
+	}
+	return resp
+}
+
+// cacheable reports whether a request may be answered from the cache
+func cacheable(req *http.Request) bool {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		return false
+	}
+	// Conditional and partial requests are handled by the caller
+	for _, name := range []string{"Range", "If-None-Match", "If-Modified-Since"} {
+		if req.Header.Get(name) != "" {
+			return false
+		}
+	}
+	return req.Header.Get("Cache-Control") != "no-cache"
+}
+
+// storable reports whether a response may be written to the cache. Not
+// found answers are kept too: they are what makes reruns cheap. Images and
+// videos are left out, the scrape database already tracks written media,
+// but HEAD answers about them are kept.
+func storable(req *http.Request, resp *http.Response) bool {
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return false
+	}
+	if resp.Header.Get("Cache-Control") == "no-store" {
+		return false
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if req.Method == http.MethodGet && (strings.HasPrefix(contentType, "image/") || strings.HasPrefix(contentType, "video/")) {
+		return false
+	}
+	return resp.ContentLength <= maxCacheBody
+}
+
+// readBody reads a response body for the cache. When it exceeds the cache
+// limit the response is returned with its body intact and ok false.
+func readBody(resp *http.Response) ([]byte, bool, error) {
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxCacheBody+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, false, err
+	}
+	if len(body) > maxCacheBody {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return nil, false, nil
+	}
+	resp.Body.Close()
+	return body, true, nil
+}
@@ -0,0 +1,319 @@
+// Package httpclient is the HTTP layer shared by every scraper provider. It
+// rate limits requests per host with token buckets, retries failures with
+// exponential backoff honouring Retry-After, answers reruns from an on-disk
+// response cache revalidated with ETag/Last-Modified, and tracks the quotas
+// reported by servers so the UI can show them.
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Defaults used for zero Options fields
+const (
+	DefaultRate       = 2.0
+	DefaultMaxRetries = 4
+	DefaultBaseDelay  = 500 * time.Millisecond
+	DefaultMaxDelay   = 30 * time.Second
+	DefaultCacheTTL   = 7 * 24 * time.Hour
+)
+
+// maxRetryAfter is the longest Retry-After honoured; longer waits are left
+// to the caller, which usually means the quota is exhausted for the day
+const maxRetryAfter = 5 * time.Minute
+
+// Options configures a Client
+type Options struct {
+	UserAgent  string
+	Limit      Limit            // Per host, defaults to DefaultRate with a burst of 2
+	HostLimits map[string]Limit // Overrides Limit for specific hosts
+	MaxRetries int              // Retries after the first attempt, negative disables
+	BaseDelay  time.Duration    // First backoff delay, doubled on every retry
+	MaxDelay   time.Duration    // Upper bound of a backoff delay
+	CacheDir   string           // Response cache directory, empty disables the cache
+	CacheTTL   time.Duration    // Age below which cached responses are used as is
+	Transport  http.RoundTripper
+}
+
+// Client is an http.RoundTripper adding rate limits, retries, caching and
+// quota tracking to a base transport. It is safe for concurrent use.
+type Client struct {
+	options Options
+	base    http.RoundTripper
+	cache   *cache
+	quotas  *Quotas
+	http    *http.Client
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+// New creates a client
+func New(options Options) *Client {
+	if options.Limit.Rate <= 0 {
+		options.Limit = Limit{Rate: DefaultRate, Burst: 2}
+	}
+	if options.MaxRetries == 0 {
+		options.MaxRetries = DefaultMaxRetries
+	}
+	if options.BaseDelay <= 0 {
+		options.BaseDelay = DefaultBaseDelay
+	}
+	if options.MaxDelay <= 0 {
+		options.MaxDelay = DefaultMaxDelay
+	}
+	if options.CacheTTL <= 0 {
+		options.CacheTTL = DefaultCacheTTL
+	}
+
+	base := options.Transport
+	if base == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		// The whole request is not bounded, so slow media downloads over
+		// weak Wi-Fi still finish; stalled servers are caught here
+		transport.ResponseHeaderTimeout = 30 * time.Second
+		base = transport
+	}
+
+	c := &Client{
+		options: options,
+		base:    base,
+		quotas:  NewQuotas(),
+		buckets: make(map[string]*bucket),
+	}
+	if options.CacheDir != "" {
+		c.cache = &cache{dir: options.CacheDir, ttl: options.CacheTTL}
+	}
+	c.http = &http.Client{Transport: c}
+	return c
+}
+
+// HTTPClient returns an http.Client using this client as transport
+func (c *Client) HTTPClient() *http.Client {
+	return c.http
+}
+
+// Quotas returns the quota tracker, also used by providers to report their
+// own quota fields
+func (c *Client) Quotas() *Quotas {
+	return c.quotas
+}
+
+// SetLimit changes the rate limit of a host, e.g. from the per-minute limit
+// a provider reports for the account
+func (c *Client) SetLimit(host string, limit Limit) {
+	c.bucket(host).setLimit(limit)
+}
+
+// ConfiguredLimit returns the limit of a host from the Options, which
+// limits reported by providers must not exceed
+func (c *Client) ConfiguredLimit(host string) Limit {
+	if limit, ok := c.options.HostLimits[host]; ok {
+		return limit
+	}
+	return c.options.Limit
+}
+
+// PruneCache removes cached responses older than maxAge
+func (c *Client) PruneCache(maxAge time.Duration) (int, error) {
+	if c.cache == nil {
+		return 0, nil
+	}
+	return c.cache.prune(maxAge)
+}
+
+// RoundTrip implements http.RoundTripper
+func (c *Client) RoundTrip(req *http.Request) (*http.Response, error) {
+	if c.cache == nil || !cacheable(req) {
+		return c.send(req)
+	}
+
+	entry, body, cached := c.cache.get(req)
+	if cached && c.cache.fresh(entry, time.Now()) {
+		return entry.response(req, body, true), nil
+	}
+
+	out := req
+	if cached {
+		out = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			out.Header.Set("If-None-Match", etag)
+		}
+		if modified := entry.Header.Get("Last-Modified"); modified != "" {
+			out.Header.Set("If-Modified-Since", modified)
+		}
+	}
+
+	resp, err := c.send(out)
+	if err != nil {
+		if cached && req.Context().Err() == nil {
+			// Flaky Wi-Fi: a stale answer beats no answer
+			log.Printf("HTTP: serving stale %s after error: %v", req.URL.Host, err)
+			return entry.response(req, body, true), nil
+		}
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && cached {
+		resp.Body.Close()
+		entry.Stored = time.Now()
+		if err := c.cache.put(req, entry, body); err != nil {
+			log.Printf("HTTP: failed to refresh cache entry: %v", err)
+		}
+		return entry.response(req, body, true), nil
+	}
+
+	if !storable(req, resp) {
+		return resp, nil
+	}
+
+	data, ok, err := readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return resp, nil
+	}
+	stored := &cacheEntry{Status: resp.StatusCode, Header: resp.Header, Stored: time.Now()}
+	if err := c.cache.put(req, stored, data); err != nil {
+		log.Printf("HTTP: failed to cache response: %v", err)
+	}
+	return stored.response(req, data, false), nil
+}
+
+// send performs a request through the rate limiter, retrying transient
+// failures. Only requests that can be replayed are retried.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Hostname()
+	replayable := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	if c.options.UserAgent != "" && req.Header.Get("User-Agent") == "" {
+		req = req.Clone(ctx)
+		req.Header.Set("User-Agent", c.options.UserAgent)
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := c.bucket(host).wait(ctx); err != nil {
+			return nil, err
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(ctx)
+			req.Body = body
+		}
+
+		resp, err := c.base.RoundTrip(req)
+		if err == nil {
+			if quota, ok := parseQuotaHeaders(host, resp.Header, time.Now()); ok {
+				c.quotas.Set(quota)
+			}
+		}
+
+		retry, delay := c.shouldRetry(resp, err, attempt)
+		if !retry || !replayable || attempt >= c.options.MaxRetries {
+			return resp, err
+		}
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				delay = max(delay, retryAfter)
+				c.bucket(host).pause(time.Now().Add(retryAfter))
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+
+		log.Printf("HTTP: retrying %s in %s (attempt %d): %s", host, delay.Round(time.Millisecond), attempt+1, retryReason(resp, err))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// shouldRetry decides whether a failed attempt is transient and returns the
+// backoff delay before the next one
+func (c *Client) shouldRetry(resp *http.Response, err error, attempt int) (bool, time.Duration) {
+	if err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return false, 0
+		}
+		var netErr net.Error
+		if !errors.As(err, &netErr) && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+			return false, 0
+		}
+		return true, c.backoff(attempt)
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok && retryAfter > maxRetryAfter {
+			return false, 0
+		}
+		return true, c.backoff(attempt)
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+		return true, c.backoff(attempt)
+	default:
+		return false, 0
+	}
+}
+
+// backoff returns BaseDelay * 2^attempt with up to 25% jitter, capped at
+// MaxDelay
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.options.BaseDelay << min(attempt, 16)
+	if delay <= 0 || delay > c.options.MaxDelay {
+		delay = c.options.MaxDelay
+	}
+	jitter := time.Duration(rand.Int64N(int64(delay)/4 + 1))
+	return delay + jitter
+}
+
+func (c *Client) bucket(host string) *bucket {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	b, ok := c.buckets[host]
+	if !ok {
+		b = newBucket(c.ConfiguredLimit(host))
+		c.buckets[host] = b
+	}
+	return b
+}
+
+// parseRetryAfter reads a Retry-After value in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+func retryReason(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
@@ -0,0 +1,219 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testClient returns a client with short backoff delays and no rate limit
+// worth waiting for
+func testClient(options Options) *Client {
+	if options.Limit.Rate == 0 {
+		options.Limit = Limit{Rate: 1000, Burst: 100}
+	}
+	if options.BaseDelay == 0 {
+		options.BaseDelay = time.Millisecond
+	}
+	return New(options)
+}
+
+func get(t *testing.T, client *Client, url string) (*http.Response, string) {
+	t.Helper()
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.HTTPClient().Do(req)
+	if err != nil {
+		t.Fatalf("GET %s: %v", url, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestRetriesTransientFailures(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	resp, body := get(t, testClient(Options{MaxRetries: 3}), server.URL)
+	if resp.StatusCode != http.StatusOK || body != "ok" {
+		t.Errorf("got %d %q, want 200 ok", resp.StatusCode, body)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("server hit %d times, want 3", hits.Load())
+	}
+}
+
+func TestRetriesGiveUp(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, _ := get(t, testClient(Options{MaxRetries: 2}), server.URL)
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want the last failure", resp.StatusCode)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("server hit %d times, want 1 attempt and 2 retries", hits.Load())
+	}
+}
+
+func TestNoRetryOnClientErrors(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	get(t, testClient(Options{MaxRetries: 3}), server.URL)
+	if hits.Load() != 1 {
+		t.Errorf("server hit %d times, want 1", hits.Load())
+	}
+}
+
+func TestRetryAfterIsHonoured(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	resp, _ := get(t, testClient(Options{MaxRetries: 1}), server.URL)
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want 200", resp.StatusCode)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("retried after %s, want at least the Retry-After second", elapsed)
+	}
+}
+
+func TestLongRetryAfterIsLeftToCaller(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Retry-After", "86400")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	resp, _ := get(t, testClient(Options{MaxRetries: 3}), server.URL)
+	if resp.StatusCode != http.StatusTooManyRequests || hits.Load() != 1 {
+		t.Errorf("got %d after %d hits, want an immediate 429", resp.StatusCode, hits.Load())
+	}
+}
+
+func TestCacheHit(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"game":1}`)
+	}))
+	defer server.Close()
+
+	client := testClient(Options{CacheDir: t.TempDir(), CacheTTL: time.Hour})
+	get(t, client, server.URL+"/game?id=1")
+	resp, body := get(t, client, server.URL+"/game?id=1")
+
+	if hits.Load() != 1 {
+		t.Errorf("server hit %d times, want the second answer from cache", hits.Load())
+	}
+	if resp.Header.Get("X-Cache") != "HIT" || body != `{"game":1}` {
+		t.Errorf("got %q with X-Cache %q, want the cached body", body, resp.Header.Get("X-Cache"))
+	}
+
+	// Another URL is not served from the same entry
+	get(t, client, server.URL+"/game?id=2")
+	if hits.Load() != 2 {
+		t.Errorf("server hit %d times, want 2", hits.Load())
+	}
+}
+
+func TestCacheRevalidatesWithETag(t *testing.T) {
+	var hits, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, "body")
+	}))
+	defer server.Close()
+
+	client := testClient(Options{CacheDir: t.TempDir(), CacheTTL: time.Nanosecond})
+	get(t, client, server.URL)
+	time.Sleep(time.Millisecond)
+	resp, body := get(t, client, server.URL)
+
+	if hits.Load() != 2 || notModified.Load() != 1 {
+		t.Errorf("hits = %d, not modified = %d, want a conditional second request", hits.Load(), notModified.Load())
+	}
+	if resp.StatusCode != http.StatusOK || body != "body" {
+		t.Errorf("got %d %q, want the cached body", resp.StatusCode, body)
+	}
+}
+
+func TestCacheServesStaleOnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "cached")
+	}))
+
+	client := testClient(Options{CacheDir: t.TempDir(), CacheTTL: time.Nanosecond, MaxRetries: -1})
+	get(t, client, server.URL)
+	server.Close()
+	time.Sleep(time.Millisecond)
+
+	resp, body := get(t, client, server.URL)
+	if body != "cached" || resp.Header.Get("X-Cache") != "HIT" {
+		t.Errorf("got %q, want the stale cached body", body)
+	}
+}
+
+func TestQuotaHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Limit", "100")
+		w.Header().Set("X-RateLimit-Remaining", "42")
+		w.Header().Set("X-RateLimit-Reset", "60")
+	}))
+	defer server.Close()
+
+	client := testClient(Options{})
+	get(t, client, server.URL)
+
+	quota, ok := client.Quotas().Get("127.0.0.1")
+	if !ok {
+		t.Fatal("quota of the server not recorded")
+	}
+	if quota.Limit != 100 || quota.Remaining != 42 || quota.Reset.IsZero() {
+		t.Errorf("quota = %+v, want 42 of 100 with a reset time", quota)
+	}
+}
@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Quota is the request allowance reported by a host or provider
+type Quota struct {
+	Name      string    // Host name, or provider name for provider specific quotas
+	Limit     int       // Requests allowed in the current window, zero when unknown
+	Remaining int       // Requests left in the current window
+	Reset     time.Time // When the window resets, zero when unknown
+	Updated   time.Time
+
+	// Exceeded is set when the provider refused a request for lack of
+	// quota, until it reports a quota again
+	Exceeded bool
+}
+
+// Fraction returns the remaining share of the quota between 0 and 1, or -1
+// when the limit is unknown
+func (q Quota) Fraction() float64 {
+	if q.Limit <= 0 {
+		return -1
+	}
+	return min(max(float64(q.Remaining)/float64(q.Limit), 0), 1)
+}
+
+// Quotas collects the latest quota of every host and provider. It is safe
+// for concurrent use.
+type Quotas struct {
+	mu     sync.Mutex
+	quotas map[string]Quota
+}
+
+// NewQuotas creates an empty quota tracker
+func NewQuotas() *Quotas {
+	return &Quotas{quotas: make(map[string]Quota)}
+}
+
+// Set records a quota, replacing the previous one of the same name
+func (q *Quotas) Set(quota Quota) {
+	if quota.Updated.IsZero() {
+		quota.Updated = time.Now()
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.quotas[quota.Name] = quota
+}
+
+// Get returns the quota of a host or provider
+func (q *Quotas) Get(name string) (Quota, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	quota, ok := q.quotas[name]
+	return quota, ok
+}
+
+// All returns every known quota sorted by name
+func (q *Quotas) All() []Quota {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	all := make([]Quota, 0, len(q.quotas))
+	for _, quota := range q.quotas {
+		all = append(all, quota)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+	return all
+}
+
+// parseQuotaHeaders reads the de facto X-RateLimit-* headers and the IETF
+// RateLimit-* ones. ok is false when the response has neither.
+func parseQuotaHeaders(host string, header http.Header, now time.Time) (Quota, bool) {
+	for _, prefix := range []string{"X-RateLimit-", "RateLimit-"} {
+		remaining, err := strconv.Atoi(header.Get(prefix + "Remaining"))
+		if err != nil {
+			continue
+		}
+
+		quota := Quota{Name: host, Remaining: remaining, Updated: now}
+		// The IETF draft allows "100, 100;w=60"; only the first value matters
+		limit := header.Get(prefix + "Limit")
+		if i := strings.IndexAny(limit, ",;"); i >= 0 {
+			limit = limit[:i]
+		}
+		quota.Limit, _ = strconv.Atoi(limit)
+
+		if reset, err := strconv.ParseInt(header.Get(prefix+"Reset"), 10, 64); err == nil {
+			// X-RateLimit-Reset is usually an epoch, RateLimit-Reset always
+			// seconds from now
+			if reset > 1_000_000_000 {
+				quota.Reset = time.Unix(reset, 0)
+			} else {
+				quota.Reset = now.Add(time.Duration(reset) * time.Second)
+			}
+		}
+		return quota, true
+	}
+	return Quota{}, false
+}
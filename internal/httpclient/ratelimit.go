@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket rate: Rate requests per second on average, with
+// bursts of up to Burst requests
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// bucket is the token bucket of a host
+type bucket struct {
+	mu          sync.Mutex
+	limit       Limit
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time // Set from Retry-After, blocks the whole host
+}
+
+func newBucket(limit Limit) *bucket {
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	return &bucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+}
+
+// wait blocks until a token is available or the context ends
+func (b *bucket) wait(ctx context.Context) error {
+	for {
+		delay := b.reserve(time.Now())
+		if delay <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// reserve takes a token and returns zero, or returns how long to wait
+// before trying again
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Before(b.pausedUntil) {
+		return b.pausedUntil.Sub(now)
+	}
+	if b.limit.Rate <= 0 {
+		return 0
+	}
+
+	elapsed := now.Sub(b.last).Seconds()
+	b.tokens = min(b.tokens+elapsed*b.limit.Rate, float64(b.limit.Burst))
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.limit.Rate * float64(time.Second))
+}
+
+// setLimit changes the rate, keeping the tokens already accumulated
+func (b *bucket) setLimit(limit Limit) {
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.limit = limit
+	b.tokens = min(b.tokens, float64(limit.Burst))
+}
+
+// pause blocks the host until t
+func (b *bucket) pause(t time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if t.After(b.pausedUntil) {
+		b.pausedUntil = t
+	}
+}
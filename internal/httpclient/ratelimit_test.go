@@ -0,0 +1,82 @@
+package httpclient
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestBucketBurstAndRefill(t *testing.T) {
+	b := newBucket(Limit{Rate: 2, Burst: 2})
+	now := b.last
+
+	for i := range 2 {
+		if delay := b.reserve(now); delay != 0 {
+			t.Fatalf("request %d of the burst waited %s", i+1, delay)
+		}
+	}
+	delay := b.reserve(now)
+	if delay <= 0 || delay > 500*time.Millisecond {
+		t.Errorf("third request waits %s, want up to 500ms at 2 requests per second", delay)
+	}
+
+	if delay := b.reserve(now.Add(500 * time.Millisecond)); delay != 0 {
+		t.Errorf("request after refill waited %s", delay)
+	}
+
+	// Tokens never exceed the burst, however long the bucket was idle
+	later := now.Add(time.Hour)
+	b.reserve(later)
+	b.reserve(later)
+	if delay := b.reserve(later); delay == 0 {
+		t.Error("idle bucket allowed more than its burst")
+	}
+}
+
+func TestBucketPause(t *testing.T) {
+	b := newBucket(Limit{Rate: 100, Burst: 10})
+	now := time.Now()
+	b.pause(now.Add(time.Second))
+
+	if delay := b.reserve(now); delay < 900*time.Millisecond {
+		t.Errorf("paused bucket waits %s, want about a second", delay)
+	}
+	if delay := b.reserve(now.Add(time.Second)); delay != 0 {
+		t.Errorf("bucket still paused after the pause ended: %s", delay)
+	}
+}
+
+func TestBucketSetLimit(t *testing.T) {
+	b := newBucket(Limit{Rate: 10, Burst: 5})
+	b.setLimit(Limit{Rate: 1, Burst: 1})
+	now := b.last
+
+	b.reserve(now)
+	if delay := b.reserve(now); delay < 900*time.Millisecond {
+		t.Errorf("second request waits %s, want about a second at the new rate", delay)
+	}
+}
+
+func TestWaitHonoursContext(t *testing.T) {
+	b := newBucket(Limit{Rate: 0.001, Burst: 1})
+	b.reserve(time.Now())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.wait(ctx); err == nil {
+		t.Error("wait returned without a token or a cancelled context")
+	}
+}
+
+func TestConfiguredLimit(t *testing.T) {
+	client := New(Options{
+		Limit:      Limit{Rate: 2, Burst: 2},
+		HostLimits: map[string]Limit{"api.example.com": {Rate: 1, Burst: 1}},
+	})
+	if limit := client.ConfiguredLimit("api.example.com"); limit.Rate != 1 {
+		t.Errorf("host limit = %+v, want the override", limit)
+	}
+	if limit := client.ConfiguredLimit("other.example.com"); limit.Rate != 2 {
+		t.Errorf("default limit = %+v, want the Options limit", limit)
+	}
+}
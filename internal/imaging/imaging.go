@@ -0,0 +1,225 @@
+// Package imaging decodes provider artwork, resizes it to the box a frontend
+// expects and re-encodes it without metadata.
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+)
+
+// Mode decides how an image is fitted to the target box
+type Mode string
+
+const (
+	// ModeFit scales the image to fit inside the box, keeping its aspect
+	// ratio. The result may be smaller than the box on one axis.
+	ModeFit Mode = "fit"
+
+	// ModeFill scales the image to cover the box and crops the overflow
+	// around the center
+	ModeFill Mode = "fill"
+
+	// ModePad scales the image to fit inside the box and centers it on a
+	// canvas of exactly the box size
+	ModePad Mode = "pad"
+)
+
+// Format is the encoding of the processed image
+type Format string
+
+const (
+	FormatPNG  Format = "png"
+	FormatJPEG Format = "jpg"
+)
+
+// ErrUnsupportedFormat is returned by Decode for unknown image formats
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// DefaultJPEGQuality is used when Options.Quality is not set
+const DefaultJPEGQuality = 90
+
+// Options describes the target of an image
+type Options struct {
+	Width        int         // Target box width, 0 to derive from Height
+	Height       int         // Target box height, 0 to derive from Width
+	Mode         Mode        // Defaults to ModeFit
+	Format       Format      // Defaults to FormatPNG
+	Quality      int         // JPEG quality 1-100
+	Background   color.Color // Padding and JPEG background, transparent/black when nil
+	AllowUpscale bool        // Scale images smaller than the box up
+}
+
+// Ext returns the file extension for the format, without the dot
+func (f Format) Ext() string {
+	if f == "" {
+		return string(FormatPNG)
+	}
+	return string(f)
+}
+
+// Decode reads a PNG, JPEG, WebP or GIF image. Only the first GIF frame is
+// used.
+func Decode(r io.Reader) (image.Image, string, error) {
+	var header [16]byte
+	buffered := &bytes.Buffer{}
+	n, _ := io.ReadFull(io.TeeReader(r, buffered), header[:])
+	content := io.MultiReader(buffered, r)
+
+	var (
+		img    image.Image
+		format string
+		err    error
+	)
+	switch {
+	case bytes.HasPrefix(header[:n], []byte("\x89PNG")):
+		format = "png"
+		img, err = png.Decode(content)
+	case bytes.HasPrefix(header[:n], []byte("\xff\xd8")):
+		format = "jpeg"
+		img, err = jpeg.Decode(content)
+	case bytes.HasPrefix(header[:n], []byte("GIF8")):
+		format = "gif"
+		img, err = gif.Decode(content)
+	case n >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("WEBP")):
+		format = "webp"
+		img, err = webp.Decode(content)
+	default:
+		return nil, "", ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, format, fmt.Errorf("failed to decode %s: %w", format, err)
+	}
+	return img, format, nil
+}
+
+// Process resizes the image to the target box following the mode
+func Process(src image.Image, opts Options) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	if srcW == 0 || srcH == 0 || (opts.Width <= 0 && opts.Height <= 0) {
+		return src
+	}
+
+	// A derived side rounds down to 0 for very tall or wide sources
+	boxW, boxH := opts.Width, opts.Height
+	if boxW <= 0 {
+		boxW = max(1, srcW*boxH/srcH)
+	}
+	if boxH <= 0 {
+		boxH = max(1, srcH*boxW/srcW)
+	}
+
+	switch opts.Mode {
+	case ModeFill:
+		return fill(src, boxW, boxH, opts.AllowUpscale)
+	case ModePad:
+		fitted := fit(src, boxW, boxH, opts.AllowUpscale)
+		return pad(fitted, boxW, boxH, opts.Background)
+	default:
+		return fit(src, boxW, boxH, opts.AllowUpscale)
+	}
+}
+
+// fit scales src to fit inside the box
+func fit(src image.Image, boxW, boxH int, upscale bool) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	scale := min(float64(boxW)/float64(srcW), float64(boxH)/float64(srcH))
+	if scale >= 1 && !upscale {
+		return src
+	}
+
+	w := max(1, int(float64(srcW)*scale+0.5))
+	h := max(1, int(float64(srcH)*scale+0.5))
+	return scaleTo(src, bounds, w, h)
+}
+
+// fill crops src to the aspect ratio of the box and scales it to the box
+func fill(src image.Image, boxW, boxH int, upscale bool) image.Image {
+	bounds := src.Bounds()
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+
+	// Largest centered region with the aspect ratio of the box
+	cropW, cropH := srcW, max(1, srcW*boxH/boxW)
+	if cropH > srcH {
+		cropW, cropH = max(1, srcH*boxW/boxH), srcH
+	}
+	x := bounds.Min.X + (srcW-cropW)/2
+	y := bounds.Min.Y + (srcH-cropH)/2
+	crop := image.Rect(x, y, x+cropW, y+cropH)
+
+	if cropW <= boxW && !upscale {
+		return scaleTo(src, crop, cropW, cropH)
+	}
+	return scaleTo(src, crop, boxW, boxH)
+}
+
+// pad centers img on a canvas of the box size
+func pad(img image.Image, boxW, boxH int, background color.Color) image.Image {
+	canvas := image.NewNRGBA(image.Rect(0, 0, boxW, boxH))
+	if background != nil {
+		draw.Draw(canvas, canvas.Bounds(), image.NewUniform(background), image.Point{}, draw.Src)
+	}
+
+	bounds := img.Bounds()
+	offset := image.Pt((boxW-bounds.Dx())/2, (boxH-bounds.Dy())/2)
+	draw.Draw(canvas, bounds.Sub(bounds.Min).Add(offset), img, bounds.Min, draw.Over)
+	return canvas
+}
+
+// scaleTo resamples the region of src to w x h with a Catmull-Rom filter
+func scaleTo(src image.Image, region image.Rectangle, w, h int) image.Image {
+	dst := image.NewNRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, region, draw.Src, nil)
+	return dst
+}
+
+// Encode writes img in the requested format. Only pixel data is written, so
+// EXIF, ICC and text chunks from the source are dropped.
+func Encode(w io.Writer, img image.Image, opts Options) error {
+	switch opts.Format {
+	case FormatJPEG:
+		quality := opts.Quality
+		if quality <= 0 || quality > 100 {
+			quality = DefaultJPEGQuality
+		}
+		return jpeg.Encode(w, flatten(img, opts.Background), &jpeg.Options{Quality: quality})
+	case FormatPNG, "":
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		return encoder.Encode(w, img)
+	default:
+		return fmt.Errorf("unsupported output format %q", opts.Format)
+	}
+}
+
+// flatten draws img over an opaque background, as JPEG has no alpha
+func flatten(img image.Image, background color.Color) image.Image {
+	if background == nil {
+		background = color.Black
+	}
+	bounds := img.Bounds()
+	canvas := image.NewRGBA(bounds)
+	draw.Draw(canvas, bounds, image.NewUniform(background), image.Point{}, draw.Src)
+	draw.Draw(canvas, bounds, img, bounds.Min, draw.Over)
+	return canvas
+}
+
+// Transform decodes r, processes the image and encodes it to w
+func Transform(w io.Writer, r io.Reader, opts Options) error {
+	img, _, err := Decode(r)
+	if err != nil {
+		return err
+	}
+	return Encode(w, Process(img, opts), opts)
+}
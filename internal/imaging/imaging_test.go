@@ -0,0 +1,111 @@
+package imaging
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+var (
+	red   = color.NRGBA{R: 255, A: 255}
+	green = color.NRGBA{G: 255, A: 255}
+	blue  = color.NRGBA{B: 255, A: 255}
+)
+
+// bands returns a w x h image split in vertical bands of the given colors
+func bands(w, h int, colors ...color.NRGBA) *image.NRGBA {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for x := range w {
+		c := colors[x*len(colors)/w]
+		for y := range h {
+			img.SetNRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func assertSize(t *testing.T, img image.Image, w, h int) {
+	t.Helper()
+	if got := img.Bounds().Size(); got != image.Pt(w, h) {
+		t.Fatalf("size = %v, want %dx%d", got, w, h)
+	}
+}
+
+func assertColor(t *testing.T, img image.Image, x, y int, want color.NRGBA) {
+	t.Helper()
+	got := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+	if got != want {
+		t.Errorf("pixel (%d, %d) = %v, want %v", x, y, got, want)
+	}
+}
+
+func TestProcessSizes(t *testing.T) {
+	tests := []struct {
+		name       string
+		srcW, srcH int
+		opts       Options
+		wantW      int
+		wantH      int
+	}{
+		{"fit downscales", 400, 200, Options{Width: 100, Height: 100}, 100, 50},
+		{"fit keeps small images", 50, 20, Options{Width: 100, Height: 100}, 50, 20},
+		{"fit upscales when allowed", 50, 20, Options{Width: 100, Height: 100, AllowUpscale: true}, 100, 40},
+		{"height derived from width", 400, 200, Options{Width: 100}, 100, 50},
+		{"width derived from height", 400, 200, Options{Height: 50}, 100, 50},
+		{"fill covers the box", 400, 200, Options{Width: 100, Height: 100, Mode: ModeFill}, 100, 100},
+		{"fill crops small images without scaling", 60, 30, Options{Width: 100, Height: 100, Mode: ModeFill}, 30, 30},
+		{"fill upscales when allowed", 60, 30, Options{Width: 100, Height: 100, Mode: ModeFill, AllowUpscale: true}, 100, 100},
+		{"pad fills the box", 400, 200, Options{Width: 100, Height: 100, Mode: ModePad}, 100, 100},
+		{"pad keeps small images on the canvas", 50, 20, Options{Width: 100, Height: 100, Mode: ModePad}, 100, 100},
+		{"no box", 40, 30, Options{}, 40, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Process(bands(tt.srcW, tt.srcH, red), tt.opts)
+			assertSize(t, got, tt.wantW, tt.wantH)
+		})
+	}
+}
+
+func TestProcessFillCropsAroundCenter(t *testing.T) {
+	got := Process(bands(300, 100, red, green, blue), Options{Width: 50, Height: 50, Mode: ModeFill})
+	assertSize(t, got, 50, 50)
+	for _, x := range []int{0, 25, 49} {
+		assertColor(t, got, x, 25, green)
+	}
+}
+
+func TestProcessPadCentersImage(t *testing.T) {
+	background := color.NRGBA{R: 10, G: 20, B: 30, A: 255}
+	got := Process(bands(100, 50, red), Options{Width: 100, Height: 100, Mode: ModePad, Background: background})
+	assertSize(t, got, 100, 100)
+	assertColor(t, got, 50, 10, background)
+	assertColor(t, got, 50, 50, red)
+	assertColor(t, got, 50, 90, background)
+}
+
+func TestProcessExtremeAspectRatios(t *testing.T) {
+	// The derived side of the box would round down to 0
+	tests := []struct {
+		name       string
+		srcW, srcH int
+		opts       Options
+	}{
+		{"tall source, height only", 1, 1000, Options{Height: 100}},
+		{"wide source, width only", 1000, 1, Options{Width: 100}},
+	}
+
+	for _, tt := range tests {
+		for _, mode := range []Mode{ModeFit, ModeFill, ModePad} {
+			t.Run(tt.name+"/"+string(mode), func(t *testing.T) {
+				opts := tt.opts
+				opts.Mode = mode
+				got := Process(bands(tt.srcW, tt.srcH, red), opts)
+				if size := got.Bounds().Size(); size.X < 1 || size.Y < 1 || size.X > 100 || size.Y > 100 {
+					t.Errorf("size = %v, want within 1x1 and 100x100", size)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// inputNames são os nomes das ações usados nas gravações de input
+var inputNames = map[InputType]string{
+	InputUp:         "up",
+	InputDown:       "down",
+	InputLeft:       "left",
+	InputRight:      "right",
+	InputConfirm:    "confirm",
+	InputBack:       "back",
+	InputMenu:       "menu",
+	InputSelect:     "select",
+	InputX:          "x",
+	InputY:          "y",
+	InputL1:         "l1",
+	InputR1:         "r1",
+	InputL2:         "l2",
+	InputR2:         "r2",
+	InputScrollUp:   "scroll-up",
+	InputScrollDown: "scroll-down",
+}
+
+func (t InputType) String() string {
+	if name, ok := inputNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("input(%d)", int(t))
+}
+
+// ParseInputType converte o nome de uma ação ("confirm") no seu InputType
+func ParseInputType(name string) (InputType, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for inputType, inputName := range inputNames {
+		if inputName == name {
+			return inputType, true
+		}
+	}
+	return 0, false
+}
+
+// keyMappings mapeia as teclas. No TrimUI os botões frontais também chegam
+// como as teclas A/B/X/Y.
+var keyMappings = map[sdl.Scancode]InputType{
+	sdl.SCANCODE_UP:           InputUp,
+	sdl.SCANCODE_DOWN:         InputDown,
+	sdl.SCANCODE_LEFT:         InputLeft,
+	sdl.SCANCODE_RIGHT:        InputRight,
+	sdl.SCANCODE_RETURN:       InputConfirm,
+	sdl.SCANCODE_SPACE:        InputConfirm,
+	sdl.SCANCODE_ESCAPE:       InputBack,
+	sdl.SCANCODE_A:            InputConfirm, // Para TrimUI
+	sdl.SCANCODE_B:            InputBack,    // Para TrimUI
+	sdl.SCANCODE_X:            InputX,
+	sdl.SCANCODE_Y:            InputY,
+	sdl.SCANCODE_PAGEUP:       InputL1,
+	sdl.SCANCODE_PAGEDOWN:     InputR1,
+	sdl.SCANCODE_LEFTBRACKET:  InputL2,
+	sdl.SCANCODE_RIGHTBRACKET: InputR2,
+}
+
+// buttonMappings mapeia os botões dos Game Controllers
+var buttonMappings = map[sdl.GameControllerButton]InputType{
+	sdl.CONTROLLER_BUTTON_DPAD_UP:       InputUp,
+	sdl.CONTROLLER_BUTTON_DPAD_DOWN:     InputDown,
+	sdl.CONTROLLER_BUTTON_DPAD_LEFT:     InputLeft,
+	sdl.CONTROLLER_BUTTON_DPAD_RIGHT:    InputRight,
+	sdl.CONTROLLER_BUTTON_A:             InputConfirm,
+	sdl.CONTROLLER_BUTTON_B:             InputBack,
+	sdl.CONTROLLER_BUTTON_X:             InputX,
+	sdl.CONTROLLER_BUTTON_Y:             InputY,
+	sdl.CONTROLLER_BUTTON_START:         InputMenu,
+	sdl.CONTROLLER_BUTTON_BACK:          InputSelect,
+	sdl.CONTROLLER_BUTTON_LEFTSHOULDER:  InputL1,
+	sdl.CONTROLLER_BUTTON_RIGHTSHOULDER: InputR1,
+}
+
+// axisMappings mapeia as metades dos eixos. Os gatilhos são eixos que vão
+// de 0 ao máximo, só a metade positiva conta.
+var axisMappings = map[axisHalf]InputType{
+	{axis: sdl.CONTROLLER_AXIS_LEFTX, positive: false}:       InputLeft,
+	{axis: sdl.CONTROLLER_AXIS_LEFTX, positive: true}:        InputRight,
+	{axis: sdl.CONTROLLER_AXIS_LEFTY, positive: false}:       InputUp,
+	{axis: sdl.CONTROLLER_AXIS_LEFTY, positive: true}:        InputDown,
+	{axis: sdl.CONTROLLER_AXIS_TRIGGERLEFT, positive: true}:  InputL2,
+	{axis: sdl.CONTROLLER_AXIS_TRIGGERRIGHT, positive: true}: InputR2,
+	{axis: sdl.CONTROLLER_AXIS_RIGHTY, positive: false}:      InputScrollUp,
+	{axis: sdl.CONTROLLER_AXIS_RIGHTY, positive: true}:       InputScrollDown,
+}
+
+// axisHalf é uma das metades de um eixo do controle
+type axisHalf struct {
+	axis     sdl.GameControllerAxis
+	positive bool
+}
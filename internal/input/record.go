@@ -0,0 +1,196 @@
+package input
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// recordVersion muda quando o formato do arquivo de gravação muda
+const recordVersion = 1
+
+// recordHeader é a primeira linha de uma gravação
+type recordHeader struct {
+	Version int `json:"version"`
+}
+
+// recordLine é um evento gravado, uma linha JSON por evento. Ações e fases
+// são gravadas pelo nome, então o arquivo continua válido se a ordem das
+// constantes mudar, e pode ser escrito à mão.
+type recordLine struct {
+	Frame     uint64 `json:"frame"`
+	Type      string `json:"type"`
+	Phase     string `json:"phase"`
+	Device    int32  `json:"device"`
+	Time      uint64 `json:"time"`
+	PressTime uint64 `json:"press_time"`
+}
+
+// Recorded é um InputEvent e o frame em que foi entregue às telas, contado
+// a partir do início da gravação
+type Recorded struct {
+	Frame uint64
+	Event InputEvent
+}
+
+// ParseInputPhase converte o nome de uma fase ("press") na sua InputPhase
+func ParseInputPhase(name string) (InputPhase, bool) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for phase, phaseName := range phaseNames {
+		if phaseName == name {
+			return phase, true
+		}
+	}
+	return 0, false
+}
+
+// Recorder grava os InputEvents de cada frame em um arquivo, para serem
+// reproduzidos depois por um Playback
+type Recorder struct {
+	file   *os.File
+	writer *bufio.Writer
+}
+
+// NewRecorder cria o arquivo de gravação, substituindo um existente
+func NewRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create input recording: %w", err)
+	}
+	r := &Recorder{file: file, writer: bufio.NewWriter(file)}
+	if err := r.writeLine(recordHeader{Version: recordVersion}); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *Recorder) writeLine(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode input recording: %w", err)
+	}
+	data = append(data, '\n')
+	if _, err := r.writer.Write(data); err != nil {
+		return fmt.Errorf("failed to write input recording: %w", err)
+	}
+	return nil
+}
+
+// Record grava os eventos entregues no frame. Frames sem eventos não
+// ocupam espaço; o arquivo é descarregado a cada frame com eventos para
+// sobreviver a um crash.
+func (r *Recorder) Record(frame uint64, events []InputEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	for _, event := range events {
+		line := recordLine{
+			Frame:     frame,
+			Type:      event.Type.String(),
+			Phase:     event.Phase.String(),
+			Device:    int32(event.Device),
+			Time:      event.Time,
+			PressTime: event.PressTime,
+		}
+		if err := r.writeLine(line); err != nil {
+			return err
+		}
+	}
+	if err := r.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write input recording: %w", err)
+	}
+	return nil
+}
+
+// Close descarrega e fecha o arquivo
+func (r *Recorder) Close() error {
+	flushErr := r.writer.Flush()
+	return errors.Join(flushErr, r.file.Close())
+}
+
+// LoadRecording lê uma gravação feita pelo Recorder
+func LoadRecording(path string) ([]Recorded, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input recording: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	if !scanner.Scan() {
+		return nil, fmt.Errorf("input recording %s is empty", path)
+	}
+	var header recordHeader
+	if err := json.Unmarshal(scanner.Bytes(), &header); err != nil || header.Version != recordVersion {
+		return nil, fmt.Errorf("input recording %s: unsupported or corrupt file", path)
+	}
+
+	var records []Recorded
+	for lineNumber := 2; scanner.Scan(); lineNumber++ {
+		var line recordLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("input recording %s line %d: %w", path, lineNumber, err)
+		}
+		inputType, ok := ParseInputType(line.Type)
+		if !ok {
+			return nil, fmt.Errorf("input recording %s line %d: unknown action %q", path, lineNumber, line.Type)
+		}
+		phase, ok := ParseInputPhase(line.Phase)
+		if !ok {
+			return nil, fmt.Errorf("input recording %s line %d: unknown phase %q", path, lineNumber, line.Phase)
+		}
+		records = append(records, Recorded{
+			Frame: line.Frame,
+			Event: InputEvent{
+				Type:      inputType,
+				Phase:     phase,
+				Device:    sdl.JoystickID(line.Device),
+				Time:      line.Time,
+				PressTime: line.PressTime,
+			},
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input recording: %w", err)
+	}
+	return records, nil
+}
+
+// Playback entrega eventos gravados presos ao frame: o evento gravado no
+// frame N sai na N-ésima chamada de Next, por mais que o frame demore. Assim
+// a reprodução não depende da velocidade da máquina.
+type Playback struct {
+	records []Recorded
+	next    int    // Índice do próximo registro
+	frame   uint64 // Frame da próxima chamada de Next
+	events  []InputEvent
+}
+
+// NewPlayback cria a reprodução dos registros, que devem estar em ordem de
+// frame
+func NewPlayback(records []Recorded) *Playback {
+	return &Playback{records: records}
+}
+
+// Next devolve os eventos do frame atual e avança um frame. O slice vale
+// até a próxima chamada.
+func (p *Playback) Next() []InputEvent {
+	p.events = p.events[:0]
+	for p.next < len(p.records) && p.records[p.next].Frame <= p.frame {
+		p.events = append(p.events, p.records[p.next].Event)
+		p.next++
+	}
+	p.frame++
+	return p.events
+}
+
+// Done diz se todos os eventos já foram entregues
+func (p *Playback) Done() bool {
+	return p.next >= len(p.records)
+}
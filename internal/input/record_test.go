@@ -0,0 +1,80 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestRecordPlaybackRoundTrip(t *testing.T) {
+	script := NewScript().
+		Tap(InputDown, InputDown).
+		Wait(3).
+		Hold(InputRight, 4).
+		LongPress(InputConfirm).
+		Wait(2).
+		Tap(InputBack)
+
+	// Grava frame a frame como o App, incluindo os frames sem eventos
+	path := filepath.Join(t.TempDir(), "input.jsonl")
+	recorder, err := NewRecorder(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := script.Playback()
+	var frames [][]InputEvent
+	for frame := range script.Frames() {
+		events := slices.Clone(source.Next())
+		frames = append(frames, events)
+		if err := recorder.Record(frame, events); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := recorder.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := LoadRecording(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := script.Records(); !slices.Equal(records, want) {
+		t.Fatalf("loaded records differ from the script:\n got %v\nwant %v", records, want)
+	}
+
+	// A reprodução entrega cada evento no mesmo frame da gravação
+	playback := NewPlayback(records)
+	for frame, want := range frames {
+		if got := playback.Next(); !slices.Equal(got, want) {
+			t.Errorf("frame %d: playback = %v, want %v", frame, got, want)
+		}
+	}
+	if !playback.Done() {
+		t.Error("playback not done after the last recorded frame")
+	}
+}
+
+func TestLoadRecordingRejectsBadFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"empty", ""},
+		{"unknown version", `{"version":99}` + "\n"},
+		{"unknown action", `{"version":1}` + "\n" + `{"frame":0,"type":"jump","phase":"press"}` + "\n"},
+		{"unknown phase", `{"version":1}` + "\n" + `{"frame":0,"type":"up","phase":"hold"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "input.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadRecording(path); err == nil {
+				t.Error("LoadRecording accepted the file")
+			}
+		})
+	}
+}
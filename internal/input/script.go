@@ -0,0 +1,93 @@
+package input
+
+import "slices"
+
+// scriptFrameMs é a duração de um frame nos tempos dos eventos de um Script
+const scriptFrameMs = 16
+
+// Script monta uma sequência de inputs frame a frame, para dirigir telas sem
+// dispositivos, como nos testes. Os métodos avançam o frame atual e podem ser
+// encadeados: NewScript().Tap(InputDown).Wait(2).Tap(InputConfirm).
+type Script struct {
+	frame   uint64
+	records []Recorded
+}
+
+func NewScript() *Script {
+	return &Script{}
+}
+
+func (s *Script) push(inputType InputType, phase InputPhase, pressFrame uint64) {
+	s.records = append(s.records, Recorded{
+		Frame: s.frame,
+		Event: InputEvent{
+			Type:      inputType,
+			Phase:     phase,
+			Device:    KeyboardDevice,
+			Time:      s.frame * scriptFrameMs,
+			PressTime: pressFrame * scriptFrameMs,
+		},
+	})
+}
+
+// Tap pressiona cada ação em um frame e a solta no seguinte
+func (s *Script) Tap(inputTypes ...InputType) *Script {
+	for _, inputType := range inputTypes {
+		s.push(inputType, PhasePress, s.frame)
+		s.frame++
+		s.push(inputType, PhaseRelease, s.frame-1)
+		s.frame++
+	}
+	return s
+}
+
+// Hold mantém a ação pressionada por frames frames e a solta no seguinte.
+// Ações que repetem, como as direções, repetem a cada frame.
+func (s *Script) Hold(inputType InputType, frames int) *Script {
+	press := s.frame
+	s.push(inputType, PhasePress, press)
+	s.frame++
+	for i := 1; i < frames; i++ {
+		if inputType.repeats() {
+			s.push(inputType, PhaseRepeat, press)
+		}
+		s.frame++
+	}
+	s.push(inputType, PhaseRelease, press)
+	s.frame++
+	return s
+}
+
+// LongPress pressiona a ação, gera a pressão longa no frame seguinte e a
+// solta depois
+func (s *Script) LongPress(inputType InputType) *Script {
+	press := s.frame
+	s.push(inputType, PhasePress, press)
+	s.frame++
+	s.push(inputType, PhaseLongPress, press)
+	s.frame++
+	s.push(inputType, PhaseRelease, press)
+	s.frame++
+	return s
+}
+
+// Wait avança frames frames sem input
+func (s *Script) Wait(frames int) *Script {
+	s.frame += uint64(frames)
+	return s
+}
+
+// Frames devolve quantos frames o script ocupa
+func (s *Script) Frames() uint64 {
+	return s.frame
+}
+
+// Records devolve os eventos do script, no formato das gravações
+func (s *Script) Records() []Recorded {
+	return slices.Clone(s.records)
+}
+
+// Playback devolve uma reprodução do script
+func (s *Script) Playback() *Playback {
+	return NewPlayback(s.Records())
+}
@@ -0,0 +1,304 @@
+package input
+
+import (
+	"log"
+	"slices"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"retroart-sdl2/internal/config"
+)
+
+// controller é um Game Controller aberto
+type controller struct {
+	pad  *sdl.GameController
+	guid string
+	name string
+}
+
+// heldKey identifica um input pressionado em um dispositivo
+type heldKey struct {
+	device sdl.JoystickID
+	source any // sdl.Scancode, sdl.GameControllerButton ou axisHalf
+}
+
+// heldInput é uma ação pressionada e o momento da próxima repetição
+type heldInput struct {
+	inputType   InputType
+	pressTime   uint64
+	next        uint64
+	longPressed bool
+}
+
+// repeatTiming controla a repetição das direções mantidas pressionadas
+type repeatTiming struct {
+	delay     uint64 // ms até a primeira repetição
+	interval  uint64 // ms entre as primeiras repetições
+	min       uint64 // ms entre repetições na velocidade máxima
+	accel     uint64 // ms repetindo até chegar à velocidade máxima
+	curve     string
+	longPress uint64 // ms até a pressão longa
+}
+
+func newRepeatTiming(cfg config.InputConfig) repeatTiming {
+	return repeatTiming{
+		delay:     cfg.RepeatDelayMs,
+		interval:  cfg.DirectionalThrottleMs,
+		min:       min(cfg.RepeatMinMs, cfg.DirectionalThrottleMs),
+		accel:     cfg.RepeatAccelMs,
+		curve:     cfg.RepeatCurve,
+		longPress: cfg.LongPressMs,
+	}
+}
+
+// next devolve o intervalo até a próxima repetição de uma direção
+// pressionada há held ms. O intervalo diminui de interval até min ao longo
+// de accel ms de repetição, seguindo a curva.
+func (r repeatTiming) next(held uint64) uint64 {
+	if r.curve == config.RepeatCurveNone || r.accel == 0 || held <= r.delay {
+		return r.interval
+	}
+	progress := min(float64(held-r.delay)/float64(r.accel), 1)
+	if r.curve == config.RepeatCurveEaseIn {
+		progress *= progress
+	}
+	return r.interval - uint64(progress*float64(r.interval-r.min))
+}
+
+// Translator converte os eventos SDL bombeados pelo loop principal em
+// InputEvents. Cada input gera um Press e um Release; direções mantidas
+// pressionadas repetem cada vez mais rápido e as demais ações geram um
+// LongPress. Tudo roda na thread principal.
+//
+// Os controles são abertos e fechados conforme o SDL avisa a conexão, então
+// controles pareados depois da inicialização funcionam, vários ao mesmo tempo.
+type Translator struct {
+	queue  Queue
+	repeat repeatTiming
+
+	// Um eixo pressiona ao passar de axisThreshold e só solta ao voltar para
+	// dentro de axisDeadzone, para um analógico perto do limite não oscilar
+	axisThreshold int
+	axisDeadzone  int
+
+	controllers map[sdl.JoystickID]*controller // Por instance ID
+	axes        map[heldKey]bool               // Direções de eixo além do limite
+	held        map[heldKey]*heldInput
+	order       []heldKey     // Inputs de held na ordem em que foram pressionados
+	devices     []DeviceEvent // Conexões desde o último DrainDevices
+}
+
+// NewTranslator cria o tradutor com os tempos de repetição e pressão longa
+// da configuração
+func NewTranslator(cfg config.InputConfig) *Translator {
+	return &Translator{
+		repeat:        newRepeatTiming(cfg),
+		axisThreshold: cfg.AxisThreshold,
+		axisDeadzone:  cfg.AxisDeadzone,
+		controllers:   make(map[sdl.JoystickID]*controller),
+		axes:          make(map[heldKey]bool),
+		held:          make(map[heldKey]*heldInput),
+	}
+}
+
+// repeats diz se a ação repete enquanto o input fica pressionado: as
+// direções e as de rolagem. As demais ações geram um LongPress no lugar.
+func (t InputType) repeats() bool {
+	switch t {
+	case InputUp, InputDown, InputLeft, InputRight, InputL1, InputR1, InputL2, InputR2,
+		InputScrollUp, InputScrollDown:
+		return true
+	}
+	return false
+}
+
+// Translate processa um evento SDL. Eventos que não são de input são
+// ignorados.
+func (t *Translator) Translate(event sdl.Event) {
+	switch e := event.(type) {
+	case *sdl.KeyboardEvent:
+		// A repetição do sistema é ignorada, o tradutor tem a sua
+		if e.Repeat != 0 {
+			return
+		}
+		if inputType, ok := keyMappings[e.Keysym.Scancode]; ok {
+			key := heldKey{device: KeyboardDevice, source: e.Keysym.Scancode}
+			t.handle(key, inputType, e.State == sdl.PRESSED, uint64(e.Timestamp))
+		}
+
+	case *sdl.ControllerButtonEvent:
+		if _, ok := t.controllers[e.Which]; !ok {
+			return
+		}
+		button := sdl.GameControllerButton(e.Button)
+		if inputType, ok := buttonMappings[button]; ok {
+			key := heldKey{device: e.Which, source: button}
+			t.handle(key, inputType, e.State == sdl.PRESSED, uint64(e.Timestamp))
+		}
+
+	case *sdl.ControllerAxisEvent:
+		if _, ok := t.controllers[e.Which]; !ok {
+			return
+		}
+		for _, positive := range []bool{false, true} {
+			half := axisHalf{axis: sdl.GameControllerAxis(e.Axis), positive: positive}
+			inputType, ok := axisMappings[half]
+			if !ok {
+				continue
+			}
+			key := heldKey{device: e.Which, source: half}
+
+			// Deslocamento na direção desta metade do eixo
+			value := int(e.Value)
+			if !positive {
+				value = -value
+			}
+			pressed := value > t.axisThreshold
+			if t.axes[key] {
+				pressed = value > t.axisDeadzone
+			}
+			if pressed != t.axes[key] {
+				t.axes[key] = pressed
+				t.handle(key, inputType, pressed, uint64(e.Timestamp))
+			}
+		}
+
+	case *sdl.ControllerDeviceEvent:
+		switch e.Type {
+		case sdl.CONTROLLERDEVICEADDED:
+			// Which é o índice do dispositivo na adição
+			t.open(int(e.Which))
+		case sdl.CONTROLLERDEVICEREMOVED:
+			// e na remoção é o instance ID
+			t.close(e.Which, uint64(e.Timestamp))
+		}
+	}
+}
+
+// handle converte o pressionamento ou a soltura de um input mapeado para
+// inputType em InputEvent
+func (t *Translator) handle(key heldKey, inputType InputType, pressed bool, now uint64) {
+	if !pressed {
+		t.release(key, now)
+		return
+	}
+
+	if _, ok := t.held[key]; ok {
+		return
+	}
+	held := &heldInput{inputType: inputType, pressTime: now, next: now + t.repeat.delay}
+	t.held[key] = held
+	t.order = append(t.order, key)
+	t.push(held, PhasePress, key.device, now)
+}
+
+// release solta um input pressionado
+func (t *Translator) release(key heldKey, now uint64) {
+	if held, ok := t.held[key]; ok {
+		delete(t.held, key)
+		t.order = slices.DeleteFunc(t.order, func(k heldKey) bool { return k == key })
+		t.push(held, PhaseRelease, key.device, now)
+	}
+}
+
+func (t *Translator) push(held *heldInput, phase InputPhase, device sdl.JoystickID, now uint64) {
+	t.queue.Push(InputEvent{
+		Type:      held.inputType,
+		Phase:     phase,
+		Device:    device,
+		Time:      now,
+		PressTime: held.pressTime,
+	})
+}
+
+// Tick repete as direções mantidas pressionadas e gera as pressões longas.
+// Deve ser chamado uma vez por frame, depois dos eventos, com o tempo atual
+// do SDL em ms. Os inputs são percorridos na ordem em que foram
+// pressionados, para eventos do mesmo frame saírem sempre na mesma ordem.
+func (t *Translator) Tick(now uint64) {
+	for _, key := range t.order {
+		held := t.held[key]
+		switch {
+		case held.inputType.repeats():
+			if now >= held.next {
+				held.next = now + t.repeat.next(now-held.pressTime)
+				t.push(held, PhaseRepeat, key.device, now)
+			}
+		case !held.longPressed && now-held.pressTime >= t.repeat.longPress:
+			held.longPressed = true
+			t.push(held, PhaseLongPress, key.device, now)
+		}
+	}
+}
+
+// Drain devolve os eventos do frame, esvaziando a fila
+func (t *Translator) Drain() []InputEvent {
+	return t.queue.Drain()
+}
+
+// DrainDevices devolve as conexões e desconexões desde a última chamada
+func (t *Translator) DrainDevices() []DeviceEvent {
+	devices := t.devices
+	t.devices = nil
+	return devices
+}
+
+// open abre o Game Controller do índice dado. O SDL também envia a adição
+// dos controles já conectados na inicialização.
+func (t *Translator) open(index int) {
+	if !sdl.IsGameController(index) {
+		return
+	}
+	pad := sdl.GameControllerOpen(index)
+	if pad == nil {
+		log.Printf("Input: failed to open controller %d: %v", index, sdl.GetError())
+		return
+	}
+
+	id := pad.Joystick().InstanceID()
+	if _, ok := t.controllers[id]; ok {
+		pad.Close()
+		return
+	}
+	c := &controller{
+		pad:  pad,
+		guid: sdl.JoystickGetGUIDString(pad.Joystick().GUID()),
+		name: pad.Name(),
+	}
+	t.controllers[id] = c
+	t.devices = append(t.devices, DeviceEvent{Device: id, Name: c.name, Connected: true})
+	log.Printf("Input: controller %d connected: %s (%s)", id, c.name, c.guid)
+}
+
+// close fecha um controle removido, soltando o que estava pressionado nele
+func (t *Translator) close(id sdl.JoystickID, now uint64) {
+	c, ok := t.controllers[id]
+	if !ok {
+		return
+	}
+
+	// Cópia, porque release remove de order
+	for _, key := range slices.Clone(t.order) {
+		if key.device == id {
+			t.release(key, now)
+		}
+	}
+	for key := range t.axes {
+		if key.device == id {
+			delete(t.axes, key)
+		}
+	}
+
+	c.pad.Close()
+	delete(t.controllers, id)
+	t.devices = append(t.devices, DeviceEvent{Device: id, Name: c.name, Connected: false})
+	log.Printf("Input: controller %d disconnected: %s", id, c.name)
+}
+
+// Close fecha todos os controles abertos
+func (t *Translator) Close() {
+	for id, c := range t.controllers {
+		c.pad.Close()
+		delete(t.controllers, id)
+	}
+}
@@ -0,0 +1,61 @@
+package input
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+
+	"retroart-sdl2/internal/config"
+)
+
+func pressKey(t *Translator, key sdl.Scancode, pressed bool, now uint64) {
+	state := uint8(sdl.RELEASED)
+	if pressed {
+		state = sdl.PRESSED
+	}
+	t.Translate(&sdl.KeyboardEvent{Timestamp: uint32(now), State: state, Keysym: sdl.Keysym{Scancode: key}})
+}
+
+func repeatedTypes(events []InputEvent) []InputType {
+	var types []InputType
+	for _, event := range events {
+		if event.Phase == PhaseRepeat {
+			types = append(types, event.Type)
+		}
+	}
+	return types
+}
+
+func TestTickRepeatsInPressOrder(t *testing.T) {
+	translator := NewTranslator(config.Default().Input)
+	keys := []sdl.Scancode{
+		sdl.SCANCODE_RIGHT, sdl.SCANCODE_UP, sdl.SCANCODE_PAGEDOWN, sdl.SCANCODE_LEFT,
+		sdl.SCANCODE_LEFTBRACKET, sdl.SCANCODE_DOWN, sdl.SCANCODE_PAGEUP, sdl.SCANCODE_RIGHTBRACKET,
+	}
+	for _, key := range keys {
+		pressKey(translator, key, true, 0)
+	}
+	translator.Drain()
+
+	want := []InputType{InputRight, InputUp, InputR1, InputLeft, InputL2, InputDown, InputL1, InputR2}
+	// Com a ordem do map as repetições trocariam de lugar entre os frames
+	now := translator.repeat.delay
+	for range 10 {
+		translator.Tick(now)
+		if got := repeatedTypes(translator.Drain()); !slices.Equal(got, want) {
+			t.Fatalf("repeats at %d ms = %v, want %v", now, got, want)
+		}
+		now += translator.repeat.interval
+	}
+
+	// Soltar um input não muda a ordem dos demais; pressionado de novo, vai para o fim
+	pressKey(translator, sdl.SCANCODE_PAGEDOWN, false, now)
+	pressKey(translator, sdl.SCANCODE_PAGEDOWN, true, now)
+	translator.Drain()
+	translator.Tick(now + translator.repeat.delay)
+	want = []InputType{InputRight, InputUp, InputLeft, InputL2, InputDown, InputL1, InputR2, InputR1}
+	if got := repeatedTypes(translator.Drain()); !slices.Equal(got, want) {
+		t.Errorf("repeats after re-pressing R1 = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,907 @@
+// Package job runs scrape tasks in the background on a bounded worker pool
+// and reports progress through typed events, so the render loop never blocks.
+package job
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"retroart-sdl2/internal/download"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/match"
+	"retroart-sdl2/internal/scrapedb"
+	"retroart-sdl2/internal/scraper"
+)
+
+// ErrRunning is returned by Start while a previous run is still active
+var ErrRunning = errors.New("a scrape job is already running")
+
+// ErrLowConfidence means only name matches below the threshold were found
+var ErrLowConfidence = errors.New("no confident match")
+
+// errNoMedia means the match has nothing for a media type
+var errNoMedia = errors.New("no media available")
+
+// maxMixSourceSize bounds the media kept in memory while mixing
+const maxMixSourceSize = 32 << 20
+
+// notFoundRetry is how long a game no provider identified is not looked up
+// again
+const notFoundRetry = 7 * 24 * time.Hour
+
+// revalidateAfter is how long a recorded match, or a recorded media file
+// with validators, is trusted before asking the provider again
+const revalidateAfter = 30 * 24 * time.Hour
+
+// eventBufferSize is large enough to absorb a few frames worth of events
+const eventBufferSize = 256
+
+// Config configures an Engine
+type Config struct {
+	Workers        int                     // Number of concurrent tasks, defaults to 2
+	ProviderLimits map[string]int          // Concurrent requests per provider name, defaults to 1
+	Providers      []scraper.Provider      // Tried in order until one matches
+	Identifier     *scraper.Identifier     // Optional hashing/DAT identification
+	MediaTypes     []scraper.MediaType     // Media downloaded for every match
+	Regions        *scraper.RegionResolver // Region priority of media, ROM regions only when nil
+	Sink           Sink
+	Mixer          Mixer        // Builds scraper.MediaMixed, which is skipped when nil
+	MatchThreshold float64      // Minimum confidence of name matches, defaults to match.DefaultThreshold
+	Decisions      Decisions    // Manual matches, consulted before any lookup
+	DB             *scrapedb.DB // Matches and media of previous runs, optional
+	HTTPClient     *http.Client
+	Exporters      []Exporter
+	Downloader     *download.Downloader // Resumable media downloads; media are streamed when nil
+	QueuePath      string               // Persisted queue of the running job, disabled when empty
+}
+
+// Engine runs scrape jobs. A single engine is shared by the whole app and
+// runs at most one job at a time.
+type Engine struct {
+	config    Config
+	events    chan Event
+	limits    map[string]chan struct{}
+	mu        sync.Mutex
+	running   bool
+	paused    bool
+	resumeCh  chan struct{}
+	cancel    context.CancelFunc
+	startedAt time.Time
+
+	queue       *queue
+	interrupted []Task // Unfinished tasks of a job cut short by a restart
+}
+
+// NewEngine creates an engine. Events must be drained by the caller, which
+// the screen manager does once per frame.
+func NewEngine(config Config) *Engine {
+	if config.Workers <= 0 {
+		config.Workers = 2
+	}
+	if config.MatchThreshold <= 0 {
+		config.MatchThreshold = match.DefaultThreshold
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: time.Minute}
+	}
+
+	limits := make(map[string]chan struct{}, len(config.Providers))
+	for _, provider := range config.Providers {
+		limit := config.ProviderLimits[provider.Name()]
+		if limit <= 0 {
+			limit = 1
+		}
+		limits[provider.Name()] = make(chan struct{}, limit)
+	}
+
+	engine := &Engine{
+		config: config,
+		events: make(chan Event, eventBufferSize),
+		limits: limits,
+	}
+
+	if config.QueuePath != "" {
+		engine.queue = &queue{path: config.QueuePath}
+		interrupted, err := engine.queue.load()
+		if err != nil {
+			log.Printf("Job: %v", err)
+		}
+		if len(interrupted) > 0 {
+			log.Printf("Job: %d tasks left by an interrupted job", len(interrupted))
+		}
+		engine.interrupted = interrupted
+	}
+
+	return engine
+}
+
+// Events returns the channel on which progress events are published
+func (e *Engine) Events() <-chan Event {
+	return e.events
+}
+
+// Start queues every game of the given systems and starts the workers
+func (e *Engine) Start(systems []library.System) error {
+	var tasks []Task
+	for _, system := range systems {
+		for _, game := range system.Games {
+			tasks = append(tasks, Task{ID: len(tasks), Game: game, System: system.Name()})
+		}
+	}
+	return e.StartTasks(tasks)
+}
+
+// StartTasks starts a job over an explicit list of tasks. Tasks left by an
+// interrupted job stay in the persisted queue, so they can still be resumed
+// once this job ends.
+func (e *Engine) StartTasks(tasks []Task) error {
+	return e.start(tasks, false)
+}
+
+// Interrupted returns how many tasks an interrupted job left unfinished
+func (e *Engine) Interrupted() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.interrupted)
+}
+
+// ResumeInterrupted starts a job with the unfinished tasks of the job that
+// was running when RetroArt last stopped
+func (e *Engine) ResumeInterrupted() error {
+	e.mu.Lock()
+	tasks := e.interrupted
+	e.mu.Unlock()
+
+	if len(tasks) == 0 {
+		return nil
+	}
+	return e.start(tasks, true)
+}
+
+func (e *Engine) start(tasks []Task, resume bool) error {
+	e.mu.Lock()
+	if e.running {
+		e.mu.Unlock()
+		return ErrRunning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	e.running = true
+	e.paused = false
+	e.resumeCh = nil
+	e.cancel = cancel
+	e.startedAt = time.Now()
+	if resume {
+		e.interrupted = nil
+	} else {
+		// Carried tasks share the queue file, so their IDs must not clash
+		// with the new ones
+		next := 0
+		for _, task := range tasks {
+			next = max(next, task.ID+1)
+		}
+		carried := make([]Task, len(e.interrupted))
+		for i, task := range e.interrupted {
+			task.ID = next + i
+			carried[i] = task
+		}
+		e.interrupted = carried
+	}
+	carried := e.interrupted
+	e.mu.Unlock()
+
+	if e.queue != nil {
+		if err := e.queue.start(tasks, carried); err != nil {
+			log.Printf("Job: %v", err)
+		}
+	}
+
+	go e.run(ctx, tasks)
+	return nil
+}
+
+// DiscardInterrupted forgets the unfinished tasks of an interrupted job
+func (e *Engine) DiscardInterrupted() {
+	e.mu.Lock()
+	running := e.running
+	e.interrupted = nil
+	e.mu.Unlock()
+
+	if e.queue != nil && !running {
+		e.queue.clear()
+	}
+}
+
+// Pause stops workers from picking new tasks or stages until Resume
+func (e *Engine) Pause() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running && !e.paused {
+		e.paused = true
+		e.resumeCh = make(chan struct{})
+	}
+}
+
+// Resume continues a paused job
+func (e *Engine) Resume() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.paused {
+		e.paused = false
+		close(e.resumeCh)
+		e.resumeCh = nil
+	}
+}
+
+// Cancel stops the current job; an EventFinished is published once workers exit
+func (e *Engine) Cancel() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cancel != nil {
+		e.cancel()
+	}
+	if e.paused {
+		e.paused = false
+		close(e.resumeCh)
+		e.resumeCh = nil
+	}
+}
+
+// IsRunning reports whether a job is active
+func (e *Engine) IsRunning() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.running
+}
+
+// IsPaused reports whether the active job is paused
+func (e *Engine) IsPaused() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.paused
+}
+
+// StartedAt returns when the current or last job started
+func (e *Engine) StartedAt() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.startedAt
+}
+
+func (e *Engine) run(ctx context.Context, tasks []Task) {
+	queue := make(chan Task, len(tasks))
+	for _, task := range tasks {
+		queue <- task
+		e.emit(ctx, Event{Type: EventQueued, Task: task})
+	}
+	close(queue)
+
+	var wg sync.WaitGroup
+	for range e.config.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for task := range queue {
+				if err := e.waitIfPaused(ctx); err != nil {
+					return
+				}
+				e.process(ctx, task)
+				if e.queue != nil && ctx.Err() == nil {
+					e.queue.finish(task)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if id := e.config.Identifier; id != nil && id.Hasher != nil {
+		if err := id.Hasher.Save(); err != nil {
+			log.Printf("Job: %v", err)
+		}
+	}
+	for _, exporter := range e.config.Exporters {
+		if err := exporter.Flush(); err != nil {
+			log.Printf("Job: %v", err)
+		}
+	}
+	if e.queue != nil {
+		// Finished or cancelled on purpose: only the tasks carried over
+		// from an interrupted job are left to resume
+		e.mu.Lock()
+		carried := e.interrupted
+		e.mu.Unlock()
+		if len(carried) == 0 {
+			e.queue.clear()
+		} else if err := e.queue.keep(carried); err != nil {
+			log.Printf("Job: %v", err)
+		}
+	}
+	if db := e.config.DB; db != nil {
+		if db.NeedsCompaction() {
+			if err := db.Compact(); err != nil {
+				log.Printf("Job: %v", err)
+			}
+		}
+		if err := db.Sync(); err != nil {
+			log.Printf("Job: failed to sync scrape database: %v", err)
+		}
+	}
+
+	e.mu.Lock()
+	e.running = false
+	e.paused = false
+	e.cancel = nil
+	e.mu.Unlock()
+
+	// The finished event must not be lost, even after cancellation
+	e.events <- Event{Type: EventFinished, Err: ctx.Err(), Time: time.Now()}
+	log.Printf("Job: finished %d tasks (err=%v)", len(tasks), ctx.Err())
+}
+
+// process scrapes a single game: identify, look up and download media
+func (e *Engine) process(ctx context.Context, task Task) {
+	e.emit(ctx, Event{Type: EventStarted, Task: task})
+
+	var decided *scraper.Result
+	if e.config.Decisions != nil {
+		result, skip, ok := e.config.Decisions.Resolution(task.Game)
+		if ok && skip {
+			e.emit(ctx, Event{Type: EventSkipped, Task: task, Reason: "skipped by user"})
+			return
+		}
+		if ok {
+			decided = result
+		}
+	}
+
+	missing := e.missingMediaTypes(task.Game)
+	if len(missing) == 0 {
+		e.exportKnown(task.Game, decided)
+		e.emit(ctx, Event{Type: EventSkipped, Task: task, Reason: "all media already present"})
+		return
+	}
+
+	query := e.config.Identifier.Query(task.Game)
+	regions := scraper.GameRegions(task.Game, query.Region)
+	key := ""
+	var known scrapedb.Entry
+	if e.config.DB != nil {
+		key = scrapedb.Key(query)
+		known, _ = e.config.DB.Get(key)
+	}
+
+	result := decided
+	if result == nil && known.Match != nil && e.trusted(known) {
+		result = known.Match
+	}
+	if result == nil && time.Since(known.NotFound) < notFoundRetry {
+		e.emit(ctx, Event{Type: EventFailed, Task: task, Err: fmt.Errorf("%w (checked %s)", scraper.ErrNotFound, known.NotFound.Format(time.DateOnly))})
+		return
+	}
+
+	var candidates []scraper.Result
+	var err error
+	if result == nil {
+		result, candidates, err = e.lookup(ctx, query)
+		if err == nil {
+			result = withRegions(result, regions)
+			e.remember(key, task.Game, func(entry *scrapedb.Entry) {
+				entry.Match = result
+				entry.Matched = time.Now()
+				entry.NotFound = time.Time{}
+			})
+		} else if known.Match != nil && transient(err) && ctx.Err() == nil &&
+			e.hasProvider(known.Match.Provider) && known.Match.Confidence >= e.config.MatchThreshold {
+			// The provider could not be asked again; the old match is
+			// better than none
+			log.Printf("Job: keeping the recorded match of %s: %v", task.Game.Name, err)
+			result, err = known.Match, nil
+		} else if errors.Is(err, scraper.ErrNotFound) {
+			e.remember(key, task.Game, func(entry *scrapedb.Entry) {
+				entry.Match = nil
+				entry.NotFound = time.Now()
+			})
+		}
+	}
+	if errors.Is(err, ErrLowConfidence) {
+		e.emit(ctx, Event{
+			Type:       EventSkipped,
+			Task:       task,
+			Reason:     fmt.Sprintf("best match %q at %.0f%%", candidates[0].Title, candidates[0].Confidence*100),
+			Candidates: candidates,
+			Err:        err,
+		})
+		return
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		e.emit(ctx, Event{Type: EventFailed, Task: task, Err: err})
+		return
+	}
+	result = withRegions(result, regions)
+	e.emit(ctx, Event{Type: EventMatched, Task: task, Provider: result.Provider, Result: result})
+
+	downloaded, kept := 0, 0
+	for _, mediaType := range missing {
+		if err := e.waitIfPaused(ctx); err != nil {
+			return
+		}
+
+		var file scrapedb.MediaFile
+		var err error
+		if mediaType == scraper.MediaMixed {
+			file, err = e.mix(ctx, result, task.Game, regions)
+			if errors.Is(err, errNoMedia) {
+				continue
+			}
+		} else {
+			media, ok := e.config.Regions.PickMedia(result.MediaOfType(mediaType), regions)
+			if !ok {
+				continue
+			}
+			if written, ok := known.Media[mediaType]; ok && e.unchanged(ctx, result.Provider, key, task.Game, mediaType, written, media) {
+				kept++
+				continue
+			}
+			file, err = e.download(ctx, result.Provider, task.Game, media)
+		}
+		if errors.Is(err, ErrKept) {
+			kept++
+			if file.Path != "" {
+				e.remember(key, task.Game, func(entry *scrapedb.Entry) {
+					if entry.Media == nil {
+						entry.Media = make(map[scraper.MediaType]scrapedb.MediaFile)
+					}
+					entry.Media[mediaType] = file
+				})
+			}
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			e.emit(ctx, Event{Type: EventFailed, Task: task, Provider: result.Provider, Err: err})
+			return
+		}
+		downloaded++
+		e.remember(key, task.Game, func(entry *scrapedb.Entry) {
+			if entry.Media == nil {
+				entry.Media = make(map[scraper.MediaType]scrapedb.MediaFile)
+			}
+			entry.Media[mediaType] = file
+		})
+		e.emit(ctx, Event{Type: EventDownloaded, Task: task, Provider: result.Provider, MediaType: mediaType, Path: file.Path})
+	}
+
+	for _, exporter := range e.config.Exporters {
+		exporter.Export(task.Game, result)
+	}
+
+	if downloaded == 0 && kept > 0 {
+		e.emit(ctx, Event{Type: EventSkipped, Task: task, Provider: result.Provider, Reason: "existing media kept"})
+		return
+	}
+	if downloaded == 0 {
+		e.emit(ctx, Event{Type: EventSkipped, Task: task, Provider: result.Provider, Reason: "no requested media available"})
+		return
+	}
+	e.emit(ctx, Event{Type: EventCompleted, Task: task, Provider: result.Provider})
+}
+
+// exportKnown exports a game that needed no download with its decided or
+// previously stored match, so exporters enabled later still see it
+func (e *Engine) exportKnown(game library.Game, result *scraper.Result) {
+	if len(e.config.Exporters) == 0 {
+		return
+	}
+	if result == nil {
+		result = e.storedMatch(game)
+	}
+	if result == nil {
+		return
+	}
+	result = e.resolveRegions(game, result)
+	for _, exporter := range e.config.Exporters {
+		exporter.Export(game, result)
+	}
+}
+
+// Known returns the match of a game: the one picked by the user, or the
+// one recorded by a previous scrape. It hashes the ROM when needed, so it
+// should not run on the UI thread.
+func (e *Engine) Known(game library.Game) (*scraper.Result, bool) {
+	if e.config.Decisions != nil {
+		if result, skip, ok := e.config.Decisions.Resolution(game); ok && !skip && result != nil {
+			return e.resolveRegions(game, result), true
+		}
+	}
+	result := e.storedMatch(game)
+	if result == nil {
+		return nil, false
+	}
+	return e.resolveRegions(game, result), true
+}
+
+// resolveRegions returns the result with the regions of the game, for
+// decisions and matches recorded before the regions were stored
+func (e *Engine) resolveRegions(game library.Game, result *scraper.Result) *scraper.Result {
+	if len(result.Regions) > 0 {
+		return result
+	}
+	query := e.config.Identifier.Query(game)
+	return withRegions(result, scraper.GameRegions(game, query.Region))
+}
+
+// withRegions returns a copy of the result carrying the regions of the ROM.
+// Results are shared with the scrape database and the review store, so they
+// are never changed in place.
+func withRegions(result *scraper.Result, regions []string) *scraper.Result {
+	if slices.Equal(result.Regions, regions) {
+		return result
+	}
+	copied := *result
+	copied.Regions = regions
+	return &copied
+}
+
+// storedMatch returns the match recorded in the scrape database
+func (e *Engine) storedMatch(game library.Game) *scraper.Result {
+	if e.config.DB == nil {
+		return nil
+	}
+	if key := scrapedb.Key(e.config.Identifier.Query(game)); key != "" {
+		if entry, ok := e.config.DB.Get(key); ok {
+			return entry.Match
+		}
+	}
+	return nil
+}
+
+// remember updates the scrape database entry of a game. Games that could
+// not be hashed have no key and are not remembered.
+func (e *Engine) remember(key string, game library.Game, fn func(entry *scrapedb.Entry)) {
+	if e.config.DB == nil || key == "" {
+		return
+	}
+	err := e.config.DB.Update(key, func(entry *scrapedb.Entry) {
+		entry.SystemID = game.SystemID
+		entry.FileName = game.FileName
+		fn(entry)
+	})
+	if err != nil {
+		log.Printf("Job: %v", err)
+	}
+}
+
+// trusted reports whether a recorded match can be reused without a new
+// lookup: it comes from a configured provider, still meets the threshold
+// and is recent enough
+func (e *Engine) trusted(known scrapedb.Entry) bool {
+	return e.hasProvider(known.Match.Provider) &&
+		known.Match.Confidence >= e.config.MatchThreshold &&
+		time.Since(known.MatchedAt()) < revalidateAfter
+}
+
+func (e *Engine) hasProvider(name string) bool {
+	_, ok := e.limits[name]
+	return ok
+}
+
+// transient reports whether a lookup failed for reasons unrelated to the
+// game, such as the network or the provider quota
+func transient(err error) bool {
+	return !errors.Is(err, scraper.ErrNotFound) && !errors.Is(err, ErrLowConfidence) &&
+		!errors.Is(err, scraper.ErrUnsupportedSystem)
+}
+
+// unchanged reports whether a previously written media file came from the
+// same source and is still on disk untouched. Once the record is old, its
+// ETag or Last-Modified are compared with the server's, so media replaced
+// by the provider under the same URL are downloaded again.
+func (e *Engine) unchanged(ctx context.Context, provider, key string, game library.Game, mediaType scraper.MediaType, written scrapedb.MediaFile, media scraper.Media) bool {
+	// Records of older versions kept the credentials in the URL
+	if written.URL == "" || scraper.RedactURL(written.URL) != scraper.RedactURL(media.URL) {
+		return false
+	}
+	info, err := os.Stat(written.Path)
+	if err != nil || info.Size() != written.Size {
+		return false
+	}
+	if (written.ETag == "" && written.LastModified == "") || time.Since(written.Time) < revalidateAfter {
+		return true
+	}
+
+	if err := e.acquire(ctx, provider); err != nil {
+		return true
+	}
+	etag, lastModified, err := scraper.Validators(ctx, e.config.HTTPClient, e.authorize(media))
+	e.release(provider)
+	if err != nil {
+		// Offline: keep the file rather than fail the game
+		log.Printf("Job: failed to revalidate %s of %s: %v", mediaType, game.Name, err)
+		return true
+	}
+
+	switch {
+	case written.ETag != "" && etag != "":
+		if etag != written.ETag {
+			return false
+		}
+	case written.LastModified != "" && lastModified != "":
+		if lastModified != written.LastModified {
+			return false
+		}
+	}
+
+	e.remember(key, game, func(entry *scrapedb.Entry) {
+		if file, ok := entry.Media[mediaType]; ok {
+			file.Time = time.Now()
+			entry.Media[mediaType] = file
+		}
+	})
+	return true
+}
+
+// missingMediaTypes returns the configured media types the game still lacks
+func (e *Engine) missingMediaTypes(game library.Game) []scraper.MediaType {
+	var missing []scraper.MediaType
+	for _, mediaType := range e.config.MediaTypes {
+		if e.config.Sink == nil || !e.config.Sink.Exists(game, mediaType) {
+			missing = append(missing, mediaType)
+		}
+	}
+	return missing
+}
+
+// lookup tries every provider in order, honouring per-provider limits. The
+// first match above the threshold wins; otherwise the ranked candidates of
+// all providers are returned with ErrLowConfidence.
+func (e *Engine) lookup(ctx context.Context, query scraper.Query) (*scraper.Result, []scraper.Result, error) {
+	lastErr := scraper.ErrNotFound
+	var candidates []scraper.Result
+	for _, provider := range e.config.Providers {
+		if err := e.acquire(ctx, provider.Name()); err != nil {
+			return nil, nil, err
+		}
+		results, err := scraper.Lookup(ctx, provider, query)
+		e.release(provider.Name())
+
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if results[0].Confidence >= e.config.MatchThreshold {
+			return &results[0], nil, nil
+		}
+		candidates = append(candidates, results...)
+	}
+
+	if len(candidates) > 0 {
+		sort.SliceStable(candidates, func(i, j int) bool {
+			return candidates[i].Confidence > candidates[j].Confidence
+		})
+		return nil, candidates, ErrLowConfidence
+	}
+	return nil, nil, lastErr
+}
+
+func (e *Engine) download(ctx context.Context, provider string, game library.Game, media scraper.Media) (scrapedb.MediaFile, error) {
+	if e.config.Sink == nil {
+		return scrapedb.MediaFile{}, errors.New("no media sink configured")
+	}
+
+	if err := e.acquire(ctx, provider); err != nil {
+		return scrapedb.MediaFile{}, err
+	}
+	defer e.release(provider)
+
+	if e.config.Downloader == nil {
+		content, err := scraper.Fetch(ctx, e.config.HTTPClient, e.authorize(media))
+		if err != nil {
+			return scrapedb.MediaFile{}, fmt.Errorf("failed to download %s: %w", media.Type, err)
+		}
+		defer content.Close()
+		return e.write(ctx, game, media, content, content.ETag, content.LastModified)
+	}
+
+	downloaded, err := e.config.Downloader.Fetch(ctx, download.Request{
+		URL:  e.authorize(media).URL,
+		Size: media.Size,
+		MD5:  media.MD5,
+		SHA1: media.SHA1,
+	})
+	if errors.Is(err, download.ErrNotFound) {
+		err = scraper.ErrNotFound
+	}
+	if err != nil {
+		return scrapedb.MediaFile{}, fmt.Errorf("failed to download %s: %w", media.Type, err)
+	}
+	defer downloaded.Remove()
+
+	content, err := os.Open(downloaded.Path)
+	if err != nil {
+		return scrapedb.MediaFile{}, fmt.Errorf("failed to open download: %w", err)
+	}
+	defer content.Close()
+	return e.write(ctx, game, media, content, downloaded.ETag, downloaded.LastModified)
+}
+
+// write stores downloaded media through the sink
+func (e *Engine) write(ctx context.Context, game library.Game, media scraper.Media, content io.Reader, etag, lastModified string) (scrapedb.MediaFile, error) {
+	path, err := e.config.Sink.Write(ctx, game, media, content)
+	if err != nil && !(errors.Is(err, ErrKept) && path != "") {
+		return scrapedb.MediaFile{}, err
+	}
+	// A kept file is recorded with the source it was compared against, so
+	// the next run finds it unchanged instead of downloading it again
+	file := writtenFile(path)
+	file.URL = scraper.RedactURL(media.URL)
+	file.ETag = etag
+	file.LastModified = lastModified
+	return file, err
+}
+
+// writtenFile describes a file written by the sink
+func writtenFile(path string) scrapedb.MediaFile {
+	file := scrapedb.MediaFile{Path: path, Time: time.Now()}
+	if info, err := os.Stat(path); err == nil {
+		file.Size = info.Size()
+	}
+	return file
+}
+
+// Search looks a name up on every provider, for manual matching. Results
+// are ranked by title similarity, best first.
+func (e *Engine) Search(ctx context.Context, name, systemID string) ([]scraper.Result, error) {
+	var results []scraper.Result
+	lastErr := scraper.ErrNotFound
+	for _, provider := range e.config.Providers {
+		found, err := scraper.LookupName(ctx, provider, name, systemID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		results = append(results, found...)
+	}
+	if len(results) == 0 {
+		return nil, lastErr
+	}
+	return scraper.RankResults(name, results), nil
+}
+
+// Fetch opens a media with the engine HTTP client, e.g. for thumbnails
+func (e *Engine) Fetch(ctx context.Context, media scraper.Media) (*scraper.Content, error) {
+	return scraper.Fetch(ctx, e.config.HTTPClient, e.authorize(media))
+}
+
+// authorize adds the provider credentials to the URL of a media right
+// before it is requested; results and records only keep redacted URLs
+func (e *Engine) authorize(media scraper.Media) scraper.Media {
+	for _, provider := range e.config.Providers {
+		if authorizer, ok := provider.(scraper.Authorizer); ok {
+			media.URL = authorizer.AuthorizeURL(media.URL)
+		}
+	}
+	return media
+}
+
+// mix downloads the sources of the mixer and writes the composed image.
+// Sources that fail to download are left out; the mixer decides whether
+// the remaining ones are enough.
+func (e *Engine) mix(ctx context.Context, result *scraper.Result, game library.Game, regions []string) (scrapedb.MediaFile, error) {
+	if e.config.Mixer == nil || e.config.Sink == nil {
+		return scrapedb.MediaFile{}, errNoMedia
+	}
+
+	sources := make(map[scraper.MediaType][]byte)
+	for _, mediaType := range e.config.Mixer.Sources() {
+		media, ok := e.config.Regions.PickMedia(result.MediaOfType(mediaType), regions)
+		if !ok {
+			continue
+		}
+		data, err := e.fetch(ctx, result.Provider, media)
+		if err != nil {
+			if ctx.Err() != nil {
+				return scrapedb.MediaFile{}, ctx.Err()
+			}
+			log.Printf("Job: mix source %s of %s: %v", mediaType, game.Name, err)
+			continue
+		}
+		sources[mediaType] = data
+	}
+	if len(sources) == 0 {
+		return scrapedb.MediaFile{}, errNoMedia
+	}
+
+	// Missing layers are expected for obscure games, so a failed mix is
+	// not a failed task
+	mixed, err := e.config.Mixer.Mix(result, sources)
+	if err != nil {
+		log.Printf("Job: mix of %s: %v", game.Name, err)
+		return scrapedb.MediaFile{}, errNoMedia
+	}
+
+	media := scraper.Media{Type: scraper.MediaMixed, Format: "png", Size: int64(len(mixed))}
+	path, err := e.config.Sink.Write(ctx, game, media, bytes.NewReader(mixed))
+	if err != nil {
+		return scrapedb.MediaFile{}, err
+	}
+	return writtenFile(path), nil
+}
+
+// fetch downloads a media into memory
+func (e *Engine) fetch(ctx context.Context, provider string, media scraper.Media) ([]byte, error) {
+	if err := e.acquire(ctx, provider); err != nil {
+		return nil, err
+	}
+	defer e.release(provider)
+
+	content, err := scraper.Fetch(ctx, e.config.HTTPClient, e.authorize(media))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", media.Type, err)
+	}
+	defer content.Close()
+
+	return io.ReadAll(io.LimitReader(content, maxMixSourceSize))
+}
+
+func (e *Engine) acquire(ctx context.Context, provider string) error {
+	limit, ok := e.limits[provider]
+	if !ok {
+		return nil
+	}
+	select {
+	case limit <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (e *Engine) release(provider string) {
+	if limit, ok := e.limits[provider]; ok {
+		<-limit
+	}
+}
+
+// waitIfPaused blocks while the job is paused
+func (e *Engine) waitIfPaused(ctx context.Context) error {
+	e.mu.Lock()
+	resume := e.resumeCh
+	paused := e.paused
+	e.mu.Unlock()
+
+	if paused {
+		select {
+		case <-resume:
+		case <-ctx.Done():
+		}
+	}
+	return ctx.Err()
+}
+
+// emit publishes an event, giving up when the job is cancelled so workers
+// never block on a channel nobody drains anymore
+func (e *Engine) emit(ctx context.Context, event Event) {
+	event.Time = time.Now()
+	select {
+	case e.events <- event:
+	case <-ctx.Done():
+	}
+}
@@ -0,0 +1,61 @@
+package job
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+type fixedDecisions struct {
+	result *scraper.Result
+}
+
+func (d fixedDecisions) Resolution(library.Game) (*scraper.Result, bool, bool) {
+	return d.result, false, true
+}
+
+func TestKnownCarriesGameRegions(t *testing.T) {
+	decided := &scraper.Result{Provider: "screenscraper", ID: "1", Title: "Game"}
+	engine := NewEngine(Config{Decisions: fixedDecisions{decided}})
+
+	game := library.Game{Name: "Game (Europe)", FileName: "Game (Europe).sfc"}
+	result, ok := engine.Known(game)
+	if !ok {
+		t.Fatal("Known found no match")
+	}
+	if want := scraper.GameRegions(game); !slices.Equal(result.Regions, want) {
+		t.Errorf("Regions = %v, want %v", result.Regions, want)
+	}
+	// The decision is shared with the review store and must stay untouched
+	if decided.Regions != nil {
+		t.Errorf("decision changed in place: %v", decided.Regions)
+	}
+}
+
+func TestFetchAddsProviderCredentials(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		io.WriteString(w, "image")
+	}))
+	defer server.Close()
+
+	provider := scraper.NewScreenScraper(scraper.ScreenScraperConfig{BaseURL: server.URL, DevID: "dev", DevPassword: "devsecret"})
+	engine := NewEngine(Config{Providers: []scraper.Provider{provider}})
+
+	// Results only carry the redacted URL
+	content, err := engine.Fetch(context.Background(), scraper.Media{URL: server.URL + "/mediaJeu.php?media=box-2D"})
+	if err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	content.Close()
+	if want := "devid=dev&devpassword=devsecret&media=box-2D"; query != want {
+		t.Errorf("media request query = %q, want %q", query, want)
+	}
+}
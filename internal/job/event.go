@@ -0,0 +1,69 @@
+package job
+
+import (
+	"time"
+
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+// EventType identifies a progress event published by the engine
+type EventType int
+
+const (
+	EventQueued     EventType = iota // Task added to the queue
+	EventStarted                     // Worker picked the task
+	EventMatched                     // A provider identified the game
+	EventDownloaded                  // A media file was written
+	EventFailed                      // Task failed, Err holds the reason
+	EventSkipped                     // Nothing to do for the task
+	EventCompleted                   // Task finished with at least one download
+	EventFinished                    // Run ended; Err is set when it was cancelled
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventQueued:
+		return "queued"
+	case EventStarted:
+		return "started"
+	case EventMatched:
+		return "matched"
+	case EventDownloaded:
+		return "downloaded"
+	case EventFailed:
+		return "failed"
+	case EventSkipped:
+		return "skipped"
+	case EventCompleted:
+		return "completed"
+	case EventFinished:
+		return "finished"
+	default:
+		return "unknown"
+	}
+}
+
+// Task is the unit of work of the engine: one game to scrape
+type Task struct {
+	ID     int
+	Game   library.Game
+	System string // Display name of the system the game belongs to
+}
+
+// Event is published on the engine channel for every state change
+type Event struct {
+	Type      EventType
+	Task      Task
+	Provider  string
+	Result    *scraper.Result   // Set on EventMatched
+	MediaType scraper.MediaType // Set on EventDownloaded
+	Path      string            // Written file, set on EventDownloaded
+	Reason    string            // Human readable reason for EventSkipped
+	Err       error
+	Time      time.Time
+
+	// Candidates holds the ranked name matches of an EventSkipped caused
+	// by ErrLowConfidence
+	Candidates []scraper.Result
+}
@@ -0,0 +1,148 @@
+package job
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"retroart-sdl2/internal/fsutil"
+)
+
+// queueVersion is bumped when the queue file format changes
+const queueVersion = 1
+
+// queueData is the task list of a job, written when the job starts
+type queueData struct {
+	Version int       `json:"version"`
+	Started time.Time `json:"started"`
+	Tasks   []Task    `json:"tasks"`
+}
+
+// queue persists the tasks of the running job, so a session interrupted
+// by a crash or a power cut can be resumed after a restart. The task list
+// is written once; finished task IDs are appended to a second file, one per
+// line, which costs a single small write per task.
+type queue struct {
+	path string
+	mu   sync.Mutex
+	done *os.File
+}
+
+func (q *queue) donePath() string {
+	return q.path + ".done"
+}
+
+// load returns the unfinished tasks of an interrupted job
+func (q *queue) load() ([]Task, error) {
+	data, err := os.ReadFile(q.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read job queue: %w", err)
+	}
+
+	var saved queueData
+	if err := json.Unmarshal(data, &saved); err != nil || saved.Version != queueVersion {
+		return nil, fmt.Errorf("ignoring job queue %s: unsupported or corrupt file", q.path)
+	}
+
+	finished := make(map[int]bool)
+	if file, err := os.Open(q.donePath()); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			// A torn last line simply does not parse
+			if id, err := strconv.Atoi(strings.TrimSpace(scanner.Text())); err == nil {
+				finished[id] = true
+			}
+		}
+		file.Close()
+	}
+
+	var pending []Task
+	for _, task := range saved.Tasks {
+		if !finished[task.ID] {
+			pending = append(pending, task)
+		}
+	}
+	return pending, nil
+}
+
+// start records the tasks of a new job, replacing any previous queue.
+// carried are the unfinished tasks of an interrupted job, kept in the queue
+// but not run.
+func (q *queue) start(tasks, carried []Task) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closeDone()
+	all := append(append(make([]Task, 0, len(tasks)+len(carried)), tasks...), carried...)
+	data, err := json.Marshal(queueData{Version: queueVersion, Started: time.Now(), Tasks: all})
+	if err != nil {
+		return fmt.Errorf("failed to encode job queue: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(q.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save job queue: %w", err)
+	}
+
+	done, err := os.OpenFile(q.donePath(), os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create job queue progress: %w", err)
+	}
+	q.done = done
+	return nil
+}
+
+// finish marks a task as done. The line is synced, so a power cut right
+// after a task never runs it again.
+func (q *queue) finish(task Task) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.done == nil {
+		return
+	}
+	if _, err := q.done.WriteString(strconv.Itoa(task.ID) + "\n"); err != nil {
+		log.Printf("Job: failed to record finished task: %v", err)
+		return
+	}
+	if err := q.done.Sync(); err != nil {
+		log.Printf("Job: failed to sync finished task: %v", err)
+	}
+}
+
+// keep rewrites the queue with only the tasks carried over by the job that
+// just ended
+func (q *queue) keep(carried []Task) error {
+	if err := q.start(nil, carried); err != nil {
+		return err
+	}
+	q.mu.Lock()
+	q.closeDone()
+	q.mu.Unlock()
+	return nil
+}
+
+// clear removes the queue once the job ended on its own or was cancelled
+func (q *queue) clear() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closeDone()
+	os.Remove(q.path)
+	os.Remove(q.donePath())
+}
+
+func (q *queue) closeDone() {
+	if q.done != nil {
+		q.done.Close()
+		q.done = nil
+	}
+}
@@ -0,0 +1,168 @@
+package job
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"retroart-sdl2/internal/library"
+)
+
+func queueTasks(names ...string) []Task {
+	tasks := make([]Task, len(names))
+	for i, name := range names {
+		tasks[i] = Task{ID: i, Game: library.Game{Name: name, SystemID: "snes"}, System: "SNES"}
+	}
+	return tasks
+}
+
+func taskNames(tasks []Task) []string {
+	names := make([]string, len(tasks))
+	for i, task := range tasks {
+		names[i] = task.Game.Name
+	}
+	return names
+}
+
+func sameNames(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := make(map[string]int)
+	for _, name := range got {
+		seen[name]++
+	}
+	for _, name := range want {
+		seen[name]--
+	}
+	for _, count := range seen {
+		if count != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// waitFinished drains events until the running job ends
+func waitFinished(t *testing.T, engine *Engine) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case event := <-engine.Events():
+			if event.Type == EventFinished {
+				return
+			}
+		case <-timeout:
+			t.Fatal("job did not finish")
+		}
+	}
+}
+
+func TestQueueLoadReturnsUnfinishedTasks(t *testing.T) {
+	q := &queue{path: filepath.Join(t.TempDir(), "queue.json")}
+	tasks := queueTasks("a", "b", "c")
+	if err := q.start(tasks, nil); err != nil {
+		t.Fatal(err)
+	}
+	q.finish(tasks[1])
+	q.closeDone()
+
+	pending, err := q.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskNames(pending); !sameNames(got, []string{"a", "c"}) {
+		t.Errorf("pending = %v, want [a c]", got)
+	}
+
+	q.clear()
+	if pending, _ := q.load(); len(pending) != 0 {
+		t.Errorf("pending after clear = %v", taskNames(pending))
+	}
+}
+
+func TestQueueIgnoresTornDoneLine(t *testing.T) {
+	q := &queue{path: filepath.Join(t.TempDir(), "queue.json")}
+	if err := q.start(queueTasks("a", "b"), nil); err != nil {
+		t.Fatal(err)
+	}
+	q.closeDone()
+	if err := os.WriteFile(q.donePath(), []byte("0\n1x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := q.load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskNames(pending); !sameNames(got, []string{"b"}) {
+		t.Errorf("pending = %v, want [b]", got)
+	}
+}
+
+func TestEngineResumesInterruptedQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	// A job cut short after its first task
+	q := &queue{path: path}
+	tasks := queueTasks("a", "b", "c")
+	if err := q.start(tasks, nil); err != nil {
+		t.Fatal(err)
+	}
+	q.finish(tasks[0])
+	q.closeDone()
+
+	engine := NewEngine(Config{QueuePath: path})
+	if got := engine.Interrupted(); got != 2 {
+		t.Fatalf("Interrupted() = %d, want 2", got)
+	}
+
+	if err := engine.ResumeInterrupted(); err != nil {
+		t.Fatal(err)
+	}
+	waitFinished(t, engine)
+
+	if got := engine.Interrupted(); got != 0 {
+		t.Errorf("Interrupted() after resume = %d, want 0", got)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("queue file still present after resume: %v", err)
+	}
+}
+
+func TestEngineStartKeepsInterruptedQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+
+	q := &queue{path: path}
+	tasks := queueTasks("a", "b", "c")
+	if err := q.start(tasks, nil); err != nil {
+		t.Fatal(err)
+	}
+	q.finish(tasks[0])
+	q.closeDone()
+
+	engine := NewEngine(Config{QueuePath: path})
+	if err := engine.StartTasks(queueTasks("x", "y")); err != nil {
+		t.Fatal(err)
+	}
+	waitFinished(t, engine)
+
+	if got := engine.Interrupted(); got != 2 {
+		t.Errorf("Interrupted() = %d, want 2", got)
+	}
+	// Carried tasks share the queue file with the new job: an ID clash
+	// would mark a carried task as done when a new one finished
+	for _, task := range engine.interrupted {
+		if task.ID < 2 {
+			t.Errorf("carried task %s kept ID %d, clashing with the new job", task.Game.Name, task.ID)
+		}
+	}
+
+	// A restart still finds the interrupted tasks, without the new job
+	restarted := NewEngine(Config{QueuePath: path})
+	if got := taskNames(restarted.interrupted); !sameNames(got, []string{"b", "c"}) {
+		t.Errorf("interrupted after restart = %v, want [b c]", got)
+	}
+}
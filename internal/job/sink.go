@@ -0,0 +1,52 @@
+package job
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+// ErrKept is returned by Sink.Write when the existing file was kept
+var ErrKept = errors.New("existing media kept")
+
+// Sink stores downloaded media for a game
+type Sink interface {
+	// Exists reports whether the game already has media of the given type
+	// that should not be downloaded again
+	Exists(game library.Game, mediaType scraper.MediaType) bool
+
+	// Write stores the media content and returns the written path
+	Write(ctx context.Context, game library.Game, media scraper.Media, content io.Reader) (string, error)
+}
+
+// Mixer composes scraper.MediaMixed from other media of a match
+type Mixer interface {
+	// Sources returns the media types used by the mix, in priority order
+	Sources() []scraper.MediaType
+
+	// Mix returns the encoded image composed from the downloaded sources
+	Mix(result *scraper.Result, sources map[scraper.MediaType][]byte) ([]byte, error)
+}
+
+// Decisions holds the matches chosen by the user for games that did not
+// match automatically
+type Decisions interface {
+	// Resolution returns the chosen result, or skip when the game must
+	// never be scraped. ok is false when no decision was taken.
+	Resolution(game library.Game) (result *scraper.Result, skip bool, ok bool)
+}
+
+// Exporter writes metadata of matched games next to the media, e.g. the
+// game lists of a frontend
+type Exporter interface {
+	// Export records a matched game. It is called once the media of the
+	// game were written, or with the known match when nothing was missing.
+	Export(game library.Game, result *scraper.Result)
+
+	// Flush writes everything recorded since the last flush; the engine
+	// calls it when a job ends
+	Flush() error
+}
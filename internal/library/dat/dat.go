@@ -0,0 +1,229 @@
+// Package dat imports Logiqx XML DAT files (No-Intro, Redump and the MAME
+// listxml subset) into an index used to identify ROMs by checksum offline.
+package dat
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"retroart-sdl2/internal/library/hash"
+)
+
+// Entry is a single ROM described by a DAT file
+type Entry struct {
+	Name   string // Canonical title, e.g. "Super Mario World (USA)"
+	Region string // First region tag of the title, e.g. "USA"
+	Serial string
+	System string // DAT header name, e.g. "Nintendo - Super Nintendo Entertainment System"
+	ROM    string // ROM file name inside the DAT
+	Size   int64
+	CRC32  string
+	MD5    string
+	SHA1   string
+}
+
+// knownRegions lists the region names used in No-Intro/Redump titles
+var knownRegions = map[string]bool{
+	"World": true, "USA": true, "Europe": true, "Japan": true, "Asia": true,
+	"Australia": true, "Brazil": true, "Canada": true, "China": true,
+	"France": true, "Germany": true, "Hong Kong": true, "Italy": true,
+	"Korea": true, "Netherlands": true, "Russia": true, "Spain": true,
+	"Sweden": true, "Taiwan": true, "United Kingdom": true,
+}
+
+var tagPattern = regexp.MustCompile(`\(([^()]+)\)`)
+
+// ParseRegion returns the first region of a No-Intro style title. For
+// "Game (USA, Europe)" it returns "USA"; an empty string means no region tag.
+func ParseRegion(title string) string {
+	for _, match := range tagPattern.FindAllStringSubmatch(title, -1) {
+		for _, part := range strings.Split(match[1], ",") {
+			part = strings.TrimSpace(part)
+			if knownRegions[part] {
+				return part
+			}
+		}
+	}
+	return ""
+}
+
+// xmlGame matches both <game> (Logiqx) and <machine> (MAME listxml) elements
+type xmlGame struct {
+	Name        string   `xml:"name,attr"`
+	Serial      string   `xml:"serial"`
+	Description string   `xml:"description"`
+	ROMs        []xmlROM `xml:"rom"`
+}
+
+type xmlROM struct {
+	Name   string `xml:"name,attr"`
+	Size   int64  `xml:"size,attr"`
+	CRC    string `xml:"crc,attr"`
+	MD5    string `xml:"md5,attr"`
+	SHA1   string `xml:"sha1,attr"`
+	Serial string `xml:"serial,attr"`
+}
+
+// ParseFile reads the entries of a DAT file on disk
+func ParseFile(path string) ([]Entry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dat %s: %w", path, err)
+	}
+	defer file.Close()
+
+	entries, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse dat %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// Parse streams a DAT document and returns one entry per ROM. Games are
+// decoded one at a time so large MAME listings do not need to fit in memory
+// as a tree.
+func Parse(r io.Reader) ([]Entry, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+
+	var entries []Entry
+	var system string
+	inHeader := false
+
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch element := token.(type) {
+		case xml.StartElement:
+			switch element.Name.Local {
+			case "header":
+				inHeader = true
+			case "name":
+				if inHeader {
+					var name string
+					if err := decoder.DecodeElement(&name, &element); err != nil {
+						return nil, err
+					}
+					system = strings.TrimSpace(name)
+				}
+			case "game", "machine":
+				var game xmlGame
+				if err := decoder.DecodeElement(&game, &element); err != nil {
+					return nil, err
+				}
+				entries = append(entries, gameEntries(game, system)...)
+			}
+		case xml.EndElement:
+			if element.Name.Local == "header" {
+				inHeader = false
+			}
+		}
+	}
+}
+
+func gameEntries(game xmlGame, system string) []Entry {
+	title := game.Name
+	// MAME short names ("sf2") are not titles; use the description instead
+	if game.Description != "" && !strings.ContainsAny(game.Name, " ()") {
+		title = game.Description
+	}
+
+	entries := make([]Entry, 0, len(game.ROMs))
+	for _, rom := range game.ROMs {
+		if rom.CRC == "" && rom.SHA1 == "" && rom.MD5 == "" {
+			continue
+		}
+
+		serial := rom.Serial
+		if serial == "" {
+			serial = game.Serial
+		}
+
+		entries = append(entries, Entry{
+			Name:   title,
+			Region: ParseRegion(title),
+			Serial: serial,
+			System: system,
+			ROM:    rom.Name,
+			Size:   rom.Size,
+			CRC32:  strings.ToLower(rom.CRC),
+			MD5:    strings.ToLower(rom.MD5),
+			SHA1:   strings.ToLower(rom.SHA1),
+		})
+	}
+	return entries
+}
+
+// Index maps checksums to DAT entries
+type Index struct {
+	Entries []Entry
+	bySHA1  map[string]int
+	byCRC   map[string][]int
+}
+
+// NewIndex builds an index over the given entries
+func NewIndex(entries []Entry) *Index {
+	idx := &Index{Entries: entries}
+	idx.build()
+	return idx
+}
+
+func (idx *Index) build() {
+	idx.bySHA1 = make(map[string]int, len(idx.Entries))
+	idx.byCRC = make(map[string][]int, len(idx.Entries))
+	for i, entry := range idx.Entries {
+		if entry.SHA1 != "" {
+			if _, exists := idx.bySHA1[entry.SHA1]; !exists {
+				idx.bySHA1[entry.SHA1] = i
+			}
+		}
+		if entry.CRC32 != "" {
+			idx.byCRC[entry.CRC32] = append(idx.byCRC[entry.CRC32], i)
+		}
+	}
+}
+
+// Len returns the number of ROMs in the index
+func (idx *Index) Len() int {
+	if idx == nil {
+		return 0
+	}
+	return len(idx.Entries)
+}
+
+// Lookup finds the entry matching the hashes, preferring SHA1 and falling
+// back to CRC32 plus size. A nil index never matches.
+func (idx *Index) Lookup(hashes hash.Hashes) (Entry, bool) {
+	if idx == nil {
+		return Entry{}, false
+	}
+	if i, ok := idx.bySHA1[strings.ToLower(hashes.SHA1)]; ok && hashes.SHA1 != "" {
+		return idx.Entries[i], true
+	}
+	return idx.LookupCRC(hashes.CRC32, hashes.Size)
+}
+
+// LookupCRC finds an entry by CRC32. When size is positive it must match too,
+// which resolves most CRC collisions between different systems.
+func (idx *Index) LookupCRC(crc string, size int64) (Entry, bool) {
+	if idx == nil || crc == "" {
+		return Entry{}, false
+	}
+	for _, i := range idx.byCRC[strings.ToLower(crc)] {
+		entry := idx.Entries[i]
+		if size <= 0 || entry.Size <= 0 || entry.Size == size {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
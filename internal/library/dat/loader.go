@@ -0,0 +1,127 @@
+package dat
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"retroart-sdl2/internal/fsutil"
+)
+
+// cacheVersion must be bumped whenever Entry or the cache layout changes
+const cacheVersion = 1
+
+// indexCache is the compact pre-parsed form of a DAT directory stored on disk
+type indexCache struct {
+	Version     int
+	Fingerprint string
+	Entries     []Entry
+}
+
+// LoadDir loads every .dat/.xml file in dir into a single index. The parsed
+// result is cached in cachePath and reused while the DAT files are unchanged.
+// A missing dir returns an empty index, since DATs are optional.
+func LoadDir(dir, cachePath string) (*Index, error) {
+	files, fingerprint, err := datFiles(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return NewIndex(nil), nil
+		}
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return NewIndex(nil), nil
+	}
+
+	if cachePath != "" {
+		if entries, ok := readCache(cachePath, fingerprint); ok {
+			log.Printf("DAT: loaded %d entries from cache %s", len(entries), cachePath)
+			return NewIndex(entries), nil
+		}
+	}
+
+	var entries []Entry
+	for _, file := range files {
+		parsed, err := ParseFile(file)
+		if err != nil {
+			log.Printf("DAT: skipping %s: %v", file, err)
+			continue
+		}
+		entries = append(entries, parsed...)
+		log.Printf("DAT: imported %d entries from %s", len(parsed), filepath.Base(file))
+	}
+
+	if cachePath != "" {
+		if err := writeCache(cachePath, fingerprint, entries); err != nil {
+			log.Printf("DAT: failed to write cache: %v", err)
+		}
+	}
+
+	return NewIndex(entries), nil
+}
+
+// datFiles lists the DAT files of dir and a fingerprint of their names,
+// sizes and modification times
+func datFiles(dir string) ([]string, string, error) {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var files []string
+	var fingerprint strings.Builder
+	for _, entry := range dirEntries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if entry.IsDir() || (ext != ".dat" && ext != ".xml") {
+			continue
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to stat %s: %w", entry.Name(), err)
+		}
+
+		files = append(files, filepath.Join(dir, entry.Name()))
+		fmt.Fprintf(&fingerprint, "%s:%d:%d;", entry.Name(), info.Size(), info.ModTime().UnixNano())
+	}
+
+	sort.Strings(files)
+	return files, fingerprint.String(), nil
+}
+
+func readCache(path, fingerprint string) ([]Entry, bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	var cache indexCache
+	if err := gob.NewDecoder(file).Decode(&cache); err != nil {
+		log.Printf("DAT: ignoring unreadable cache %s: %v", path, err)
+		return nil, false
+	}
+
+	if cache.Version != cacheVersion || cache.Fingerprint != fingerprint {
+		return nil, false
+	}
+	return cache.Entries, true
+}
+
+func writeCache(path, fingerprint string, entries []Entry) error {
+	var buf bytes.Buffer
+	cache := indexCache{Version: cacheVersion, Fingerprint: fingerprint, Entries: entries}
+	if err := gob.NewEncoder(&buf).Encode(&cache); err != nil {
+		return err
+	}
+
+	// A power cut while saving must not leave a truncated cache behind
+	return fsutil.WriteFileAtomic(path, buf.Bytes(), 0o644)
+}
@@ -0,0 +1,111 @@
+package hash
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"retroart-sdl2/internal/fsutil"
+)
+
+// cacheEntry remembers the hashes of a file at a given size and mtime
+type cacheEntry struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mtime"`
+	Hashes  Hashes `json:"hashes"`
+}
+
+// Hasher hashes ROMs and caches the results by path, size and modification
+// time, so unchanged files are never read twice
+type Hasher struct {
+	path    string
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	dirty   bool
+}
+
+// NewHasher creates a hasher backed by the cache file at path. A missing or
+// corrupt cache file starts an empty cache. An empty path disables persistence.
+func NewHasher(path string) *Hasher {
+	h := &Hasher{
+		path:    path,
+		entries: make(map[string]cacheEntry),
+	}
+
+	if path == "" {
+		return h
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Hash: failed to read cache %s: %v", path, err)
+		}
+		return h
+	}
+
+	if err := json.Unmarshal(data, &h.entries); err != nil {
+		log.Printf("Hash: ignoring corrupt cache %s: %v", path, err)
+		h.entries = make(map[string]cacheEntry)
+	}
+
+	return h
+}
+
+// Hash returns the hashes of the file, from cache when it did not change
+func (h *Hasher) Hash(path string) (Hashes, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Hashes{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	h.mu.Lock()
+	entry, ok := h.entries[path]
+	h.mu.Unlock()
+
+	if ok && entry.Size == info.Size() && entry.ModTime == info.ModTime().UnixNano() {
+		return entry.Hashes, nil
+	}
+
+	hashes, err := File(path)
+	if err != nil {
+		return Hashes{}, err
+	}
+
+	h.mu.Lock()
+	h.entries[path] = cacheEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Hashes:  hashes,
+	}
+	h.dirty = true
+	h.mu.Unlock()
+
+	return hashes, nil
+}
+
+// Save writes the cache to disk if it changed since it was loaded
+func (h *Hasher) Save() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.path == "" || !h.dirty {
+		return nil
+	}
+
+	data, err := json.Marshal(h.entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode hash cache: %w", err)
+	}
+
+	// A power cut while saving must not leave a truncated cache behind
+	if err := fsutil.WriteFileAtomic(h.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write hash cache: %w", err)
+	}
+
+	h.dirty = false
+	return nil
+}
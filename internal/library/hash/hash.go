@@ -0,0 +1,197 @@
+// Package hash computes ROM checksums compatible with No-Intro/Redump DATs.
+// Files are streamed with a fixed size buffer, zip archives are hashed on
+// their first entry, 7z archives are not hashed and known copier/emulator
+// headers are skipped.
+package hash
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// bufferSize bounds the memory used while hashing a file
+const bufferSize = 64 * 1024
+
+// ErrUnsupportedArchive is returned by File for archives whose entries
+// cannot be read, such as .7z. Hashing the archive itself would give
+// checksums that no DAT or provider knows.
+var ErrUnsupportedArchive = errors.New("unsupported archive format")
+
+// Hashes holds the checksums of a ROM's data
+type Hashes struct {
+	CRC32 string `json:"crc32"`
+	MD5   string `json:"md5"`
+	SHA1  string `json:"sha1"`
+	Size  int64  `json:"size"`  // Size of the hashed data, after header removal
+	Entry string `json:"entry"` // Archive entry that was hashed, empty for plain files
+
+	// FileSize is the size of the ROM with its header: the file size, or
+	// the uncompressed size of the archive entry
+	FileSize int64 `json:"file_size"`
+}
+
+// header describes a header that must be removed before hashing
+type header struct {
+	name       string
+	extensions []string
+	magic      []byte
+	size       int
+	// matches decides whether the header is present when magic is not enough
+	matches func(totalSize int64) bool
+}
+
+var headers = []header{
+	{name: "iNES", extensions: []string{".nes"}, magic: []byte("NES\x1a"), size: 16},
+	{name: "fwNES", extensions: []string{".fds"}, magic: []byte("FDS\x1a"), size: 16},
+	{name: "Lynx", extensions: []string{".lnx"}, magic: []byte("LYNX\x00"), size: 64},
+	{name: "SNES copier", extensions: []string{".sfc", ".smc", ".fig", ".swc"}, size: 512,
+		matches: func(totalSize int64) bool { return totalSize%1024 == 512 }},
+}
+
+// File hashes a ROM on disk. Zip archives are hashed on their first file
+// entry and .7z archives return ErrUnsupportedArchive; any other file is
+// hashed as is.
+func File(path string) (Hashes, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".zip":
+		return zipEntry(path)
+	case ".7z":
+		return Hashes{}, fmt.Errorf("%w: %s", ErrUnsupportedArchive, path)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return Hashes{}, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return Hashes{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+
+	hashes, err := Reader(file, filepath.Base(path), info.Size())
+	hashes.FileSize = info.Size()
+	return hashes, err
+}
+
+// zipEntry hashes the first non directory entry of a zip archive
+func zipEntry(path string) (Hashes, error) {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return Hashes{}, fmt.Errorf("failed to open zip %s: %w", path, err)
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		reader, err := entry.Open()
+		if err != nil {
+			return Hashes{}, fmt.Errorf("failed to open %s in %s: %w", entry.Name, path, err)
+		}
+		defer reader.Close()
+
+		hashes, err := Reader(reader, entry.Name, int64(entry.UncompressedSize64))
+		hashes.Entry = entry.Name
+		hashes.FileSize = int64(entry.UncompressedSize64)
+		return hashes, err
+	}
+
+	return Hashes{}, fmt.Errorf("zip %s has no files", path)
+}
+
+// Reader hashes a stream. name is used to pick which headers may apply and
+// size is the total stream length, needed to detect copier headers.
+func Reader(r io.Reader, name string, size int64) (Hashes, error) {
+	buffered := bufio.NewReaderSize(r, bufferSize)
+
+	skip, err := headerSize(buffered, name, size)
+	if err != nil {
+		return Hashes{}, err
+	}
+	if skip > 0 {
+		if _, err := buffered.Discard(skip); err != nil {
+			return Hashes{}, fmt.Errorf("failed to skip header of %s: %w", name, err)
+		}
+	}
+
+	crcHash := crc32.NewIEEE()
+	md5Hash := md5.New()
+	sha1Hash := sha1.New()
+
+	written, err := io.CopyBuffer(io.MultiWriter(crcHash, md5Hash, sha1Hash), buffered, make([]byte, bufferSize))
+	if err != nil {
+		return Hashes{}, fmt.Errorf("failed to hash %s: %w", name, err)
+	}
+
+	return Hashes{
+		CRC32: hex.EncodeToString(crcHash.Sum(nil)),
+		MD5:   hex.EncodeToString(md5Hash.Sum(nil)),
+		SHA1:  hex.EncodeToString(sha1Hash.Sum(nil)),
+		Size:  written,
+	}, nil
+}
+
+// MayHaveHeader reports whether files with this name can carry a header
+// that is skipped before hashing, in which case checksums stored by other
+// tools (such as the CRC of a zip entry) do not match No-Intro DATs
+func MayHaveHeader(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, h := range headers {
+		if containsExt(h.extensions, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// headerSize returns how many leading bytes should be skipped for the file
+func headerSize(r *bufio.Reader, name string, size int64) (int, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+
+	for _, h := range headers {
+		if !containsExt(h.extensions, ext) || size <= int64(h.size) {
+			continue
+		}
+
+		if h.matches != nil {
+			if h.matches(size) {
+				return h.size, nil
+			}
+			continue
+		}
+
+		peek, err := r.Peek(len(h.magic))
+		if err != nil && err != io.EOF {
+			return 0, fmt.Errorf("failed to read header of %s: %w", name, err)
+		}
+		if bytes.Equal(peek, h.magic) {
+			return h.size, nil
+		}
+	}
+
+	return 0, nil
+}
+
+func containsExt(extensions []string, ext string) bool {
+	for _, e := range extensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
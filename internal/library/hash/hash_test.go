@@ -0,0 +1,179 @@
+package hash
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// romData returns n bytes of ROM content that differ from any header
+func romData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func mustReader(t *testing.T, data []byte, name string) Hashes {
+	t.Helper()
+	hashes, err := Reader(bytes.NewReader(data), name, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hashes
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReaderSkipsHeaders(t *testing.T) {
+	rom := romData(2048)
+
+	tests := []struct {
+		name   string
+		file   string
+		header []byte
+		skip   bool
+	}{
+		{"iNES", "game.nes", append([]byte("NES\x1a"), make([]byte, 12)...), true},
+		{"fwNES", "disk.fds", append([]byte("FDS\x1a"), make([]byte, 12)...), true},
+		{"Lynx", "game.lnx", append([]byte("LYNX\x00"), make([]byte, 59)...), true},
+		{"SNES copier", "game.smc", make([]byte, 512), true},
+		{"headerless SNES", "game.sfc", nil, false},
+		{"iNES magic on another system", "game.bin", append([]byte("NES\x1a"), make([]byte, 12)...), false},
+	}
+
+	want := mustReader(t, rom, "rom.bin")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(append([]byte{}, tt.header...), rom...)
+			got := mustReader(t, data, tt.file)
+			if tt.skip && got != want {
+				t.Errorf("hashes = %+v, want the headerless %+v", got, want)
+			}
+			if !tt.skip && got.Size != int64(len(data)) {
+				t.Errorf("hashed %d bytes, want all %d", got.Size, len(data))
+			}
+		})
+	}
+}
+
+func TestFileHashesFirstZipEntry(t *testing.T) {
+	rom := append(append([]byte("NES\x1a"), make([]byte, 12)...), romData(4096)...)
+	path := filepath.Join(t.TempDir(), "game.zip")
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	if _, err := archive.Create("docs/"); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := archive.Create("Game (USA).nes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry.Write(rom)
+	other, err := archive.Create("readme.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Write([]byte("not a rom"))
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, path, buf.Bytes())
+
+	got, err := File(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := mustReader(t, rom, "Game (USA).nes")
+	if got.CRC32 != want.CRC32 || got.SHA1 != want.SHA1 || got.Size != 4096 {
+		t.Errorf("hashes = %+v, want those of the headerless entry %+v", got, want)
+	}
+	if got.Entry != "Game (USA).nes" || got.FileSize != int64(len(rom)) {
+		t.Errorf("entry = %q, file size = %d, want the ROM entry with its header", got.Entry, got.FileSize)
+	}
+}
+
+func TestFileDoesNotHash7z(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "game.7z")
+	writeFile(t, path, []byte("7z\xbc\xaf\x27\x1c"))
+
+	if _, err := File(path); !errors.Is(err, ErrUnsupportedArchive) {
+		t.Errorf("File error = %v, want ErrUnsupportedArchive", err)
+	}
+}
+
+func TestHasherCachesBySizeAndModTime(t *testing.T) {
+	dir := t.TempDir()
+	rom := filepath.Join(dir, "game.bin")
+	cache := filepath.Join(dir, "hashes.json")
+	writeFile(t, rom, romData(1024))
+	modTime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(rom, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	hasher := NewHasher(cache)
+	first, err := hasher.Hash(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hasher.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same size and mtime: the new content is not read, even by a hasher
+	// that loaded the cache from disk
+	writeFile(t, rom, bytes.Repeat([]byte{0xff}, 1024))
+	if err := os.Chtimes(rom, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	cached, err := NewHasher(cache).Hash(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cached != first {
+		t.Errorf("cached hashes = %+v, want %+v", cached, first)
+	}
+
+	// A new mtime invalidates the entry
+	if err := os.Chtimes(rom, modTime.Add(time.Minute), modTime.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := hasher.Hash(rom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fresh.CRC32 == first.CRC32 {
+		t.Error("hasher returned the cached hashes of a modified file")
+	}
+}
+
+func TestNewHasherIgnoresCorruptCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := filepath.Join(dir, "hashes.json")
+	writeFile(t, cache, []byte("{not json"))
+	rom := filepath.Join(dir, "game.bin")
+	writeFile(t, rom, romData(64))
+
+	hasher := NewHasher(cache)
+	if _, err := hasher.Hash(rom); err != nil {
+		t.Fatal(err)
+	}
+	if err := hasher.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewHasher(cache).Hash(rom); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,74 @@
+// Package library scans a ROMs root directory organised in TrimUI/MinUI style
+// ("Roms/<SYSTEM NAME> (TAG)/") and returns the systems and games found on it.
+package library
+
+import (
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultRomsRoot is the ROMs folder of a TrimUI Smart Pro SD card
+const DefaultRomsRoot = "/mnt/SDCARD/Roms"
+
+// Game is a single ROM file found inside a system folder
+type Game struct {
+	Name     string    // File name without extension
+	Title    string    // Canonical DAT title, empty when the ROM was not identified
+	FileName string    // File name with extension
+	Path     string    // Absolute path to the ROM
+	Ext      string    // Lowercase extension including the dot
+	Size     int64     // Size in bytes
+	ModTime  time.Time // Last modification time
+	SystemID string    // ID of the SystemDefinition that owns this game
+}
+
+// DisplayName returns the canonical title when known, or the file name
+func (g Game) DisplayName() string {
+	if g.Title != "" {
+		return g.Title
+	}
+	return g.Name
+}
+
+// IsArchive reports whether the ROM is stored inside a zip/7z archive
+func (g Game) IsArchive() bool {
+	for _, ext := range archiveExtensions {
+		if g.Ext == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// System is a ROM folder matched against a SystemDefinition
+type System struct {
+	Definition SystemDefinition
+	Folder     string // Folder name as found on disk, e.g. "Super Nintendo (SFC)"
+	Path       string // Absolute path to the folder
+	Games      []Game
+}
+
+// ID returns the identifier of the underlying system definition
+func (s System) ID() string {
+	return s.Definition.ID
+}
+
+// Name returns a display name for the system, preferring the folder name
+// without the trailing tag so user renamed folders are respected.
+func (s System) Name() string {
+	if matches := folderTagPattern.FindStringSubmatch(s.Folder); matches != nil {
+		if name := strings.TrimSpace(matches[1]); name != "" {
+			return name
+		}
+	}
+	if s.Folder != "" {
+		return filepath.Base(s.Folder)
+	}
+	return s.Definition.Name
+}
+
+// GameCount returns the number of games in the system
+func (s System) GameCount() int {
+	return len(s.Games)
+}
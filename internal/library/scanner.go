@@ -0,0 +1,239 @@
+package library
+
+import (
+	"archive/zip"
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"retroart-sdl2/internal/library/dat"
+	"retroart-sdl2/internal/library/hash"
+)
+
+// ignoredDirs are folders created by frontends inside system folders that never
+// contain games
+var ignoredDirs = map[string]bool{
+	"imgs":             true,
+	".res":             true,
+	"media":            true,
+	"downloaded_media": true,
+	"images":           true,
+	"videos":           true,
+	"manuals":          true,
+}
+
+// Scanner walks a ROMs root and builds the list of systems and games
+type Scanner struct {
+	root  string
+	index *dat.Index
+}
+
+// NewScanner creates a scanner for the given ROMs root directory
+func NewScanner(root string) *Scanner {
+	return &Scanner{root: root}
+}
+
+// SetDatIndex enables DAT identification. Zipped ROMs are matched through the
+// CRC stored in the archive directory, which costs no decompression, unless
+// their entry may carry a header; other files keep their file name until
+// they are hashed by the scraper.
+func (s *Scanner) SetDatIndex(index *dat.Index) {
+	s.index = index
+}
+
+// Root returns the directory being scanned
+func (s *Scanner) Root() string {
+	return s.root
+}
+
+// Scan lists every recognised system folder under the root that contains at
+// least one game. Folders of unknown systems are skipped.
+func (s *Scanner) Scan() ([]System, error) {
+	entries, err := os.ReadDir(s.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read roms root %s: %w", s.root, err)
+	}
+
+	systems := make([]System, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() || isHidden(entry.Name()) {
+			continue
+		}
+
+		def, ok := IdentifyFolder(entry.Name())
+		if !ok {
+			log.Printf("Library: skipping unknown system folder '%s'", entry.Name())
+			continue
+		}
+
+		system, err := s.ScanSystem(filepath.Join(s.root, entry.Name()), def)
+		if err != nil {
+			log.Printf("Library: failed to scan '%s': %v", entry.Name(), err)
+			continue
+		}
+
+		if system.GameCount() == 0 {
+			continue
+		}
+		systems = append(systems, system)
+	}
+
+	sort.Slice(systems, func(i, j int) bool {
+		return strings.ToLower(systems[i].Name()) < strings.ToLower(systems[j].Name())
+	})
+
+	log.Printf("Library: found %d systems in %s", len(systems), s.root)
+	return systems, nil
+}
+
+// ScanSystem lists the games inside a single system folder, including games
+// stored in sub folders. Files referenced by an .m3u playlist are hidden so
+// multi-disc games appear only once.
+func (s *Scanner) ScanSystem(path string, def SystemDefinition) (System, error) {
+	system := System{
+		Definition: def,
+		Folder:     filepath.Base(path),
+		Path:       path,
+	}
+
+	playlistEntries := make(map[string]bool)
+
+	err := filepath.WalkDir(path, func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name := entry.Name()
+		if entry.IsDir() {
+			if filePath != path && (isHidden(name) || ignoredDirs[strings.ToLower(name)]) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if isHidden(name) || !def.AcceptsFile(name) {
+			return nil
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		ext := strings.ToLower(filepath.Ext(name))
+		if ext == ".m3u" {
+			for _, ref := range readPlaylist(filePath) {
+				playlistEntries[ref] = true
+			}
+		}
+
+		game := Game{
+			Name:     strings.TrimSuffix(name, filepath.Ext(name)),
+			FileName: name,
+			Path:     filePath,
+			Ext:      ext,
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+			SystemID: def.ID,
+		}
+		if ext == ".zip" && s.index.Len() > 0 {
+			game.Title = s.identifyZip(filePath)
+		}
+
+		system.Games = append(system.Games, game)
+		return nil
+	})
+	if err != nil {
+		return system, err
+	}
+
+	if len(playlistEntries) > 0 {
+		games := system.Games[:0]
+		for _, game := range system.Games {
+			if !playlistEntries[game.Path] {
+				games = append(games, game)
+			}
+		}
+		system.Games = games
+	}
+
+	sort.Slice(system.Games, func(i, j int) bool {
+		return strings.ToLower(system.Games[i].Name) < strings.ToLower(system.Games[j].Name)
+	})
+
+	return system, nil
+}
+
+// identifyZip looks up the first entry of a zip archive in the DAT index.
+// The CRC stored in the archive covers the whole entry, so entries that may
+// carry a header are streamed through the hasher, which skips it.
+func (s *Scanner) identifyZip(path string) string {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return ""
+	}
+	defer archive.Close()
+
+	for _, entry := range archive.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+
+		if !hash.MayHaveHeader(entry.Name) {
+			crc := fmt.Sprintf("%08x", entry.CRC32)
+			if match, ok := s.index.LookupCRC(crc, int64(entry.UncompressedSize64)); ok {
+				return match.Name
+			}
+			return ""
+		}
+
+		reader, err := entry.Open()
+		if err != nil {
+			return ""
+		}
+		defer reader.Close()
+
+		hashes, err := hash.Reader(reader, entry.Name, int64(entry.UncompressedSize64))
+		if err != nil {
+			log.Printf("Library: failed to hash %s in %s: %v", entry.Name, path, err)
+			return ""
+		}
+		if match, ok := s.index.Lookup(hashes); ok {
+			return match.Name
+		}
+		return ""
+	}
+	return ""
+}
+
+// readPlaylist returns the absolute paths of the files listed in an .m3u file
+func readPlaylist(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var entries []string
+	dir := filepath.Dir(path)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+		entries = append(entries, filepath.Clean(line))
+	}
+	return entries
+}
+
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".")
+}
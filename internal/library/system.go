@@ -0,0 +1,132 @@
+package library
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SystemDefinition describes a console/platform known by the scanner: how its
+// ROM folders are tagged and which file extensions are considered games.
+type SystemDefinition struct {
+	ID         string   // Stable identifier used across the app (e.g. "snes")
+	Name       string   // Human readable name
+	Tags       []string // Folder tags used by TrimUI/MinUI, e.g. "SFC" in "Super Nintendo (SFC)"
+	Extensions []string // Lowercase extensions including the dot
+}
+
+// archiveExtensions are accepted for every cartridge based system
+var archiveExtensions = []string{".zip", ".7z"}
+
+// knownSystems lists the systems recognised by the scanner. The order matters
+// only for name based fallbacks, where the first match wins.
+var knownSystems = []SystemDefinition{
+	{ID: "arcade", Name: "Arcade", Tags: []string{"ARCADE", "MAME", "FBNEO", "FBA"}, Extensions: []string{".zip", ".7z"}},
+	{ID: "cps1", Name: "CPS I", Tags: []string{"CPS1"}, Extensions: []string{".zip", ".7z"}},
+	{ID: "cps2", Name: "CPS II", Tags: []string{"CPS2"}, Extensions: []string{".zip", ".7z"}},
+	{ID: "cps3", Name: "CPS III", Tags: []string{"CPS3"}, Extensions: []string{".zip", ".7z"}},
+	{ID: "neogeo", Name: "Neo Geo", Tags: []string{"NEOGEO"}, Extensions: []string{".zip", ".7z"}},
+	{ID: "nes", Name: "Nintendo Entertainment System", Tags: []string{"FC", "NES"}, Extensions: []string{".nes", ".unf", ".unif"}},
+	{ID: "fds", Name: "Famicom Disk System", Tags: []string{"FDS"}, Extensions: []string{".fds"}},
+	{ID: "snes", Name: "Super Nintendo", Tags: []string{"SFC", "SNES"}, Extensions: []string{".sfc", ".smc", ".fig", ".swc", ".bs"}},
+	{ID: "n64", Name: "Nintendo 64", Tags: []string{"N64"}, Extensions: []string{".n64", ".v64", ".z64"}},
+	{ID: "gb", Name: "Game Boy", Tags: []string{"GB"}, Extensions: []string{".gb"}},
+	{ID: "gbc", Name: "Game Boy Color", Tags: []string{"GBC"}, Extensions: []string{".gbc", ".gb"}},
+	{ID: "gba", Name: "Game Boy Advance", Tags: []string{"GBA", "MGBA"}, Extensions: []string{".gba"}},
+	{ID: "nds", Name: "Nintendo DS", Tags: []string{"NDS"}, Extensions: []string{".nds"}},
+	{ID: "vb", Name: "Virtual Boy", Tags: []string{"VB"}, Extensions: []string{".vb", ".vboy"}},
+	{ID: "pokemini", Name: "Pokemon Mini", Tags: []string{"POKE", "PKM"}, Extensions: []string{".min"}},
+	{ID: "gw", Name: "Game & Watch", Tags: []string{"GW"}, Extensions: []string{".mgw"}},
+	{ID: "mastersystem", Name: "Master System", Tags: []string{"MS", "SMS"}, Extensions: []string{".sms"}},
+	{ID: "megadrive", Name: "Mega Drive", Tags: []string{"MD", "GEN"}, Extensions: []string{".md", ".gen", ".smd", ".bin"}},
+	{ID: "segacd", Name: "Sega CD", Tags: []string{"SEGACD", "MDCD"}, Extensions: []string{".cue", ".chd", ".iso", ".m3u"}},
+	{ID: "sega32x", Name: "Sega 32X", Tags: []string{"32X", "THIRTYTWOX"}, Extensions: []string{".32x"}},
+	{ID: "gamegear", Name: "Game Gear", Tags: []string{"GG"}, Extensions: []string{".gg"}},
+	{ID: "sg1000", Name: "SG-1000", Tags: []string{"SG1000", "SEGASGONE"}, Extensions: []string{".sg"}},
+	{ID: "saturn", Name: "Sega Saturn", Tags: []string{"SATURN", "SS"}, Extensions: []string{".cue", ".chd", ".m3u"}},
+	{ID: "dreamcast", Name: "Dreamcast", Tags: []string{"DC"}, Extensions: []string{".cdi", ".gdi", ".chd", ".m3u"}},
+	{ID: "psx", Name: "PlayStation", Tags: []string{"PS", "PS1", "PSX"}, Extensions: []string{".cue", ".chd", ".pbp", ".m3u"}},
+	{ID: "psp", Name: "PSP", Tags: []string{"PSP"}, Extensions: []string{".iso", ".cso", ".pbp", ".chd"}},
+	{ID: "pcengine", Name: "PC Engine", Tags: []string{"PCE", "TG16"}, Extensions: []string{".pce"}},
+	{ID: "pcenginecd", Name: "PC Engine CD", Tags: []string{"PCECD", "TGCD"}, Extensions: []string{".cue", ".chd", ".m3u"}},
+	{ID: "atari2600", Name: "Atari 2600", Tags: []string{"ATARI", "A2600"}, Extensions: []string{".a26", ".bin"}},
+	{ID: "atari7800", Name: "Atari 7800", Tags: []string{"SEVENTYEIGHTHUNDRED", "A7800"}, Extensions: []string{".a78", ".bin"}},
+	{ID: "lynx", Name: "Atari Lynx", Tags: []string{"LYNX"}, Extensions: []string{".lnx"}},
+	{ID: "ngp", Name: "Neo Geo Pocket", Tags: []string{"NGP"}, Extensions: []string{".ngp"}},
+	{ID: "ngpc", Name: "Neo Geo Pocket Color", Tags: []string{"NGPC"}, Extensions: []string{".ngc", ".ngpc"}},
+	{ID: "wonderswan", Name: "WonderSwan", Tags: []string{"WS"}, Extensions: []string{".ws"}},
+	{ID: "wonderswancolor", Name: "WonderSwan Color", Tags: []string{"WSC"}, Extensions: []string{".wsc"}},
+	{ID: "colecovision", Name: "ColecoVision", Tags: []string{"COLECO", "CV"}, Extensions: []string{".col"}},
+	{ID: "msx", Name: "MSX", Tags: []string{"MSX"}, Extensions: []string{".rom", ".mx1", ".mx2", ".dsk"}},
+	{ID: "pico8", Name: "PICO-8", Tags: []string{"PICO", "PICO8"}, Extensions: []string{".p8", ".png"}},
+}
+
+// folderTagPattern extracts "Name" and "TAG" from folders like "Super Nintendo (SFC)"
+var folderTagPattern = regexp.MustCompile(`^(.*?)\s*\(([^()]+)\)\s*$`)
+
+// KnownSystems returns the list of systems the scanner can recognise
+func KnownSystems() []SystemDefinition {
+	return knownSystems
+}
+
+// FindSystem returns the definition with the given ID
+func FindSystem(id string) (SystemDefinition, bool) {
+	for _, def := range knownSystems {
+		if def.ID == id {
+			return def, true
+		}
+	}
+	return SystemDefinition{}, false
+}
+
+// IdentifyFolder works out which system a ROM folder belongs to. The tag in
+// parentheses has priority; when it is missing or unknown the folder name is
+// compared against system names and IDs.
+func IdentifyFolder(folder string) (SystemDefinition, bool) {
+	name := filepath.Base(folder)
+
+	if matches := folderTagPattern.FindStringSubmatch(name); matches != nil {
+		tag := strings.ToUpper(strings.TrimSpace(matches[2]))
+		for _, def := range knownSystems {
+			for _, t := range def.Tags {
+				if t == tag {
+					return def, true
+				}
+			}
+		}
+		name = matches[1]
+	}
+
+	name = strings.TrimSpace(name)
+	for _, def := range knownSystems {
+		if strings.EqualFold(def.Name, name) || strings.EqualFold(def.ID, name) {
+			return def, true
+		}
+		for _, t := range def.Tags {
+			if strings.EqualFold(t, name) {
+				return def, true
+			}
+		}
+	}
+
+	return SystemDefinition{}, false
+}
+
+// AcceptsFile reports whether a file name is a game for this system
+func (def SystemDefinition) AcceptsFile(fileName string) bool {
+	ext := strings.ToLower(filepath.Ext(fileName))
+	if ext == "" {
+		return false
+	}
+	for _, e := range def.Extensions {
+		if e == ext {
+			return true
+		}
+	}
+	for _, e := range archiveExtensions {
+		if e == ext {
+			return true
+		}
+	}
+	return false
+}
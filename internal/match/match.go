@@ -0,0 +1,262 @@
+// Package match compares game titles for name based lookups. File names are
+// cleaned from No-Intro/TOSEC style tags, normalised and compared token by
+// token, so "Legend of Zelda, The - A Link to the Past (USA) [!]" matches
+// "The Legend of Zelda: A Link to the Past".
+package match
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultThreshold is the confidence above which a name match is accepted
+// without review
+const DefaultThreshold = 0.8
+
+// extraWordPenalty scales the score of candidates with words the query does
+// not have, so spin-offs like "Street Fighter II Turbo" are not accepted
+// for "Street Fighter II"
+const extraWordPenalty = 0.75
+
+var (
+	// tagPattern matches (USA), (Rev 1), (En,Fr,De), [!], [b1], {Hack} ...
+	tagPattern = regexp.MustCompile(`\s*[\(\[\{][^\)\]\}]*[\)\]\}]`)
+
+	// trailingArticle matches "Legend of Zelda, The" and
+	// "Legend of Zelda, The - A Link to the Past"
+	trailingArticle = regexp.MustCompile(`(?i)^(.+?), (the|a|an|le|la|les|l'|der|die|das|el|il|lo)((?:\s+[-:].*)?)$`)
+
+	spacePattern = regexp.MustCompile(`\s+`)
+)
+
+// articles are ignored when comparing tokens
+var articles = map[string]bool{"the": true, "a": true, "an": true}
+
+// accents folds the accented letters common in game titles
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ç", "c", "ñ", "n", "ß", "ss",
+)
+
+// Clean strips tags and moves trailing articles to the front, returning a
+// readable title suitable for searches:
+//
+//	"Super Mario World (USA) (Rev 1) [!]" -> "Super Mario World"
+//	"Legend of Zelda, The - A Link to the Past" -> "The Legend of Zelda - A Link to the Past"
+func Clean(name string) string {
+	name = tagPattern.ReplaceAllString(name, "")
+	name = strings.ReplaceAll(name, "_", " ")
+	name = strings.TrimSpace(spacePattern.ReplaceAllString(name, " "))
+
+	if m := trailingArticle.FindStringSubmatch(name); m != nil {
+		article := m[2]
+		separator := " "
+		if strings.HasSuffix(article, "'") {
+			separator = ""
+		}
+		name = article + separator + m[1] + m[3]
+	}
+	return name
+}
+
+// Tokens returns the comparable words of a title: cleaned, lowercased,
+// without punctuation or articles, with accents folded and roman numerals
+// converted to numbers
+func Tokens(title string) []string {
+	title = strings.ToLower(Clean(title))
+	title = accents.Replace(title)
+	title = strings.ReplaceAll(title, "&", " and ")
+	title = strings.ReplaceAll(title, "'", "")
+
+	words := strings.FieldsFunc(title, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := make([]string, 0, len(words))
+	for _, word := range words {
+		if articles[word] {
+			continue
+		}
+		if n, ok := romanToInt(word); ok {
+			word = strconv.Itoa(n)
+		}
+		tokens = append(tokens, word)
+	}
+	return tokens
+}
+
+// Normalize returns the tokens of a title joined by spaces
+func Normalize(title string) string {
+	return strings.Join(Tokens(title), " ")
+}
+
+// Score returns the similarity of a candidate title b to the query a,
+// between 0 and 1. Tokens are matched greedily, allowing small typos in
+// longer words; titles whose numbers differ (sequels) are heavily
+// penalised, and so are candidates adding words to the query (spin-offs).
+func Score(a, b string) float64 {
+	tokensA, tokensB := Tokens(a), Tokens(b)
+	if len(tokensA) == 0 || len(tokensB) == 0 {
+		return 0
+	}
+	if strings.Join(tokensA, " ") == strings.Join(tokensB, " ") {
+		return 1
+	}
+
+	used := make([]bool, len(tokensB))
+	var matched float64
+	for _, ta := range tokensA {
+		best, bestIndex := 0.0, -1
+		for j, tb := range tokensB {
+			if used[j] {
+				continue
+			}
+			if s := tokenSimilarity(ta, tb); s > best {
+				best, bestIndex = s, j
+			}
+		}
+		if bestIndex >= 0 {
+			used[bestIndex] = true
+			matched += best
+		}
+	}
+
+	score := 2 * matched / float64(len(tokensA)+len(tokensB))
+	if !sameNumbers(tokensA, tokensB) {
+		score *= 0.5
+	}
+	if slices.Contains(used, false) {
+		score *= extraWordPenalty
+	}
+	return score
+}
+
+// Candidate is a title ranked against a query
+type Candidate struct {
+	Index      int // Index in the slice passed to Rank
+	Title      string
+	Confidence float64
+}
+
+// Rank scores every title against the query, best first. Ties keep the
+// original order.
+func Rank(query string, titles []string) []Candidate {
+	candidates := make([]Candidate, len(titles))
+	for i, title := range titles {
+		candidates[i] = Candidate{Index: i, Title: title, Confidence: Score(query, title)}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Confidence > candidates[j].Confidence
+	})
+	return candidates
+}
+
+// tokenSimilarity returns 1 for equal tokens and a partial score for
+// near-equal words; numbers must match exactly
+func tokenSimilarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	if isNumber(a) || isNumber(b) || len(a) < 4 || len(b) < 4 {
+		return 0
+	}
+
+	distance := levenshtein(a, b)
+	ratio := 1 - float64(distance)/float64(max(len(a), len(b)))
+	if ratio < 0.75 {
+		return 0
+	}
+	return ratio
+}
+
+func sameNumbers(a, b []string) bool {
+	numbers := func(tokens []string) string {
+		var n []string
+		for _, token := range tokens {
+			if isNumber(token) {
+				n = append(n, token)
+			}
+		}
+		sort.Strings(n)
+		return strings.Join(n, " ")
+	}
+	return numbers(a) == numbers(b)
+}
+
+func isNumber(s string) bool {
+	for _, r := range s {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// romanToInt converts roman numerals up to 39, the range used by game
+// sequels. Single letters other than "i" and "v" are not numerals: a lone
+// "x" is more often a name, as in "Mega Man X", than a tenth episode.
+func romanToInt(s string) (int, bool) {
+	if s == "x" {
+		return 0, false
+	}
+	values := map[byte]int{'i': 1, 'v': 5, 'x': 10}
+	total := 0
+	for i := 0; i < len(s); i++ {
+		v, ok := values[s[i]]
+		if !ok {
+			return 0, false
+		}
+		if i+1 < len(s) && values[s[i+1]] > v {
+			total -= v
+		} else {
+			total += v
+		}
+	}
+	if total <= 0 || total > 39 || intToRoman(total) != s {
+		return 0, false
+	}
+	return total, true
+}
+
+func intToRoman(n int) string {
+	var b strings.Builder
+	for _, step := range []struct {
+		value  int
+		symbol string
+	}{{10, "x"}, {9, "ix"}, {5, "v"}, {4, "iv"}, {1, "i"}} {
+		for n >= step.value {
+			b.WriteString(step.symbol)
+			n -= step.value
+		}
+	}
+	return b.String()
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,115 @@
+package match
+
+import "testing"
+
+func TestClean(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Super Mario World (USA) (Rev 1) [!]", "Super Mario World"},
+		{"Legend of Zelda, The - A Link to the Past (USA)", "The Legend of Zelda - A Link to the Past"},
+		{"Legend of Zelda, The", "The Legend of Zelda"},
+		{"Aventure, L' (France)", "L'Aventure"},
+		{"Sonic_the_Hedgehog (Europe) (En,Fr,De) {Hack}", "Sonic the Hedgehog"},
+		{"  Tetris   (World)  ", "Tetris"},
+		{"Final Fantasy III", "Final Fantasy III"},
+	}
+
+	for _, tt := range tests {
+		if got := Clean(tt.name); got != tt.want {
+			t.Errorf("Clean(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTokens(t *testing.T) {
+	tests := []struct {
+		title string
+		want  string
+	}{
+		{"The Legend of Zelda: A Link to the Past", "legend of zelda link to past"},
+		{"Final Fantasy VI (Japan)", "final fantasy 6"},
+		{"Pokémon Rouge", "pokemon rouge"},
+		{"Mario & Luigi", "mario and luigi"},
+		{"Mega Man X", "mega man x"},
+		{"Mega Man XI", "mega man 11"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.title); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.title, got, tt.want)
+		}
+	}
+}
+
+func TestScoreThreshold(t *testing.T) {
+	tests := []struct {
+		a, b   string
+		accept bool
+	}{
+		{"Legend of Zelda, The - A Link to the Past (USA) [!]", "The Legend of Zelda: A Link to the Past", true},
+		{"Super Mario World (USA) (Rev 1)", "Super Mario World", true},
+		{"Castlevania - Symphony of the Night", "Castelvania: Symphony of the Night", true},
+		{"Final Fantasy VI", "Final Fantasy 6", true},
+		{"Final Fantasy VI", "Final Fantasy VII", false},
+		{"Street Fighter II", "Super Street Fighter II Turbo", false},
+		{"Tetris", "Tetris Attack", false},
+		{"Sonic the Hedgehog", "Kirby's Dream Land", false},
+		{"Mega Man X (USA)", "Mega Man 10", false},
+		// The candidate adds a word to the query: a different game
+		{"Sonic the Hedgehog (USA)", "Sonic the Hedgehog Spinball", false},
+		{"Street Fighter II (USA)", "Street Fighter II Turbo", false},
+		{"Pac-Man", "Ms. Pac-Man", false},
+		{"Super Mario Bros.", "Super Mario Bros. Deluxe", false},
+	}
+
+	for _, tt := range tests {
+		score := Score(tt.a, tt.b)
+		if accepted := score >= DefaultThreshold; accepted != tt.accept {
+			t.Errorf("Score(%q, %q) = %.2f, accepted = %v, want %v", tt.a, tt.b, score, accepted, tt.accept)
+		}
+	}
+}
+
+func TestScoreBounds(t *testing.T) {
+	if got := Score("Super Mario World", "Super Mario World (USA)"); got != 1 {
+		t.Errorf("identical titles scored %.2f, want 1", got)
+	}
+	if got := Score("", "Super Mario World"); got != 0 {
+		t.Errorf("empty title scored %.2f, want 0", got)
+	}
+	if got := Score("(USA) [!]", "Super Mario World"); got != 0 {
+		t.Errorf("title with only tags scored %.2f, want 0", got)
+	}
+}
+
+func TestRank(t *testing.T) {
+	titles := []string{
+		"Super Mario World 2: Yoshi's Island",
+		"Super Mario World",
+		"Super Mario Kart",
+		"Super Mario World",
+	}
+
+	ranked := Rank("Super Mario World (USA) [!]", titles)
+	if len(ranked) != len(titles) {
+		t.Fatalf("Rank returned %d candidates, want %d", len(ranked), len(titles))
+	}
+
+	// Ties keep the original order
+	if ranked[0].Index != 1 || ranked[1].Index != 3 {
+		t.Errorf("best candidates = %d, %d, want 1, 3", ranked[0].Index, ranked[1].Index)
+	}
+	if ranked[0].Confidence != 1 {
+		t.Errorf("exact match confidence = %.2f, want 1", ranked[0].Confidence)
+	}
+	for i := 1; i < len(ranked); i++ {
+		if ranked[i].Confidence > ranked[i-1].Confidence {
+			t.Errorf("candidate %d (%.2f) ranked after a worse one (%.2f)", i, ranked[i].Confidence, ranked[i-1].Confidence)
+		}
+	}
+	if ranked[0].Title != titles[ranked[0].Index] {
+		t.Errorf("candidate title %q does not match index %d", ranked[0].Title, ranked[0].Index)
+	}
+}
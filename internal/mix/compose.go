@@ -0,0 +1,187 @@
+package mix
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"golang.org/x/image/draw"
+
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/scraper"
+)
+
+// ErrMissingMedia is returned when a required layer has no source image
+var ErrMissingMedia = errors.New("missing media for mix layer")
+
+// Compose draws the template layers on a transparent canvas. rating is
+// between 0 and 1; rating layers are skipped when it is zero.
+func Compose(tmpl *Template, images map[scraper.MediaType]image.Image, rating float64) (image.Image, error) {
+	canvas := image.NewNRGBA(image.Rect(0, 0, tmpl.Width, tmpl.Height))
+
+	for i, layer := range tmpl.Layers {
+		boxW, boxH := layer.box(tmpl)
+
+		var img image.Image
+		if layer.Source == SourceRating {
+			if rating > 0 {
+				img = drawStars(boxW, boxH, rating)
+			}
+		} else {
+			for _, source := range layer.Sources() {
+				if images[source] != nil {
+					mode := layer.Fit
+					if mode == "" {
+						mode = imaging.ModeFit
+					}
+					img = imaging.Process(images[source], imaging.Options{
+						Width:        boxW,
+						Height:       boxH,
+						Mode:         mode,
+						AllowUpscale: true,
+					})
+					break
+				}
+			}
+		}
+
+		if img == nil {
+			if layer.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("%w %d (%s)", ErrMissingMedia, i, layer.Source)
+		}
+
+		drawLayer(canvas, img, layer, layer.position(tmpl, img.Bounds()))
+	}
+
+	return canvas, nil
+}
+
+// box returns the size of the layer box in canvas pixels
+func (l Layer) box(tmpl *Template) (int, int) {
+	width, height := l.Width, l.Height
+	if width <= 0 {
+		width = 1
+	}
+	if height <= 0 {
+		height = 1
+	}
+	return max(1, int(width*float64(tmpl.Width))), max(1, int(height*float64(tmpl.Height)))
+}
+
+// position returns the top-left corner of an image of the given bounds
+// aligned to the layer anchor
+func (l Layer) position(tmpl *Template, bounds image.Rectangle) image.Point {
+	anchor, ok := anchors[l.Anchor]
+	if !ok {
+		anchor = anchors["center"]
+	}
+
+	x := anchor[0]*float64(tmpl.Width) + l.X*float64(tmpl.Width) - anchor[0]*float64(bounds.Dx())
+	y := anchor[1]*float64(tmpl.Height) + l.Y*float64(tmpl.Height) - anchor[1]*float64(bounds.Dy())
+	return image.Pt(int(x), int(y))
+}
+
+// drawLayer draws the shadow and the image with the layer opacity
+func drawLayer(canvas *image.NRGBA, img image.Image, layer Layer, at image.Point) {
+	bounds := img.Bounds()
+	dst := image.Rectangle{Min: at, Max: at.Add(bounds.Size())}
+	opacity := layer.opacity()
+
+	if shadow := layer.Shadow; shadow != nil && shadow.Opacity > 0 {
+		tint, _ := parseColor(shadow.Color)
+		mask, origin := shadowMask(img, shadow.Blur, shadow.Opacity*opacity)
+		shadowAt := dst.Min.Add(image.Pt(shadow.X, shadow.Y)).Add(origin)
+		draw.DrawMask(canvas, mask.Bounds().Add(shadowAt), image.NewUniform(tint), image.Point{}, mask, image.Point{}, draw.Over)
+	}
+
+	var mask image.Image
+	if opacity < 1 {
+		mask = image.NewUniform(color.Alpha{A: uint8(opacity * 255)})
+	}
+	draw.DrawMask(canvas, dst, img, bounds.Min, mask, image.Point{}, draw.Over)
+}
+
+// shadowMask returns the blurred alpha of img scaled by opacity, grown by
+// the blur radius on every side, and the offset of its origin
+func shadowMask(img image.Image, radius int, opacity float64) (*image.Alpha, image.Point) {
+	radius = max(radius, 0)
+	bounds := img.Bounds()
+	mask := image.NewAlpha(image.Rect(0, 0, bounds.Dx()+2*radius, bounds.Dy()+2*radius))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			_, _, _, a := img.At(x, y).RGBA()
+			mask.SetAlpha(x-bounds.Min.X+radius, y-bounds.Min.Y+radius, color.Alpha{A: uint8(float64(a>>8) * opacity)})
+		}
+	}
+
+	// Three box blur passes approximate a gaussian blur
+	if radius > 0 {
+		for range 3 {
+			boxBlur(mask, radius)
+		}
+	}
+	return mask, image.Pt(-radius, -radius)
+}
+
+// boxBlur blurs the alpha mask in place, horizontally then vertically
+func boxBlur(mask *image.Alpha, radius int) {
+	w, h := mask.Rect.Dx(), mask.Rect.Dy()
+	line := make([]uint8, max(w, h))
+	window := 2*radius + 1
+
+	blur := func(get func(int) uint8, set func(int, uint8), n int) {
+		sum := 0
+		for i := -radius; i <= radius; i++ {
+			if i >= 0 && i < n {
+				sum += int(get(i))
+			}
+		}
+		for i := 0; i < n; i++ {
+			line[i] = uint8(sum / window)
+			if out := i - radius; out >= 0 {
+				sum -= int(get(out))
+			}
+			if in := i + radius + 1; in < n {
+				sum += int(get(in))
+			}
+		}
+		for i := 0; i < n; i++ {
+			set(i, line[i])
+		}
+	}
+
+	for y := 0; y < h; y++ {
+		row := mask.Pix[y*mask.Stride : y*mask.Stride+w]
+		blur(func(i int) uint8 { return row[i] }, func(i int, v uint8) { row[i] = v }, w)
+	}
+	for x := 0; x < w; x++ {
+		blur(func(i int) uint8 { return mask.Pix[i*mask.Stride+x] },
+			func(i int, v uint8) { mask.Pix[i*mask.Stride+x] = v }, h)
+	}
+}
+
+// parseColor parses "#rrggbb" or "#rrggbbaa", defaulting to black
+func parseColor(value string) (color.Color, error) {
+	if value == "" {
+		return color.Black, nil
+	}
+
+	hex := strings.TrimPrefix(value, "#")
+	if len(hex) != 6 && len(hex) != 8 {
+		return nil, fmt.Errorf("invalid color %q", value)
+	}
+	if len(hex) == 6 {
+		hex += "ff"
+	}
+	rgba, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return nil, fmt.Errorf("invalid color %q", value)
+	}
+	return color.NRGBA{R: uint8(rgba >> 24), G: uint8(rgba >> 16), B: uint8(rgba >> 8), A: uint8(rgba)}, nil
+}
@@ -0,0 +1,178 @@
+package mix
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/scraper"
+)
+
+func solid(w, h int, c color.NRGBA) image.Image {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.R, c.G, c.B, c.A
+	}
+	return img
+}
+
+func TestBuiltinTemplatesAreValid(t *testing.T) {
+	templates, err := LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{DefaultTemplate, "box-and-wheel", "screenshot-logo"} {
+		if templates[name] == nil {
+			t.Errorf("built-in template %q missing, have %v", name, TemplateNames(templates))
+		}
+	}
+}
+
+func TestParseTemplateRejectsInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+	}{
+		{"malformed", `{"name": "x",`},
+		{"no size", `{"name": "x", "layers": [{"source": "wheel"}]}`},
+		{"no layers", `{"name": "x", "width": 100, "height": 100}`},
+		{"layer without source", `{"name": "x", "width": 100, "height": 100, "layers": [{}]}`},
+		{"unknown anchor", `{"name": "x", "width": 100, "height": 100, "layers": [{"source": "wheel", "anchor": "middle"}]}`},
+		{"unknown fit", `{"name": "x", "width": 100, "height": 100, "layers": [{"source": "wheel", "fit": "pad"}]}`},
+		{"bad shadow color", `{"name": "x", "width": 100, "height": 100, "layers": [{"source": "wheel", "shadow": {"color": "red"}}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTemplate([]byte(tt.json)); err == nil {
+				t.Error("ParseTemplate accepted the template")
+			}
+		})
+	}
+}
+
+func TestParseTemplate(t *testing.T) {
+	tmpl, err := ParseTemplate([]byte(`{"name": "x", "width": 300, "height": 200, "layers": [
+		{"source": "screenshot", "fallback": ["title"], "fit": "fill"},
+		{"source": "rating", "anchor": "top-right", "shadow": {"color": "#ff000080", "opacity": 0.5}},
+		{"source": "title", "anchor": "bottom"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []scraper.MediaType{scraper.MediaScreenshot, scraper.MediaTitle}
+	if got := tmpl.Sources(); len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Sources = %v, want %v", got, want)
+	}
+}
+
+func TestLoadTemplatesFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"classic.json": `{"name": "classic", "width": 100, "height": 100, "layers": [{"source": "wheel"}]}`,
+		"banner.json":  `{"width": 300, "height": 100, "layers": [{"source": "wheel"}]}`,
+		"broken.json":  `{"width": 0}`,
+		"notes.txt":    `not a template`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	templates, err := LoadTemplates(dir)
+	if err == nil {
+		t.Error("LoadTemplates did not report broken.json")
+	}
+	if tmpl := templates["classic"]; tmpl == nil || tmpl.Width != 100 {
+		t.Errorf("classic = %+v, want the template of the directory", tmpl)
+	}
+	if templates["banner"] == nil {
+		t.Error("template without a name not loaded under its file name")
+	}
+	if templates["box-and-wheel"] == nil {
+		t.Error("a broken template hid the built-in ones")
+	}
+}
+
+func TestComposeClassic(t *testing.T) {
+	templates, err := LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := templates["classic"]
+
+	red := color.NRGBA{R: 255, A: 255}
+	blue := color.NRGBA{B: 255, A: 255}
+	images := map[scraper.MediaType]image.Image{
+		scraper.MediaScreenshot: solid(320, 240, red),
+		scraper.MediaBox3D:      solid(100, 150, blue),
+	}
+
+	canvas, err := Compose(tmpl, images, 0.8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := canvas.Bounds().Size(); got != image.Pt(640, 480) {
+		t.Fatalf("canvas size = %v, want 640x480", got)
+	}
+
+	// The box sits on the screenshot at the bottom left, the top left corner
+	// is left transparent
+	for _, tt := range []struct {
+		x, y int
+		want color.NRGBA
+	}{
+		{630, 240, red},
+		{10, 470, blue},
+		{5, 5, color.NRGBA{}},
+	} {
+		if got := color.NRGBAModel.Convert(canvas.At(tt.x, tt.y)); got != tt.want {
+			t.Errorf("pixel (%d, %d) = %v, want %v", tt.x, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestComposeMissingMedia(t *testing.T) {
+	templates, err := LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	images := map[scraper.MediaType]image.Image{scraper.MediaBox3D: solid(10, 10, color.NRGBA{A: 255})}
+	if _, err := Compose(templates["classic"], images, 0); !errors.Is(err, ErrMissingMedia) {
+		t.Errorf("Compose error = %v, want ErrMissingMedia", err)
+	}
+}
+
+func TestMixerEncodesPNG(t *testing.T) {
+	templates, err := LoadTemplates("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var screenshot bytes.Buffer
+	if err := imaging.Encode(&screenshot, solid(64, 48, color.NRGBA{G: 255, A: 255}), imaging.Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	mixer := NewMixer(templates["screenshot-logo"])
+	data, err := mixer.Mix(&scraper.Result{}, map[scraper.MediaType][]byte{
+		scraper.MediaScreenshot: screenshot.Bytes(),
+		scraper.MediaWheel:      []byte("not an image"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	config, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.Width != 640 || config.Height != 480 {
+		t.Errorf("mix size = %dx%d, want 640x480", config.Width, config.Height)
+	}
+}
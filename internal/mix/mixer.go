@@ -0,0 +1,50 @@
+package mix
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/scraper"
+)
+
+// Mixer builds scraper.MediaMixed images for the job engine from the media
+// downloaded for a match
+type Mixer struct {
+	Template *Template
+}
+
+// NewMixer creates a mixer for the template
+func NewMixer(tmpl *Template) *Mixer {
+	return &Mixer{Template: tmpl}
+}
+
+// Sources returns the media types the template needs
+func (m *Mixer) Sources() []scraper.MediaType {
+	return m.Template.Sources()
+}
+
+// Mix decodes the sources, composes them and returns a PNG. Sources that
+// fail to decode are treated as missing.
+func (m *Mixer) Mix(result *scraper.Result, sources map[scraper.MediaType][]byte) ([]byte, error) {
+	images := make(map[scraper.MediaType]image.Image, len(sources))
+	for mediaType, data := range sources {
+		img, _, err := imaging.Decode(bytes.NewReader(data))
+		if err != nil {
+			continue
+		}
+		images[mediaType] = img
+	}
+
+	canvas, err := Compose(m.Template, images, result.Metadata.Rating)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %w", m.Template.Name, err)
+	}
+
+	var out bytes.Buffer
+	if err := imaging.Encode(&out, canvas, imaging.Options{Format: imaging.FormatPNG}); err != nil {
+		return nil, fmt.Errorf("failed to encode mix: %w", err)
+	}
+	return out.Bytes(), nil
+}
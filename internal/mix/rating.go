@@ -0,0 +1,60 @@
+package mix
+
+import (
+	"image"
+	"image/color"
+	"math"
+
+	"golang.org/x/image/draw"
+	"golang.org/x/image/vector"
+)
+
+// starCount is the number of stars of a rating badge
+const starCount = 5
+
+var (
+	starFilled = color.NRGBA{R: 255, G: 196, B: 0, A: 255}
+	starEmpty  = color.NRGBA{R: 90, G: 90, B: 90, A: 200}
+)
+
+// drawStars renders rating (0-1) as a row of stars fitted in w x h. Partial
+// stars are filled proportionally.
+func drawStars(w, h int, rating float64) image.Image {
+	size := min(float64(h), float64(w)/starCount)
+	width := int(math.Ceil(size * starCount))
+	height := int(math.Ceil(size))
+
+	raster := vector.NewRasterizer(width, height)
+	for i := range starCount {
+		addStar(raster, float32(size)*(float32(i)+0.5), float32(size)/2, float32(size)/2)
+	}
+	mask := image.NewAlpha(image.Rect(0, 0, width, height))
+	raster.Draw(mask, mask.Bounds(), image.Opaque, image.Point{})
+
+	stars := image.NewNRGBA(mask.Bounds())
+	draw.DrawMask(stars, stars.Bounds(), image.NewUniform(starEmpty), image.Point{}, mask, image.Point{}, draw.Src)
+
+	filled := image.Rect(0, 0, int(float64(width)*min(max(rating, 0), 1)), height)
+	draw.DrawMask(stars, filled, image.NewUniform(starFilled), image.Point{}, mask, image.Point{}, draw.Src)
+	return stars
+}
+
+// addStar adds a five pointed star centered on (cx, cy) to the rasterizer
+func addStar(raster *vector.Rasterizer, cx, cy, radius float32) {
+	inner := radius * 0.45
+	for i := range 10 {
+		r := radius
+		if i%2 == 1 {
+			r = inner
+		}
+		angle := float64(i)*math.Pi/5 - math.Pi/2
+		x := cx + r*float32(math.Cos(angle))
+		y := cy + r*float32(math.Sin(angle))
+		if i == 0 {
+			raster.MoveTo(x, y)
+		} else {
+			raster.LineTo(x, y)
+		}
+	}
+	raster.ClosePath()
+}
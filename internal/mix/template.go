@@ -0,0 +1,208 @@
+// Package mix composes "mix" images from several downloaded media, e.g. a
+// screenshot background with a 3D box and the wheel logo on top, following
+// declarative JSON templates.
+package mix
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/scraper"
+)
+
+// SourceRating draws the game rating as a row of stars instead of a media
+const SourceRating = "rating"
+
+// DefaultTemplate is used when the configured template does not exist
+const DefaultTemplate = "classic"
+
+//go:embed templates/*.json
+var builtinTemplates embed.FS
+
+// Anchor names accepted in Layer.Anchor
+var anchors = map[string][2]float64{
+	"top-left":     {0, 0},
+	"top":          {0.5, 0},
+	"top-right":    {1, 0},
+	"left":         {0, 0.5},
+	"center":       {0.5, 0.5},
+	"right":        {1, 0.5},
+	"bottom-left":  {0, 1},
+	"bottom":       {0.5, 1},
+	"bottom-right": {1, 1},
+}
+
+// Template describes a mix image. Layers are drawn in order, the first one
+// at the bottom.
+type Template struct {
+	Name   string  `json:"name"`
+	Width  int     `json:"width"`
+	Height int     `json:"height"`
+	Layers []Layer `json:"layers"`
+}
+
+// Layer places one source on the canvas. Positions and sizes are fractions
+// of the canvas so templates do not depend on the output resolution.
+type Layer struct {
+	Source   string       `json:"source"`             // Media type or "rating"
+	Fallback []string     `json:"fallback,omitempty"` // Media types tried when Source is missing
+	Anchor   string       `json:"anchor"`             // e.g. "bottom-left", defaults to "center"
+	X        float64      `json:"x"`                  // Offset from the anchor
+	Y        float64      `json:"y"`
+	Width    float64      `json:"width"` // Box size, defaults to the full canvas
+	Height   float64      `json:"height"`
+	Fit      imaging.Mode `json:"fit,omitempty"`     // "fit" (default) or "fill"
+	Opacity  *float64     `json:"opacity,omitempty"` // Defaults to 1
+	Shadow   *Shadow      `json:"shadow,omitempty"`
+	Optional bool         `json:"optional,omitempty"` // Skip instead of failing when missing
+}
+
+// Shadow is a blurred drop shadow drawn below a layer
+type Shadow struct {
+	X       int     `json:"x"` // Offset in canvas pixels
+	Y       int     `json:"y"`
+	Blur    int     `json:"blur"` // Blur radius in canvas pixels
+	Opacity float64 `json:"opacity"`
+	Color   string  `json:"color,omitempty"` // "#rrggbb", defaults to black
+}
+
+// Sources returns the media types the layer may use, in order
+func (l Layer) Sources() []scraper.MediaType {
+	if l.Source == SourceRating {
+		return nil
+	}
+	sources := []scraper.MediaType{scraper.MediaType(l.Source)}
+	for _, fallback := range l.Fallback {
+		sources = append(sources, scraper.MediaType(fallback))
+	}
+	return sources
+}
+
+func (l Layer) opacity() float64 {
+	if l.Opacity == nil {
+		return 1
+	}
+	return min(max(*l.Opacity, 0), 1)
+}
+
+// Sources returns every media type used by the template
+func (t *Template) Sources() []scraper.MediaType {
+	var sources []scraper.MediaType
+	seen := make(map[scraper.MediaType]bool)
+	for _, layer := range t.Layers {
+		for _, source := range layer.Sources() {
+			if !seen[source] {
+				seen[source] = true
+				sources = append(sources, source)
+			}
+		}
+	}
+	return sources
+}
+
+// Validate checks the template for errors
+func (t *Template) Validate() error {
+	if t.Width <= 0 || t.Height <= 0 {
+		return fmt.Errorf("template %s: invalid size %dx%d", t.Name, t.Width, t.Height)
+	}
+	if len(t.Layers) == 0 {
+		return fmt.Errorf("template %s: no layers", t.Name)
+	}
+	for i, layer := range t.Layers {
+		if layer.Source == "" {
+			return fmt.Errorf("template %s: layer %d has no source", t.Name, i)
+		}
+		if _, ok := anchors[layer.Anchor]; layer.Anchor != "" && !ok {
+			return fmt.Errorf("template %s: layer %d has unknown anchor %q", t.Name, i, layer.Anchor)
+		}
+		if layer.Fit != "" && layer.Fit != imaging.ModeFit && layer.Fit != imaging.ModeFill {
+			return fmt.Errorf("template %s: layer %d has unknown fit %q", t.Name, i, layer.Fit)
+		}
+		if layer.Shadow != nil {
+			if _, err := parseColor(layer.Shadow.Color); err != nil {
+				return fmt.Errorf("template %s: layer %d: %w", t.Name, i, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ParseTemplate decodes and validates a JSON template
+func ParseTemplate(data []byte) (*Template, error) {
+	var tmpl Template
+	if err := json.Unmarshal(data, &tmpl); err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+	if err := tmpl.Validate(); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// LoadTemplates returns the built-in templates plus the ones found in dir,
+// which override built-ins with the same name. A missing dir is not an error.
+func LoadTemplates(dir string) (map[string]*Template, error) {
+	templates := make(map[string]*Template)
+	if err := loadFS(builtinTemplates, "templates", templates); err != nil {
+		return nil, err
+	}
+
+	if dir == "" {
+		return templates, nil
+	}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		return templates, nil
+	}
+	if err := loadFS(os.DirFS(dir), ".", templates); err != nil {
+		return templates, err
+	}
+	return templates, nil
+}
+
+func loadFS(fsys fs.FS, dir string, templates map[string]*Template) error {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	// A broken template must not hide the valid ones
+	var errs []error
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".json") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(dir, entry.Name())))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to read template %s: %w", entry.Name(), err))
+			continue
+		}
+		tmpl, err := ParseTemplate(data)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+			continue
+		}
+		if tmpl.Name == "" {
+			tmpl.Name = strings.TrimSuffix(entry.Name(), filepath.Ext(entry.Name()))
+		}
+		templates[tmpl.Name] = tmpl
+	}
+	return errors.Join(errs...)
+}
+
+// TemplateNames returns the sorted names of the templates
+func TemplateNames(templates map[string]*Template) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
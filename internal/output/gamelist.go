@@ -0,0 +1,331 @@
+package output
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"retroart-sdl2/internal/fsutil"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+// GamelistFile is the EmulationStation game list written in every system
+// folder, where ES-DE, Batocera and Knulli look for it
+const GamelistFile = "gamelist.xml"
+
+// gamelistMedia lists, for every media element, the media types used in
+// order of preference
+var gamelistMedia = []struct {
+	Element string
+	Types   []scraper.MediaType
+}{
+	{"image", []scraper.MediaType{scraper.MediaMixed, scraper.MediaScreenshot, scraper.MediaBoxFront}},
+	{"thumbnail", []scraper.MediaType{scraper.MediaBoxFront, scraper.MediaBox3D}},
+	{"marquee", []scraper.MediaType{scraper.MediaWheel, scraper.MediaMarquee}},
+	{"video", []scraper.MediaType{scraper.MediaVideo}},
+}
+
+// Gamelist exports matched games to the gamelist.xml of their system. It
+// implements job.Exporter.
+//
+// Existing files are merged: entries of games that were not scraped,
+// unknown elements and attributes are kept as they are, and a field is only
+// replaced when it still holds the value RetroArt wrote last time, so edits
+// made in the frontend survive. The values written are remembered in a
+// state file per system.
+type Gamelist struct {
+	writer   *Writer
+	romsRoot string
+	stateDir string
+	prefs    scraper.MetadataPrefs
+	regions  *scraper.RegionResolver
+
+	mu      sync.Mutex
+	pending map[string][]gamelistGame // By system folder
+}
+
+// gamelistGame is an exported game waiting for Flush
+type gamelistGame struct {
+	path   string // "./" relative path used as key by EmulationStation
+	fields []gamelistField
+}
+
+type gamelistField struct {
+	name  string
+	value string
+}
+
+// NewGamelist creates an exporter. Media paths are looked up through the
+// writer; stateDir keeps the values written to every game list. Localized
+// texts are picked with prefs, the regions of the ROM recorded on the result
+// first.
+func NewGamelist(writer *Writer, romsRoot, stateDir string, prefs scraper.MetadataPrefs, regions *scraper.RegionResolver) *Gamelist {
+	return &Gamelist{
+		writer:   writer,
+		romsRoot: romsRoot,
+		stateDir: stateDir,
+		prefs:    prefs,
+		regions:  regions,
+		pending:  make(map[string][]gamelistGame),
+	}
+}
+
+// Export records a game for the next Flush
+func (g *Gamelist) Export(game library.Game, result *scraper.Result) {
+	systemDir := g.systemDir(game)
+	entry := gamelistGame{path: relativePath(systemDir, game.Path)}
+
+	add := func(name, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			entry.fields = append(entry.fields, gamelistField{name, value})
+		}
+	}
+	prefs := g.regions.Prefs(g.prefs, result.Regions)
+	metadata := &result.Metadata
+	add("name", result.DisplayTitle(prefs))
+	add("desc", metadata.Description(prefs))
+	if metadata.Rating > 0 {
+		add("rating", strconv.FormatFloat(float64(int(metadata.Rating*100+0.5))/100, 'f', -1, 64))
+	}
+	add("releasedate", gamelistDate(metadata.ReleaseDate(prefs)))
+	add("developer", metadata.Developer)
+	add("publisher", metadata.Publisher)
+	add("genre", strings.Join(metadata.GenreNames(prefs), ", "))
+	add("players", metadata.Players)
+
+	for _, media := range gamelistMedia {
+		for _, mediaType := range media.Types {
+			if path, ok := g.writer.Find(game, mediaType); ok {
+				add(media.Element, relativePath(systemDir, path))
+				break
+			}
+		}
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.pending[systemDir] = append(g.pending[systemDir], entry)
+}
+
+// Flush merges the recorded games into the game list of every system
+func (g *Gamelist) Flush() error {
+	g.mu.Lock()
+	pending := g.pending
+	g.pending = make(map[string][]gamelistGame)
+	g.mu.Unlock()
+
+	var errs []error
+	for systemDir, games := range pending {
+		if err := g.merge(systemDir, games); err != nil {
+			errs = append(errs, fmt.Errorf("gamelist %s: %w", systemDir, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// systemDir returns the system folder of a game, the first folder below
+// the ROMs root
+func (g *Gamelist) systemDir(game library.Game) string {
+	if rel, err := filepath.Rel(g.romsRoot, game.Path); err == nil && !strings.HasPrefix(rel, "..") {
+		if first, _, found := strings.Cut(filepath.ToSlash(rel), "/"); found {
+			return filepath.Join(g.romsRoot, first)
+		}
+	}
+	return filepath.Dir(game.Path)
+}
+
+// merge updates the game list of a system folder with the exported games
+func (g *Gamelist) merge(systemDir string, games []gamelistGame) error {
+	path := filepath.Join(systemDir, GamelistFile)
+	root, err := readGamelist(path)
+	if err != nil {
+		// Never replace a file we could not understand
+		return err
+	}
+
+	statePath := g.statePath(systemDir)
+	state := loadGamelistState(statePath)
+
+	// Positions, not pointers: appending a new game may move the slice
+	index := make(map[string]int)
+	for i, child := range root.Children {
+		if child.XMLName.Local == "game" {
+			if p := child.child("path"); p != nil {
+				index[cleanGamelistPath(p.Text)] = i
+			}
+		}
+	}
+
+	for _, game := range games {
+		key := cleanGamelistPath(game.path)
+		i, ok := index[key]
+		if !ok {
+			root.Children = append(root.Children, xmlElement{
+				XMLName:  xml.Name{Local: "game"},
+				Children: []xmlElement{{XMLName: xml.Name{Local: "path"}, Text: game.path}},
+			})
+			i = len(root.Children) - 1
+			index[key] = i
+		}
+		element := &root.Children[i]
+
+		written := state[key]
+		if written == nil {
+			written = make(map[string]string)
+			state[key] = written
+		}
+		for _, field := range game.fields {
+			child := element.child(field.name)
+			if child == nil {
+				element.Children = append(element.Children, xmlElement{XMLName: xml.Name{Local: field.name}})
+				child = &element.Children[len(element.Children)-1]
+			} else if current := strings.TrimSpace(child.Text); current != "" && current != written[field.name] {
+				// Edited by the user or written by another tool
+				continue
+			}
+			child.Text = field.value
+			written[field.name] = field.value
+		}
+	}
+
+	data, err := xml.MarshalIndent(root, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to encode: %w", err)
+	}
+	data = append([]byte(xml.Header), append(data, '\n')...)
+	if err := fsutil.WriteFileAtomic(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write: %w", err)
+	}
+	return saveGamelistState(statePath, state)
+}
+
+func (g *Gamelist) statePath(systemDir string) string {
+	sum := sha256.Sum256([]byte(systemDir))
+	return filepath.Join(g.stateDir, "gamelist-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// loadGamelistState returns the values written per game path and field. A
+// missing or corrupt state only makes existing values look user edited.
+func loadGamelistState(path string) map[string]map[string]string {
+	state := make(map[string]map[string]string)
+	if data, err := os.ReadFile(path); err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+func saveGamelistState(path string, state map[string]map[string]string) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	return nil
+}
+
+// xmlElement is a generic XML element, so elements and attributes RetroArt
+// does not know survive a merge
+type xmlElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr   `xml:",any,attr"`
+	Text     string       `xml:",chardata"`
+	Children []xmlElement `xml:",any"`
+}
+
+func (e *xmlElement) child(name string) *xmlElement {
+	for i := range e.Children {
+		if e.Children[i].XMLName.Local == name {
+			return &e.Children[i]
+		}
+	}
+	return nil
+}
+
+// trimLayout drops the indentation text of elements with children, which
+// would otherwise pile up every time the file is rewritten
+func (e *xmlElement) trimLayout() {
+	if len(e.Children) > 0 && strings.TrimSpace(e.Text) == "" {
+		e.Text = ""
+	}
+	for i := range e.Children {
+		e.Children[i].trimLayout()
+	}
+}
+
+// readGamelist parses a game list; a missing file yields an empty one
+func readGamelist(path string) (*xmlElement, error) {
+	root := &xmlElement{XMLName: xml.Name{Local: "gameList"}}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return root, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return root, nil
+	}
+
+	if err := xml.Unmarshal(data, root); err != nil {
+		return nil, fmt.Errorf("failed to parse: %w", err)
+	}
+	if root.XMLName.Local != "gameList" {
+		return nil, fmt.Errorf("unexpected root element <%s>", root.XMLName.Local)
+	}
+	root.trimLayout()
+	return root, nil
+}
+
+// relativePath returns path relative to the system folder in the "./"
+// form EmulationStation expects, or the absolute path when it lies outside
+func relativePath(systemDir, path string) string {
+	rel, err := filepath.Rel(systemDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(path)
+	}
+	return "./" + filepath.ToSlash(rel)
+}
+
+func cleanGamelistPath(path string) string {
+	path = strings.TrimSpace(path)
+	if !strings.HasPrefix(path, "/") {
+		path = "./" + strings.TrimPrefix(path, "./")
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+// gamelistDate converts "1991-08-23", "1991-08" or "1991" to the
+// "19910823T000000" form used by EmulationStation
+func gamelistDate(date string) string {
+	parts := strings.Split(strings.TrimSpace(date), "-")
+	if len(parts) == 0 || len(parts[0]) != 4 {
+		return ""
+	}
+	digits := parts[0]
+	for i := 1; i < 3; i++ {
+		part := "01"
+		if i < len(parts) && len(parts[i]) == 2 {
+			part = parts[i]
+		}
+		digits += part
+	}
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return ""
+		}
+	}
+	return digits + "T000000"
+}
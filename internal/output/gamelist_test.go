@@ -0,0 +1,164 @@
+package output
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+const existingGamelist = `<?xml version="1.0"?>
+<gameList>
+	<provider><System>SNES</System></provider>
+	<game id="7">
+		<path>./Alpha.sfc</path>
+		<name>Alpha</name>
+		<favorite>true</favorite>
+	</game>
+	<game>
+		<path>./Bravo.sfc</path>
+		<name>Bravo (hand written)</name>
+	</game>
+</gameList>
+`
+
+type gamelistEntry struct {
+	Path      string `xml:"path"`
+	Name      string `xml:"name"`
+	Desc      string `xml:"desc"`
+	Rating    string `xml:"rating"`
+	Favorite  string `xml:"favorite"`
+	Developer string `xml:"developer"`
+	ID        string `xml:"id,attr"`
+}
+
+func readEntries(t *testing.T, path string) map[string]gamelistEntry {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var list struct {
+		Games []gamelistEntry `xml:"game"`
+	}
+	if err := xml.Unmarshal(data, &list); err != nil {
+		t.Fatalf("gamelist is not valid XML: %v\n%s", err, data)
+	}
+	entries := make(map[string]gamelistEntry)
+	for _, game := range list.Games {
+		if _, dup := entries[game.Path]; dup {
+			t.Errorf("game %s listed twice", game.Path)
+		}
+		entries[game.Path] = game
+	}
+	return entries
+}
+
+func TestGamelistMerge(t *testing.T) {
+	systemDir := t.TempDir()
+	path := filepath.Join(systemDir, GamelistFile)
+	if err := os.WriteFile(path, []byte(existingGamelist), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	g := &Gamelist{stateDir: t.TempDir()}
+
+	// First export: Alpha was never written by RetroArt, so its name is
+	// treated as a user edit; the missing fields are added
+	alpha := gamelistGame{path: "./Alpha.sfc", fields: []gamelistField{
+		{"name", "Alpha Scraped"}, {"desc", "First desc"}, {"rating", "0.5"},
+	}}
+	if err := g.merge(systemDir, []gamelistGame{alpha}); err != nil {
+		t.Fatal(err)
+	}
+	entries := readEntries(t, path)
+	if got := entries["./Alpha.sfc"]; got.Name != "Alpha" || got.Desc != "First desc" || got.Rating != "0.5" {
+		t.Errorf("Alpha after first merge = %+v", got)
+	}
+
+	// The user edits the description in the frontend
+	data, _ := os.ReadFile(path)
+	data = []byte(strings.Replace(string(data), "First desc", "My own desc", 1))
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Second export: many new games are appended before Alpha is updated,
+	// which grows the element slice past its capacity
+	var games []gamelistGame
+	for i := range 20 {
+		games = append(games, gamelistGame{
+			path:   fmt.Sprintf("./New %02d.sfc", i),
+			fields: []gamelistField{{"name", fmt.Sprintf("New %02d", i)}, {"rating", "0.7"}},
+		})
+	}
+	alpha.fields = []gamelistField{
+		{"name", "Alpha Scraped"}, {"desc", "Second desc"}, {"rating", "0.9"}, {"developer", "Studio"},
+	}
+	games = append(games, alpha)
+	if err := g.merge(systemDir, games); err != nil {
+		t.Fatal(err)
+	}
+
+	entries = readEntries(t, path)
+	if len(entries) != 22 {
+		t.Errorf("gamelist has %d games, want 22", len(entries))
+	}
+	got := entries["./Alpha.sfc"]
+	if got.Desc != "My own desc" {
+		t.Errorf("user edited desc replaced: %q", got.Desc)
+	}
+	if got.Rating != "0.9" {
+		t.Errorf("rating written by RetroArt not updated: %q", got.Rating)
+	}
+	if got.Developer != "Studio" {
+		t.Errorf("field added to an existing game lost: developer = %q", got.Developer)
+	}
+	if got.Name != "Alpha" || got.Favorite != "true" || got.ID != "7" {
+		t.Errorf("existing fields of Alpha lost: %+v", got)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "<System>SNES</System>") {
+		t.Errorf("unknown element lost:\n%s", data)
+	}
+	if got := entries["./Bravo.sfc"]; got.Name != "Bravo (hand written)" {
+		t.Errorf("game that was not scraped changed: %+v", got)
+	}
+	for i := range 20 {
+		key := fmt.Sprintf("./New %02d.sfc", i)
+		if got := entries[key]; got.Name != fmt.Sprintf("New %02d", i) || got.Rating != "0.7" {
+			t.Errorf("new game %s = %+v", key, got)
+		}
+	}
+}
+
+func TestGamelistExportUsesResultRegions(t *testing.T) {
+	romsRoot := t.TempDir()
+	systemDir := filepath.Join(romsRoot, "SNES")
+	if err := os.MkdirAll(systemDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	layout, err := NewLayout(LayoutTrimUI, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGamelist(NewWriter(layout, ""), romsRoot, t.TempDir(), scraper.MetadataPrefs{}, &scraper.RegionResolver{Order: []string{"us"}})
+
+	// The file name says nothing of the region; the DAT identified the ROM
+	// as European, which the engine recorded on the result
+	game := library.Game{Name: "Game", FileName: "Game.sfc", Path: filepath.Join(systemDir, "Game.sfc"), SystemID: "snes"}
+	result := &scraper.Result{Title: "Game", Regions: []string{"eu"}}
+	result.Metadata.Titles.Add("us", "Game US")
+	result.Metadata.Titles.Add("eu", "Game EU")
+
+	g.Export(game, result)
+	if err := g.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readEntries(t, filepath.Join(systemDir, GamelistFile))["./Game.sfc"].Name; got != "Game EU" {
+		t.Errorf("name = %q, want the title of the ROM region", got)
+	}
+}
@@ -0,0 +1,227 @@
+// Package output writes scraped artwork where each frontend expects it.
+//
+// A Layout maps a game and a media type to a destination path; the Writer
+// stores files atomically at that path honouring an overwrite policy.
+package output
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+// Built-in layout names accepted in the config
+const (
+	LayoutTrimUI           = "trimui"
+	LayoutMinUI            = "minui"
+	LayoutOnion            = "onion"
+	LayoutEmulationStation = "emulationstation"
+)
+
+// LayoutNames lists the built-in layouts in display order
+var LayoutNames = []string{LayoutTrimUI, LayoutMinUI, LayoutOnion, LayoutEmulationStation}
+
+// Layout maps a scraped game to the files a frontend reads
+type Layout interface {
+	// Name returns the layout name used in the config
+	Name() string
+
+	// Paths returns the candidate paths for the media type, the preferred
+	// one first. An empty result means the frontend has no slot for it.
+	Paths(game library.Game, mediaType scraper.MediaType, format string) []string
+
+	// Image returns how images of the media type are resized and encoded.
+	// False means the content is written as downloaded.
+	Image(mediaType scraper.MediaType) (imaging.Options, bool)
+
+	// Dirs returns every folder Paths may write to for the games of a
+	// system, which the artwork audit scans for files without a ROM
+	Dirs(system library.System) []string
+}
+
+// Options configures the built-in layouts
+type Options struct {
+	// ArtDir is the root for layouts that keep art outside the ROM folders
+	ArtDir string
+
+	// Primary is the media type shown by frontends with a single image
+	// per game
+	Primary scraper.MediaType
+}
+
+// NewLayout returns the built-in layout with the given name
+func NewLayout(name string, opts Options) (Layout, error) {
+	if opts.Primary == "" {
+		opts.Primary = scraper.MediaBoxFront
+	}
+
+	switch name {
+	case LayoutTrimUI:
+		// Roms/<system>/Imgs/<rom name>.png, shown on the right of the list
+		return romDirLayout{
+			name:    name,
+			dir:     "Imgs",
+			primary: opts.Primary,
+			image:   imaging.Options{Width: 500, Height: 500, Mode: imaging.ModeFit, Format: imaging.FormatPNG},
+		}, nil
+	case LayoutMinUI:
+		// Roms/<system>/.res/<rom file name>.png
+		return romDirLayout{
+			name:    name,
+			dir:     ".res",
+			keepExt: true,
+			primary: opts.Primary,
+			image:   imaging.Options{Width: 360, Height: 360, Mode: imaging.ModeFit, Format: imaging.FormatPNG},
+		}, nil
+	case LayoutOnion:
+		// Roms/<system>/Imgs/<rom name>.png, sized for the 640x480 screen
+		return romDirLayout{
+			name:    name,
+			dir:     "Imgs",
+			primary: opts.Primary,
+			image:   imaging.Options{Width: 250, Height: 360, Mode: imaging.ModeFit, Format: imaging.FormatPNG},
+		}, nil
+	case LayoutEmulationStation:
+		if opts.ArtDir == "" {
+			return nil, fmt.Errorf("layout %s needs an art directory", name)
+		}
+		return esLayout{root: filepath.Join(opts.ArtDir, "downloaded_media")}, nil
+	default:
+		return nil, fmt.Errorf("unknown output layout %q", name)
+	}
+}
+
+// romDirLayout stores one PNG per game in a folder next to the ROMs, as the
+// handheld frontends only show a single image per game
+type romDirLayout struct {
+	name    string
+	dir     string
+	keepExt bool // Name the image after the full ROM file name
+	primary scraper.MediaType
+	image   imaging.Options
+}
+
+func (l romDirLayout) Name() string {
+	return l.name
+}
+
+func (l romDirLayout) Paths(game library.Game, mediaType scraper.MediaType, format string) []string {
+	if mediaType != l.primary {
+		return nil
+	}
+
+	name := game.Name
+	if l.keepExt {
+		name = game.FileName
+	}
+	// The frontends load images through SDL_image, which detects the
+	// format from the content, so the extension is always .png
+	return []string{filepath.Join(filepath.Dir(game.Path), l.dir, name+".png")}
+}
+
+func (l romDirLayout) Image(mediaType scraper.MediaType) (imaging.Options, bool) {
+	return l.image, mediaType == l.primary
+}
+
+// Dirs includes the folder of each ROM subdirectory, as Paths puts the
+// image next to the ROM rather than at the system root
+func (l romDirLayout) Dirs(system library.System) []string {
+	dirs := []string{filepath.Join(system.Path, l.dir)}
+	for _, game := range system.Games {
+		dir := filepath.Join(filepath.Dir(game.Path), l.dir)
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// esMediaFolders maps media types to the EmulationStation-DE media folders
+var esMediaFolders = map[scraper.MediaType]string{
+	scraper.MediaBoxFront:   "covers",
+	scraper.MediaBoxBack:    "backcovers",
+	scraper.MediaBox3D:      "3dboxes",
+	scraper.MediaScreenshot: "screenshots",
+	scraper.MediaTitle:      "titlescreens",
+	scraper.MediaWheel:      "marquees",
+	scraper.MediaFanart:     "fanart",
+	scraper.MediaVideo:      "videos",
+	scraper.MediaMixed:      "miximages",
+}
+
+// esSystemNames lists the system IDs whose EmulationStation name differs
+var esSystemNames = map[string]string{
+	"vb":     "virtualboy",
+	"gw":     "gameandwatch",
+	"sg1000": "sg-1000",
+	"lynx":   "atarilynx",
+}
+
+// esImage keeps enough resolution for TV output while bounding the size of
+// 4K fanart and scans
+var esImage = imaging.Options{Width: 1280, Height: 1280, Mode: imaging.ModeFit, Format: imaging.FormatPNG}
+
+// esFormats are the extensions EmulationStation looks for, in order
+var esFormats = []string{"png", "jpg", "mp4"}
+
+// esLayout stores media in downloaded_media/<system>/<folder>/<rom name>.<ext>
+type esLayout struct {
+	root string
+}
+
+func (l esLayout) Name() string {
+	return LayoutEmulationStation
+}
+
+func (l esLayout) Paths(game library.Game, mediaType scraper.MediaType, format string) []string {
+	folder, ok := esMediaFolders[mediaType]
+	if !ok {
+		return nil
+	}
+
+	dir := filepath.Join(l.root, esSystemName(game.SystemID), folder)
+
+	format = strings.ToLower(format)
+	if format == "" {
+		format = "png"
+	}
+
+	paths := []string{filepath.Join(dir, game.Name+"."+format)}
+	for _, ext := range esFormats {
+		if ext != format {
+			paths = append(paths, filepath.Join(dir, game.Name+"."+ext))
+		}
+	}
+	return paths
+}
+
+func (l esLayout) Image(mediaType scraper.MediaType) (imaging.Options, bool) {
+	if mediaType == scraper.MediaVideo {
+		return imaging.Options{}, false
+	}
+	return esImage, true
+}
+
+func (l esLayout) Dirs(system library.System) []string {
+	dirs := make([]string, 0, len(esMediaFolders))
+	for _, folder := range esMediaFolders {
+		dirs = append(dirs, filepath.Join(l.root, esSystemName(system.ID()), folder))
+	}
+	sort.Strings(dirs)
+	return dirs
+}
+
+// esSystemName returns the EmulationStation folder name of a system
+func esSystemName(systemID string) string {
+	if name, ok := esSystemNames[systemID]; ok {
+		return name
+	}
+	return systemID
+}
@@ -0,0 +1,46 @@
+package output
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+func TestDirsCoverEveryPath(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "Roms", "PS")
+	system := library.System{
+		Definition: library.SystemDefinition{ID: "psx"},
+		Folder:     "PlayStation (PS)",
+		Path:       root,
+		Games: []library.Game{
+			{Name: "Alpha", FileName: "Alpha.chd", Path: filepath.Join(root, "Alpha.chd"), SystemID: "psx"},
+			{Name: "Bravo", FileName: "Bravo.cue", Path: filepath.Join(root, "Bravo", "Bravo.cue"), SystemID: "psx"},
+			{Name: "Charlie", FileName: "Charlie.cue", Path: filepath.Join(root, "Bravo", "Charlie.cue"), SystemID: "psx"},
+		},
+	}
+
+	for _, name := range LayoutNames {
+		t.Run(name, func(t *testing.T) {
+			layout, err := NewLayout(name, Options{ArtDir: t.TempDir()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			dirs := layout.Dirs(system)
+			for _, game := range system.Games {
+				for _, mediaType := range append(slices.Clone(scraper.AllMediaTypes), scraper.MediaMixed) {
+					for _, path := range layout.Paths(game, mediaType, "png") {
+						if !slices.Contains(dirs, filepath.Dir(path)) {
+							t.Errorf("Dirs = %v, missing the folder of %s", dirs, path)
+						}
+					}
+				}
+			}
+			if len(slices.Compact(slices.Clone(dirs))) != len(dirs) {
+				t.Errorf("Dirs = %v, want no duplicates", dirs)
+			}
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"retroart-sdl2/internal/config"
+	"retroart-sdl2/internal/fsutil"
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/job"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+// Writer stores media following a Layout and an overwrite policy. Images are
+// resized and re-encoded to the layout target. It implements job.Sink.
+//
+// Policies:
+//   - never: existing files are never replaced
+//   - always: existing files are always replaced
+//   - only-if-smaller: an existing file is replaced when it is smaller than
+//     the downloaded one, e.g. a low resolution thumbnail
+//   - only-if-missing: only missing or empty files are written
+type Writer struct {
+	Layout    Layout
+	Overwrite config.OverwritePolicy
+}
+
+// NewWriter creates a writer for the layout
+func NewWriter(layout Layout, overwrite config.OverwritePolicy) *Writer {
+	if !overwrite.Valid() {
+		overwrite = config.OverwriteIfMissing
+	}
+	return &Writer{Layout: layout, Overwrite: overwrite}
+}
+
+// Exists reports whether the media type needs no download: either the
+// layout has no slot for it or the policy keeps the existing file. With
+// only-if-smaller the new media must be fetched to compare sizes; the job
+// engine records the outcome in the scrape database, so an existing file is
+// only compared once per source.
+func (w *Writer) Exists(game library.Game, mediaType scraper.MediaType) bool {
+	paths := w.Layout.Paths(game, mediaType, "")
+	if len(paths) == 0 {
+		return true
+	}
+
+	_, info := existing(paths)
+	if info == nil {
+		return false
+	}
+
+	switch w.Overwrite {
+	case config.OverwriteAlways, config.OverwriteIfSmaller:
+		return false
+	case config.OverwriteIfMissing:
+		return info.Size() > 0
+	default:
+		return true
+	}
+}
+
+// Find returns the stored file of a media type, if any
+func (w *Writer) Find(game library.Game, mediaType scraper.MediaType) (string, bool) {
+	path, info := existing(w.Layout.Paths(game, mediaType, ""))
+	return path, info != nil
+}
+
+// Write stores content atomically at the layout path. It returns
+// job.ErrKept when the policy keeps the existing file.
+func (w *Writer) Write(ctx context.Context, game library.Game, media scraper.Media, content io.Reader) (string, error) {
+	format := media.Format
+	imageOpts, process := w.Layout.Image(media.Type)
+	if process {
+		format = imageOpts.Format.Ext()
+	}
+
+	paths := w.Layout.Paths(game, media.Type, format)
+	if len(paths) == 0 {
+		return "", fmt.Errorf("layout %s has no slot for %s", w.Layout.Name(), media.Type)
+	}
+	target := paths[0]
+
+	oldPath, oldInfo := existing(paths)
+	if oldInfo != nil {
+		switch w.Overwrite {
+		case config.OverwriteNever:
+			return oldPath, job.ErrKept
+		case config.OverwriteIfMissing:
+			if oldInfo.Size() > 0 {
+				return oldPath, job.ErrKept
+			}
+		}
+	}
+
+	file, err := fsutil.CreateAtomic(target, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", target, err)
+	}
+	defer file.Abort()
+
+	if process {
+		if err := imaging.Transform(file, content, imageOpts); err != nil {
+			return "", fmt.Errorf("failed to convert %s: %w", media.Type, err)
+		}
+	} else if _, err := io.Copy(file, content); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", target, err)
+	}
+
+	size, err := file.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", target, err)
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	if oldInfo != nil && w.Overwrite == config.OverwriteIfSmaller && oldInfo.Size() >= size {
+		return oldPath, job.ErrKept
+	}
+
+	if err := file.Commit(); err != nil {
+		return "", fmt.Errorf("failed to save %s: %w", target, err)
+	}
+
+	// A file with another extension would shadow or duplicate the new one
+	if oldInfo != nil && oldPath != target {
+		os.Remove(oldPath)
+	}
+	return target, nil
+}
+
+// existing returns the first path that exists
+func existing(paths []string) (string, os.FileInfo) {
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, info
+		}
+	}
+	return "", nil
+}
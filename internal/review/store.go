@@ -0,0 +1,235 @@
+// Package review keeps the games that need a manual match and the decisions
+// taken for them in the review screen, so later runs reuse them.
+package review
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"retroart-sdl2/internal/fsutil"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+// storeVersion is bumped when the file format changes incompatibly
+const storeVersion = 1
+
+// Action is what the user decided for a game
+type Action string
+
+const (
+	ActionMatch Action = "match" // Use Decision.Result
+	ActionSkip  Action = "skip"  // Never scrape the game again
+)
+
+// Decision is a choice taken in the review screen
+type Decision struct {
+	Action Action          `json:"action"`
+	Result *scraper.Result `json:"result,omitempty"`
+	Time   time.Time       `json:"time"`
+}
+
+// Item is a game waiting for review
+type Item struct {
+	Game       library.Game     `json:"game"`
+	System     string           `json:"system"` // Display name of the system
+	Reason     string           `json:"reason"`
+	Candidates []scraper.Result `json:"candidates,omitempty"`
+	Time       time.Time        `json:"time"`
+}
+
+type storeData struct {
+	Version   int                 `json:"version"`
+	Decisions map[string]Decision `json:"decisions"`
+	Pending   map[string]Item     `json:"pending"`
+}
+
+// redacted returns a copy of the data without credentials in the media
+// URLs of the results, and whether any were found
+func (d storeData) redacted() (storeData, bool) {
+	out := storeData{
+		Version:   d.Version,
+		Decisions: make(map[string]Decision, len(d.Decisions)),
+		Pending:   make(map[string]Item, len(d.Pending)),
+	}
+	found := false
+	for key, decision := range d.Decisions {
+		if result := decision.Result.Redacted(); result != decision.Result {
+			decision.Result = result
+			found = true
+		}
+		out.Decisions[key] = decision
+	}
+	for key, item := range d.Pending {
+		var candidates []scraper.Result
+		for i := range item.Candidates {
+			if result := item.Candidates[i].Redacted(); result != &item.Candidates[i] {
+				if candidates == nil {
+					candidates = slices.Clone(item.Candidates)
+				}
+				candidates[i] = *result
+			}
+		}
+		if candidates != nil {
+			item.Candidates = candidates
+			found = true
+		}
+		out.Pending[key] = item
+	}
+	return out, found
+}
+
+// Store holds pending items and decisions. It is safe for concurrent use.
+type Store struct {
+	path  string
+	mu    sync.Mutex
+	data  storeData
+	dirty bool
+}
+
+// Key identifies a game across runs, independently of the ROMs root
+func Key(game library.Game) string {
+	return game.SystemID + "/" + game.FileName
+}
+
+// Load reads the store at path; a missing file yields an empty store
+func Load(path string) (*Store, error) {
+	store := &Store{path: path}
+	store.reset()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return store, nil
+		}
+		return store, fmt.Errorf("failed to read review store: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &store.data); err != nil || store.data.Version != storeVersion {
+		store.reset()
+		return store, fmt.Errorf("ignoring review store %s: unsupported or corrupt file", path)
+	}
+	if store.data.Decisions == nil {
+		store.data.Decisions = make(map[string]Decision)
+	}
+	if store.data.Pending == nil {
+		store.data.Pending = make(map[string]Item)
+	}
+	// Files of older versions kept the credentials in the media URLs; the
+	// next save rewrites them without
+	if redacted, found := store.data.redacted(); found {
+		store.data = redacted
+		store.dirty = true
+	}
+	return store, nil
+}
+
+func (s *Store) reset() {
+	s.data = storeData{
+		Version:   storeVersion,
+		Decisions: make(map[string]Decision),
+		Pending:   make(map[string]Item),
+	}
+}
+
+// Resolution implements job.Decisions
+func (s *Store) Resolution(game library.Game) (*scraper.Result, bool, bool) {
+	decision, ok := s.Decision(game)
+	if !ok {
+		return nil, false, false
+	}
+	if decision.Action == ActionSkip {
+		return nil, true, true
+	}
+	return decision.Result, false, decision.Result != nil
+}
+
+// Decision returns the decision taken for a game
+func (s *Store) Decision(game library.Game) (Decision, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	decision, ok := s.data.Decisions[Key(game)]
+	return decision, ok
+}
+
+// Decide records a decision and removes the game from the pending list
+func (s *Store) Decide(game library.Game, decision Decision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if decision.Time.IsZero() {
+		decision.Time = time.Now()
+	}
+	key := Key(game)
+	s.data.Decisions[key] = decision
+	delete(s.data.Pending, key)
+	s.dirty = true
+}
+
+// Flag adds a game to the pending list, unless a decision already exists
+func (s *Store) Flag(item Item) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := Key(item.Game)
+	if _, decided := s.data.Decisions[key]; decided {
+		return
+	}
+	if item.Time.IsZero() {
+		item.Time = time.Now()
+	}
+	s.data.Pending[key] = item
+	s.dirty = true
+}
+
+// Unflag removes a game from the pending list, e.g. after it matched
+func (s *Store) Unflag(game library.Game) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := Key(game)
+	if _, ok := s.data.Pending[key]; ok {
+		delete(s.data.Pending, key)
+		s.dirty = true
+	}
+}
+
+// Pending returns the games waiting for review sorted by system and name
+func (s *Store) Pending() []Item {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]Item, 0, len(s.data.Pending))
+	for _, item := range s.data.Pending {
+		items = append(items, item)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].System != items[j].System {
+			return items[i].System < items[j].System
+		}
+		return items[i].Game.Name < items[j].Game.Name
+	})
+	return items
+}
+
+// Save writes the store if it changed since the last save
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.dirty || s.path == "" {
+		return nil
+	}
+
+	redacted, _ := s.data.redacted()
+	data, err := json.Marshal(redacted)
+	if err != nil {
+		return fmt.Errorf("failed to encode review store: %w", err)
+	}
+	if err := fsutil.WriteFileAtomic(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to save review store: %w", err)
+	}
+	s.dirty = false
+	return nil
+}
@@ -0,0 +1,75 @@
+package review
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+)
+
+const secretURL = "https://neoclone.screenscraper.fr/api2/mediaJeu.php?devid=dev&devpassword=devsecret&ssid=user&sspassword=usersecret&media=box-2D"
+
+func secretResult(id string) scraper.Result {
+	return scraper.Result{Provider: "screenscraper", ID: id, Media: []scraper.Media{{Type: scraper.MediaBoxFront, URL: secretURL}}}
+}
+
+func assertNoSecrets(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"devsecret", "usersecret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("review store contains %q", secret)
+		}
+	}
+	if !strings.Contains(string(data), "media=box-2D") {
+		t.Errorf("review store lost the media URLs: %s", data)
+	}
+}
+
+func TestSaveStoresNoCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decided := secretResult("1")
+	store.Decide(library.Game{SystemID: "snes", FileName: "a.sfc"}, Decision{Action: ActionMatch, Result: &decided})
+	store.Flag(Item{Game: library.Game{SystemID: "snes", FileName: "b.sfc"}, Candidates: []scraper.Result{secretResult("2")}})
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	assertNoSecrets(t, path)
+	if decided.Media[0].URL != secretURL {
+		t.Error("Save changed the result of the caller")
+	}
+}
+
+func TestLoadRewritesStoredCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "review.json")
+	old := `{"version":1,"decisions":{"snes/a.sfc":{"action":"match","result":{"Provider":"screenscraper","ID":"1",` +
+		`"Media":[{"Type":"box-front","URL":"` + secretURL + `"}]}}},"pending":{}}`
+	if err := os.WriteFile(path, []byte(old), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(); err != nil {
+		t.Fatal(err)
+	}
+	assertNoSecrets(t, path)
+
+	result, skip, ok := store.Resolution(library.Game{SystemID: "snes", FileName: "a.sfc"})
+	if !ok || skip || result.ID != "1" {
+		t.Errorf("Resolution = %+v, %v, %v, want the recorded match", result, skip, ok)
+	}
+}
@@ -0,0 +1,385 @@
+// Package scrapedb is the local scrape database. For every ROM hash it
+// remembers the provider match with its metadata and the media files that
+// were written, with their source URL, validators, size and time, so later
+// runs can skip lookups and downloads that were already done.
+//
+// The database is a single append-only log file (see log.go) replayed into
+// memory on open, and rewritten from memory by Compact. Appends are synced
+// in small batches, so a power cut loses at most the last few records; a
+// record torn by the cut is cut off on the next open.
+package scrapedb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"retroart-sdl2/internal/fsutil"
+)
+
+// ErrNewerSchema is returned by Open for databases written by a newer
+// version of RetroArt
+var ErrNewerSchema = errors.New("scrape database written by a newer version")
+
+// compactMinGarbage is the number of obsolete records below which the log
+// is never worth compacting
+const compactMinGarbage = 512
+
+// Appended records are synced once syncBatch of them are pending, or when
+// the oldest pending one is syncInterval old
+const (
+	syncBatch    = 16
+	syncInterval = 2 * time.Second
+)
+
+// DB is an open scrape database. It is safe for concurrent use.
+type DB struct {
+	path    string
+	mu      sync.Mutex
+	file    *os.File
+	size    int64 // Size of the log, to undo failed appends
+	entries map[string]Entry
+	records int // Put and delete records in the log
+	leaked  int // Replayed entries that held credentials in their URLs
+
+	unsynced    int       // Records appended since the last sync
+	unsyncedAge time.Time // When the oldest unsynced record was appended
+}
+
+// Open loads the database at path, creating it when missing. Corrupt
+// records are skipped and a torn last record is truncated.
+func Open(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create scrape database dir: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open scrape database: %w", err)
+	}
+
+	db := &DB{
+		path:    path,
+		file:    file,
+		entries: make(map[string]Entry),
+	}
+
+	version, err := db.load()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	switch {
+	case version == 0:
+		// New or empty file
+		if err := db.append(record{Op: opSchema, Version: SchemaVersion}); err != nil {
+			file.Close()
+			return nil, err
+		}
+	case version < SchemaVersion:
+		log.Printf("ScrapeDB: migrated %d entries from schema version %d", len(db.entries), version)
+		if err := db.Compact(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	case db.leaked > 0:
+		// Rewrite the log so the credentials are gone from the disk
+		log.Printf("ScrapeDB: removing credentials from %d entries", db.leaked)
+		if err := db.Compact(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// load replays the log and returns its schema version, zero for an empty log
+func (db *DB) load() (int, error) {
+	lr := newLogReader(db.file)
+	version, corrupt := 0, 0
+
+	for {
+		rec, err := lr.next()
+		if err == io.EOF {
+			break
+		}
+		if err == io.ErrUnexpectedEOF {
+			log.Printf("ScrapeDB: truncating torn record at offset %d of %s", lr.offset, db.path)
+			if err := db.file.Truncate(lr.offset); err != nil {
+				return 0, fmt.Errorf("failed to truncate scrape database: %w", err)
+			}
+			break
+		}
+		if errors.Is(err, errCorrupt) {
+			corrupt++
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read scrape database: %w", err)
+		}
+
+		if version == 0 {
+			if rec.Op != opSchema || rec.Version <= 0 {
+				return 0, fmt.Errorf("scrape database %s has no schema header", db.path)
+			}
+			if rec.Version > SchemaVersion {
+				return 0, fmt.Errorf("%w (schema version %d)", ErrNewerSchema, rec.Version)
+			}
+			version = rec.Version
+			continue
+		}
+
+		if err := db.replay(version, rec); err != nil {
+			corrupt++
+			continue
+		}
+		db.records++
+	}
+
+	if corrupt > 0 {
+		log.Printf("ScrapeDB: skipped %d corrupt records in %s", corrupt, db.path)
+		// Counted as garbage so the next compaction drops them
+		db.records += corrupt
+	}
+	db.size = lr.offset
+	return version, nil
+}
+
+// replay applies a record read from a log with the given schema version
+func (db *DB) replay(version int, rec record) error {
+	switch rec.Op {
+	case opPut:
+		data, err := migrate(version, rec.Entry)
+		if err != nil {
+			return err
+		}
+		var entry Entry
+		if err := json.Unmarshal(data, &entry); err != nil || entry.Key == "" {
+			return errCorrupt
+		}
+		entry, leaked := entry.redacted()
+		if leaked {
+			db.leaked++
+		}
+		db.entries[entry.Key] = entry
+	case opDelete:
+		delete(db.entries, rec.Key)
+	default:
+		return errCorrupt
+	}
+	return nil
+}
+
+// Get returns the entry of a key
+func (db *DB) Get(key string) (Entry, bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	entry, ok := db.entries[key]
+	return entry.clone(), ok
+}
+
+// Update changes the entry of a key, creating it when missing, and appends
+// it to the log
+func (db *DB) Update(key string, fn func(entry *Entry)) error {
+	if key == "" {
+		return errors.New("empty scrape database key")
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	entry, ok := db.entries[key]
+	if ok {
+		entry = entry.clone()
+	} else {
+		entry = Entry{Key: key}
+	}
+	fn(&entry)
+	entry, _ = entry.redacted()
+	entry.Key = key
+	entry.Updated = time.Now()
+
+	if err := db.appendEntry(entry); err != nil {
+		return err
+	}
+	db.entries[key] = entry
+	return nil
+}
+
+// Delete removes the entry of a key
+func (db *DB) Delete(key string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.entries[key]; !ok {
+		return nil
+	}
+	if err := db.append(record{Op: opDelete, Key: key}); err != nil {
+		return err
+	}
+	delete(db.entries, key)
+	db.records++
+	return nil
+}
+
+// Len returns the number of entries
+func (db *DB) Len() int {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return len(db.entries)
+}
+
+// NeedsCompaction reports whether most of the log is obsolete records
+func (db *DB) NeedsCompaction() bool {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	garbage := db.records - len(db.entries)
+	return garbage >= compactMinGarbage && garbage > len(db.entries)
+}
+
+// Compact rewrites the log with one record per entry, sorted by key. The
+// new log replaces the old one atomically.
+func (db *DB) Compact() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	out, err := fsutil.CreateAtomic(db.path, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to compact scrape database: %w", err)
+	}
+	defer out.Abort()
+
+	keys := make([]string, 0, len(db.entries))
+	for key := range db.entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var size int64
+	write := func(rec record) error {
+		line, err := encodeRecord(rec)
+		if err != nil {
+			return err
+		}
+		n, err := out.Write(line)
+		size += int64(n)
+		return err
+	}
+
+	if err := write(record{Op: opSchema, Version: SchemaVersion}); err != nil {
+		return fmt.Errorf("failed to compact scrape database: %w", err)
+	}
+	for _, key := range keys {
+		data, err := json.Marshal(db.entries[key])
+		if err != nil {
+			return fmt.Errorf("failed to encode scrape database entry: %w", err)
+		}
+		if err := write(record{Op: opPut, Entry: data}); err != nil {
+			return fmt.Errorf("failed to compact scrape database: %w", err)
+		}
+	}
+	if err := out.Commit(); err != nil {
+		return fmt.Errorf("failed to replace scrape database: %w", err)
+	}
+
+	// The old handle points to the replaced file
+	db.file.Close()
+	file, err := os.OpenFile(db.path, os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		db.file = nil
+		return fmt.Errorf("failed to reopen scrape database: %w", err)
+	}
+	db.file = file
+	db.size = size
+	db.records = len(keys)
+	db.unsynced = 0
+	log.Printf("ScrapeDB: compacted %s to %d entries", db.path, len(keys))
+	return nil
+}
+
+// Sync flushes appended records to disk
+func (db *DB) Sync() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.file == nil {
+		return os.ErrClosed
+	}
+	return db.sync()
+}
+
+// sync flushes the log; db.mu must be held
+func (db *DB) sync() error {
+	if err := db.file.Sync(); err != nil {
+		return err
+	}
+	db.unsynced = 0
+	return nil
+}
+
+// Close syncs and closes the database
+func (db *DB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.file == nil {
+		return nil
+	}
+	err := db.file.Sync()
+	if closeErr := db.file.Close(); err == nil {
+		err = closeErr
+	}
+	db.file = nil
+	return err
+}
+
+func (db *DB) appendEntry(entry Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode scrape database entry: %w", err)
+	}
+	if err := db.append(record{Op: opPut, Entry: data}); err != nil {
+		return err
+	}
+	db.records++
+	return nil
+}
+
+// append writes a record at the end of the log. A failed write is cut off
+// so the next record starts on a fresh line.
+func (db *DB) append(rec record) error {
+	if db.file == nil {
+		return os.ErrClosed
+	}
+
+	line, err := encodeRecord(rec)
+	if err != nil {
+		return fmt.Errorf("failed to encode scrape database record: %w", err)
+	}
+
+	n, err := db.file.Write(line)
+	if err != nil {
+		if n > 0 {
+			db.file.Truncate(db.size)
+		}
+		return fmt.Errorf("failed to write scrape database: %w", err)
+	}
+	db.size += int64(n)
+
+	if db.unsynced == 0 {
+		db.unsyncedAge = time.Now()
+	}
+	db.unsynced++
+	if db.unsynced >= syncBatch || time.Since(db.unsyncedAge) >= syncInterval {
+		if err := db.sync(); err != nil {
+			return fmt.Errorf("failed to sync scrape database: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,298 @@
+package scrapedb
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"retroart-sdl2/internal/scraper"
+)
+
+func openTemp(t *testing.T) (*DB, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scrape.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	return db, path
+}
+
+func reopen(t *testing.T, db *DB, path string) *DB {
+	t.Helper()
+	if err := db.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestReopenReplaysLog(t *testing.T) {
+	db, path := openTemp(t)
+
+	must(t, db.Update("sha1:a", func(entry *Entry) {
+		entry.SystemID = "snes"
+		entry.Match = &scraper.Result{Provider: "screenscraper", ID: "1", Title: "Game A"}
+	}))
+	must(t, db.Update("sha1:b", func(entry *Entry) { entry.SystemID = "nes" }))
+	must(t, db.Update("sha1:a", func(entry *Entry) {
+		entry.Media = map[scraper.MediaType]MediaFile{
+			scraper.MediaBoxFront: {Path: "/art/a.png", URL: "https://example.com/a.png", ETag: `"v1"`, Size: 42},
+		}
+	}))
+	must(t, db.Delete("sha1:b"))
+
+	db = reopen(t, db, path)
+
+	if db.Len() != 1 {
+		t.Fatalf("Len = %d after reopen, want 1", db.Len())
+	}
+	entry, ok := db.Get("sha1:a")
+	if !ok {
+		t.Fatal("entry sha1:a lost after reopen")
+	}
+	if entry.Match == nil || entry.Match.Title != "Game A" {
+		t.Errorf("match = %+v, want Game A", entry.Match)
+	}
+	if media := entry.Media[scraper.MediaBoxFront]; media.ETag != `"v1"` || media.Size != 42 {
+		t.Errorf("media = %+v, want the recorded box front", media)
+	}
+	if _, ok := db.Get("sha1:b"); ok {
+		t.Error("deleted entry came back after reopen")
+	}
+}
+
+func TestOpenTruncatesTornTail(t *testing.T) {
+	db, path := openTemp(t)
+	must(t, db.Update("sha1:a", func(entry *Entry) { entry.FileName = "a.sfc" }))
+	must(t, db.Close())
+
+	intact, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A power cut in the middle of an append leaves a line without newline
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	line, err := encodeRecord(record{Op: opPut, Entry: json.RawMessage(`{"key":"sha1:b"}`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.Write(line[:len(line)/2])
+	file.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open with torn tail: %v", err)
+	}
+	if info, _ := os.Stat(path); info.Size() != intact.Size() {
+		t.Errorf("size after open = %d, want the torn record cut off at %d", info.Size(), intact.Size())
+	}
+	if _, ok := db.Get("sha1:b"); ok {
+		t.Error("torn record was replayed")
+	}
+
+	// Appends continue on a fresh line
+	must(t, db.Update("sha1:c", func(entry *Entry) { entry.FileName = "c.sfc" }))
+	db = reopen(t, db, path)
+	for _, key := range []string{"sha1:a", "sha1:c"} {
+		if _, ok := db.Get(key); !ok {
+			t.Errorf("entry %s missing after reopen", key)
+		}
+	}
+}
+
+func TestOpenSkipsCorruptRecords(t *testing.T) {
+	db, path := openTemp(t)
+	must(t, db.Update("sha1:a", func(entry *Entry) {}))
+	must(t, db.Close())
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	file.WriteString("00000000 {\"op\":\"put\",\"entry\":{\"key\":\"sha1:bad\"}}\n")
+	file.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	if _, ok := db.Get("sha1:bad"); ok {
+		t.Error("record with a wrong checksum was replayed")
+	}
+	if _, ok := db.Get("sha1:a"); !ok {
+		t.Error("valid record lost next to a corrupt one")
+	}
+}
+
+func TestOpenMigratesVersion1(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrape.db")
+
+	v1 := `{"key":"sha1:a","system":"snes","match":{"Provider":"screenscraper","ID":"9","Title":"Game A",` +
+		`"Rating":0.8,"Description":"A game.","ReleaseDate":"1992-08-01","Developer":"Dev","Publisher":"Pub",` +
+		`"Genres":["Platform","Action"],"Players":"1-2"}}`
+	var log []byte
+	for _, rec := range []record{
+		{Op: opSchema, Version: 1},
+		{Op: opPut, Entry: json.RawMessage(v1)},
+	} {
+		line, err := encodeRecord(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		log = append(log, line...)
+	}
+	if err := os.WriteFile(path, log, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	db = reopen(t, db, path)
+
+	entry, ok := db.Get("sha1:a")
+	if !ok || entry.Match == nil {
+		t.Fatal("migrated entry or its match is missing")
+	}
+	metadata := entry.Match.Metadata
+	if metadata.Rating != 0.8 || metadata.Developer != "Dev" || metadata.Publisher != "Pub" || metadata.Players != "1-2" {
+		t.Errorf("metadata = %+v, want the version 1 fields", metadata)
+	}
+	if len(metadata.Synopsis) != 1 || metadata.Synopsis[0].Text != "A game." {
+		t.Errorf("synopsis = %+v, want the version 1 description", metadata.Synopsis)
+	}
+	if len(metadata.Genres) != 2 {
+		t.Errorf("genres = %+v, want 2", metadata.Genres)
+	}
+
+	// The log was rewritten with the current schema
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	header, err := newLogReader(file).next()
+	if err != nil || header.Op != opSchema || header.Version != SchemaVersion {
+		t.Errorf("header = %+v, %v, want schema version %d", header, err, SchemaVersion)
+	}
+}
+
+func TestOpenRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrape.db")
+	line, err := encodeRecord(record{Op: opSchema, Version: SchemaVersion + 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, line, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Open(path); err == nil {
+		t.Fatal("Open accepted a database of a newer schema")
+	}
+}
+
+func TestCompactKeepsEntries(t *testing.T) {
+	db, path := openTemp(t)
+	for range 5 {
+		must(t, db.Update("sha1:a", func(entry *Entry) { entry.FileName += "a" }))
+	}
+	must(t, db.Compact())
+	must(t, db.Update("sha1:b", func(entry *Entry) {}))
+
+	db = reopen(t, db, path)
+	if entry, _ := db.Get("sha1:a"); entry.FileName != "aaaaa" {
+		t.Errorf("FileName = %q after compaction, want the last update", entry.FileName)
+	}
+	if db.Len() != 2 {
+		t.Errorf("Len = %d, want 2", db.Len())
+	}
+}
+
+const secretURL = "https://neoclone.screenscraper.fr/api2/mediaJeu.php?devid=dev&devpassword=devsecret&ssid=user&sspassword=usersecret&media=box-2D"
+
+func assertNoSecrets(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"devsecret", "usersecret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("scrape database contains %q", secret)
+		}
+	}
+}
+
+func TestUpdateStoresNoCredentials(t *testing.T) {
+	db, path := openTemp(t)
+	match := &scraper.Result{Provider: "screenscraper", ID: "1", Media: []scraper.Media{{Type: scraper.MediaBoxFront, URL: secretURL}}}
+	must(t, db.Update("sha1:a", func(entry *Entry) {
+		entry.Match = match
+		entry.Media = map[scraper.MediaType]MediaFile{scraper.MediaBoxFront: {Path: "/art/a.png", URL: secretURL}}
+	}))
+	must(t, db.Sync())
+	assertNoSecrets(t, path)
+
+	if match.Media[0].URL != secretURL {
+		t.Error("Update changed the match of the caller")
+	}
+	entry, _ := db.Get("sha1:a")
+	if got := entry.Match.Media[0].URL; !strings.Contains(got, "media=box-2D") || strings.Contains(got, "devid") {
+		t.Errorf("stored media URL = %q, want it without credentials", got)
+	}
+	if got := entry.Media[scraper.MediaBoxFront].URL; got != entry.Match.Media[0].URL {
+		t.Errorf("stored file URL = %q, want the redacted media URL", got)
+	}
+}
+
+func TestOpenRemovesStoredCredentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "scrape.db")
+	entry := `{"key":"sha1:a","system":"snes","match":{"Provider":"screenscraper","ID":"1",` +
+		`"Media":[{"Type":"box-front","URL":"` + secretURL + `"}]},` +
+		`"media":{"box-front":{"path":"/art/a.png","url":"` + secretURL + `","size":1}}}`
+	var log []byte
+	for _, rec := range []record{
+		{Op: opSchema, Version: SchemaVersion},
+		{Op: opPut, Entry: json.RawMessage(entry)},
+	} {
+		line, err := encodeRecord(rec)
+		if err != nil {
+			t.Fatal(err)
+		}
+		log = append(log, line...)
+	}
+	if err := os.WriteFile(path, log, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer db.Close()
+	assertNoSecrets(t, path)
+	if got, _ := db.Get("sha1:a"); got.Media[scraper.MediaBoxFront].Path != "/art/a.png" {
+		t.Errorf("entry = %+v, want it kept", got)
+	}
+}
+
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
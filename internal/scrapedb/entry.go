@@ -0,0 +1,93 @@
+package scrapedb
+
+import (
+	"strconv"
+	"time"
+
+	"retroart-sdl2/internal/scraper"
+)
+
+// Entry is everything known about a ROM, keyed by its hash
+type Entry struct {
+	Key      string `json:"key"`
+	SystemID string `json:"system"`
+	FileName string `json:"file"` // Last file name seen with this hash
+
+	// Match is the provider result the ROM was identified as, including
+	// its metadata and the media offered by the provider
+	Match *scraper.Result `json:"match,omitempty"`
+
+	// Matched is when Match was last looked up; zero for entries written
+	// before it was recorded, which fall back to Updated
+	Matched time.Time `json:"matched,omitzero"`
+
+	// NotFound is when every provider last failed to identify the ROM;
+	// zero once a match is recorded
+	NotFound time.Time `json:"not_found"`
+
+	Media   map[scraper.MediaType]MediaFile `json:"media,omitempty"`
+	Updated time.Time                       `json:"updated"`
+}
+
+// MediaFile records where a media file came from and where it was written
+type MediaFile struct {
+	Path         string    `json:"path"`
+	URL          string    `json:"url,omitempty"` // Empty for locally composed media
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	Size         int64     `json:"size"` // Size of the written file
+	Time         time.Time `json:"time"`
+}
+
+// Key returns the database key of a query: its SHA1, or CRC32 and size when
+// only those are known. It is empty for games that could not be hashed.
+func Key(query scraper.Query) string {
+	switch {
+	case query.SHA1 != "":
+		return "sha1:" + query.SHA1
+	case query.CRC32 != "":
+		return "crc32:" + query.CRC32 + ":" + strconv.FormatInt(query.Size, 10)
+	default:
+		return ""
+	}
+}
+
+// MatchedAt returns when the match was last looked up
+func (e Entry) MatchedAt() time.Time {
+	if e.Matched.IsZero() {
+		return e.Updated
+	}
+	return e.Matched
+}
+
+// redacted returns the entry without credentials in its media URLs and
+// whether any were found. The media map and a match holding credentials
+// are copied, since callers keep using theirs.
+func (e Entry) redacted() (Entry, bool) {
+	e = e.clone()
+	found := false
+	for mediaType, file := range e.Media {
+		if url := scraper.RedactURL(file.URL); url != file.URL {
+			file.URL = url
+			e.Media[mediaType] = file
+			found = true
+		}
+	}
+	if match := e.Match.Redacted(); match != e.Match {
+		e.Match = match
+		found = true
+	}
+	return e, found
+}
+
+// clone returns a copy that does not share the media map
+func (e Entry) clone() Entry {
+	if e.Media != nil {
+		media := make(map[scraper.MediaType]MediaFile, len(e.Media))
+		for mediaType, file := range e.Media {
+			media[mediaType] = file
+		}
+		e.Media = media
+	}
+	return e
+}
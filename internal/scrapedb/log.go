@@ -0,0 +1,102 @@
+package scrapedb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"strconv"
+)
+
+// The database file is a log of JSON records, one per line, each prefixed
+// by the CRC32 of its JSON:
+//
+//	1a2b3c4d {"op":"schema","version":1}
+//	5e6f7a8b {"op":"put","entry":{...}}
+//	9c0d1e2f {"op":"del","key":"sha1:..."}
+//
+// Records are only ever appended, so a crash can at worst leave a torn last
+// line, which fails its checksum and is cut off on the next open.
+
+// Operations of a record
+const (
+	opSchema = "schema"
+	opPut    = "put"
+	opDelete = "del"
+)
+
+// record is a line of the log
+type record struct {
+	Op      string          `json:"op"`
+	Version int             `json:"version,omitempty"`
+	Key     string          `json:"key,omitempty"`
+	Entry   json.RawMessage `json:"entry,omitempty"`
+}
+
+// errCorrupt means a line failed its checksum or could not be decoded
+var errCorrupt = errors.New("corrupt record")
+
+// encodeRecord returns the log line of a record, including the newline
+func encodeRecord(rec record) ([]byte, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	line := make([]byte, 0, len(data)+10)
+	line = fmt.Appendf(line, "%08x ", crc32.ChecksumIEEE(data))
+	line = append(line, data...)
+	return append(line, '\n'), nil
+}
+
+// decodeRecord parses a log line without its newline
+func decodeRecord(line []byte) (record, error) {
+	var rec record
+	sum, data, ok := bytes.Cut(line, []byte{' '})
+	if !ok || len(sum) != 8 {
+		return rec, errCorrupt
+	}
+
+	want, err := strconv.ParseUint(string(sum), 16, 32)
+	if err != nil || uint32(want) != crc32.ChecksumIEEE(data) {
+		return rec, errCorrupt
+	}
+
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, errCorrupt
+	}
+	return rec, nil
+}
+
+// logReader reads records and tracks the offset of the last complete line,
+// so a torn tail can be truncated
+type logReader struct {
+	r      *bufio.Reader
+	offset int64 // End of the last complete line
+}
+
+func newLogReader(r io.Reader) *logReader {
+	return &logReader{r: bufio.NewReaderSize(r, 64*1024)}
+}
+
+// next returns the next record. A corrupt complete line returns errCorrupt
+// and reading can continue; a line without newline is a torn write and ends
+// the log with io.ErrUnexpectedEOF.
+func (lr *logReader) next() (record, error) {
+	line, err := lr.r.ReadBytes('\n')
+	if err == io.EOF {
+		if len(line) == 0 {
+			return record{}, io.EOF
+		}
+		return record{}, io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return record{}, err
+	}
+
+	lr.offset += int64(len(line))
+	return decodeRecord(line[:len(line)-1])
+}
@@ -0,0 +1,78 @@
+package scrapedb
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"retroart-sdl2/internal/scraper"
+)
+
+// SchemaVersion is the version of the entries written by this build. Bump
+// it and add a migration whenever Entry changes incompatibly.
+const SchemaVersion = 2
+
+// migration upgrades an encoded entry from version v to v+1
+type migration func(entry json.RawMessage) (json.RawMessage, error)
+
+// migrations holds the upgrade from each old version to the next one
+var migrations = map[int]migration{
+	1: migrateMetadata,
+}
+
+// migrate upgrades an entry written with schema version to SchemaVersion
+func migrate(version int, entry json.RawMessage) (json.RawMessage, error) {
+	for v := version; v < SchemaVersion; v++ {
+		upgrade, ok := migrations[v]
+		if !ok {
+			return nil, fmt.Errorf("no migration from schema version %d", v)
+		}
+		var err error
+		if entry, err = upgrade(entry); err != nil {
+			return nil, fmt.Errorf("migration from schema version %d: %w", v, err)
+		}
+	}
+	return entry, nil
+}
+
+// migrateMetadata moves the flat metadata fields of version 1 matches into
+// scraper.GameMetadata. Their language and region were not recorded, so the
+// variants get an empty code.
+func migrateMetadata(entry json.RawMessage) (json.RawMessage, error) {
+	var old struct {
+		Match *struct {
+			Rating      float64
+			Description string
+			ReleaseDate string
+			Developer   string
+			Publisher   string
+			Genres      []string
+			Players     string
+		} `json:"match"`
+	}
+	if err := json.Unmarshal(entry, &old); err != nil {
+		return nil, err
+	}
+	if old.Match == nil {
+		return entry, nil
+	}
+
+	var upgraded Entry
+	if err := json.Unmarshal(entry, &upgraded); err != nil {
+		return nil, err
+	}
+	metadata := &upgraded.Match.Metadata
+	metadata.Rating = old.Match.Rating
+	metadata.Synopsis.Add("", old.Match.Description)
+	metadata.ReleaseDates.Add("", old.Match.ReleaseDate)
+	metadata.Developer = old.Match.Developer
+	metadata.Publisher = old.Match.Publisher
+	metadata.Players = old.Match.Players
+	for _, genre := range old.Match.Genres {
+		var names scraper.Localized
+		names.Add("", genre)
+		if len(names) > 0 {
+			metadata.Genres = append(metadata.Genres, names)
+		}
+	}
+	return json.Marshal(upgraded)
+}
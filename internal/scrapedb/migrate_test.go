@@ -0,0 +1,96 @@
+package scrapedb
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"retroart-sdl2/internal/scraper"
+)
+
+func TestMigrateMetadata(t *testing.T) {
+	v1 := `{"key":"sha1:a","system":"snes","file":"Game A (USA).sfc",` +
+		`"match":{"Provider":"screenscraper","ID":"9","Title":"Game A","Confidence":0.9,` +
+		`"Rating":0.65,"Description":" A game. ","ReleaseDate":"1992","Developer":"Dev","Publisher":"Pub",` +
+		`"Genres":["Platform",""," Action "],"Players":"2"},` +
+		`"media":{"box-front":{"path":"/art/a.png","url":"https://example.com/a.png","size":12}},` +
+		`"updated":"2024-05-01T10:00:00Z"}`
+
+	data, err := migrate(1, json.RawMessage(v1))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("migrated entry does not decode: %v", err)
+	}
+
+	if entry.Key != "sha1:a" || entry.SystemID != "snes" || entry.FileName != "Game A (USA).sfc" {
+		t.Errorf("entry identity = %q %q %q", entry.Key, entry.SystemID, entry.FileName)
+	}
+	if want := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC); !entry.Updated.Equal(want) {
+		t.Errorf("Updated = %v, want %v", entry.Updated, want)
+	}
+	if media, ok := entry.Media[scraper.MediaBoxFront]; !ok || media.Path != "/art/a.png" || media.Size != 12 {
+		t.Errorf("media = %+v, want the version 1 box art", entry.Media)
+	}
+
+	match := entry.Match
+	if match == nil || match.Provider != "screenscraper" || match.ID != "9" || match.Title != "Game A" || match.Confidence != 0.9 {
+		t.Fatalf("match = %+v, want the version 1 identity", match)
+	}
+	metadata := match.Metadata
+	if metadata.Rating != 0.65 || metadata.Developer != "Dev" || metadata.Publisher != "Pub" || metadata.Players != "2" {
+		t.Errorf("metadata = %+v", metadata)
+	}
+	if got := metadata.Description(scraper.MetadataPrefs{}); got != "A game." {
+		t.Errorf("description = %q, want the trimmed version 1 text", got)
+	}
+	if got := metadata.ReleaseDate(scraper.MetadataPrefs{}); got != "1992" {
+		t.Errorf("release date = %q", got)
+	}
+	// Empty genres are dropped instead of becoming empty variants
+	genres := metadata.GenreNames(scraper.MetadataPrefs{})
+	if len(genres) != 2 || genres[0] != "Platform" || genres[1] != "Action" {
+		t.Errorf("genres = %q, want [Platform Action]", genres)
+	}
+}
+
+func TestMigrateMetadataWithoutMatch(t *testing.T) {
+	v1 := `{"key":"crc32:1234abcd:1024","system":"nes","not_found":"2024-05-01T10:00:00Z","updated":"2024-05-01T10:00:00Z"}`
+
+	data, err := migrate(1, json.RawMessage(v1))
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Match != nil {
+		t.Errorf("match = %+v, want none", entry.Match)
+	}
+	if entry.NotFound.IsZero() {
+		t.Error("not found time lost by the migration")
+	}
+}
+
+func TestMigrateCurrentVersionIsUnchanged(t *testing.T) {
+	v2 := json.RawMessage(`{"key":"sha1:b","system":"snes"}`)
+	data, err := migrate(SchemaVersion, v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != string(v2) {
+		t.Errorf("migrate changed a current entry: %s", data)
+	}
+}
+
+func TestMigrateRejectsUnknownVersion(t *testing.T) {
+	if _, err := migrate(0, json.RawMessage(`{}`)); err == nil {
+		t.Error("migrate accepted an entry of schema version 0")
+	}
+	if _, err := migrate(1, json.RawMessage(`{"match":"broken"}`)); err == nil {
+		t.Error("migrate accepted a corrupt version 1 entry")
+	}
+}
@@ -0,0 +1,96 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// Content is an open media body with the validators sent by the server
+type Content struct {
+	io.ReadCloser
+	ETag         string
+	LastModified string
+	Length       int64 // -1 when unknown
+}
+
+// Fetch opens the content of a media URL. Besides http(s) it supports
+// file:// URLs returned by providers backed by a local mirror.
+func Fetch(ctx context.Context, client *http.Client, media Media) (*Content, error) {
+	u, err := url.Parse(media.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid media url: %w", RedactError(err))
+	}
+
+	if u.Scheme == "file" {
+		file, err := os.Open(filepath.FromSlash(u.Path))
+		if err != nil {
+			return nil, err
+		}
+		length := int64(-1)
+		if info, err := file.Stat(); err == nil {
+			length = info.Size()
+		}
+		return &Content{ReadCloser: file, Length: length}, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, media.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", RedactError(err))
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("media request failed: %w", RedactError(err))
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("media request returned status %d", resp.StatusCode)
+	}
+
+	return &Content{
+		ReadCloser:   resp.Body,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Length:       resp.ContentLength,
+	}, nil
+}
+
+// Validators returns the ETag and Last-Modified of a media URL with a HEAD
+// request, to tell whether the file changed since it was downloaded. Media
+// of local mirrors have none.
+func Validators(ctx context.Context, client *http.Client, media Media) (etag, lastModified string, err error) {
+	u, err := url.Parse(media.URL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid media url: %w", RedactError(err))
+	}
+	if u.Scheme == "file" {
+		return "", "", nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, media.URL, nil)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create request: %w", RedactError(err))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", "", fmt.Errorf("media request failed: %w", RedactError(err))
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", "", ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("media request returned status %d", resp.StatusCode)
+	}
+	return resp.Header.Get("ETag"), resp.Header.Get("Last-Modified"), nil
+}
@@ -0,0 +1,246 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"retroart-sdl2/internal/httpclient"
+)
+
+// DefaultLibretroURL is the public libretro-thumbnails mirror
+const DefaultLibretroURL = "https://thumbnails.libretro.com"
+
+// libretroSystems maps library system IDs to libretro-thumbnails folders
+var libretroSystems = map[string]string{
+	"arcade":          "MAME",
+	"cps1":            "FBNeo - Arcade Games",
+	"cps2":            "FBNeo - Arcade Games",
+	"cps3":            "FBNeo - Arcade Games",
+	"neogeo":          "SNK - Neo Geo",
+	"nes":             "Nintendo - Nintendo Entertainment System",
+	"fds":             "Nintendo - Family Computer Disk System",
+	"snes":            "Nintendo - Super Nintendo Entertainment System",
+	"n64":             "Nintendo - Nintendo 64",
+	"gb":              "Nintendo - Game Boy",
+	"gbc":             "Nintendo - Game Boy Color",
+	"gba":             "Nintendo - Game Boy Advance",
+	"nds":             "Nintendo - Nintendo DS",
+	"vb":              "Nintendo - Virtual Boy",
+	"pokemini":        "Nintendo - Pokemon Mini",
+	"gw":              "Handheld Electronic Game",
+	"mastersystem":    "Sega - Master System - Mark III",
+	"megadrive":       "Sega - Mega Drive - Genesis",
+	"segacd":          "Sega - Mega-CD - Sega CD",
+	"sega32x":         "Sega - 32X",
+	"gamegear":        "Sega - Game Gear",
+	"sg1000":          "Sega - SG-1000",
+	"saturn":          "Sega - Saturn",
+	"dreamcast":       "Sega - Dreamcast",
+	"psx":             "Sony - PlayStation",
+	"psp":             "Sony - PlayStation Portable",
+	"pcengine":        "NEC - PC Engine - TurboGrafx 16",
+	"pcenginecd":      "NEC - PC Engine CD - TurboGrafx-CD",
+	"atari2600":       "Atari - 2600",
+	"atari7800":       "Atari - 7800",
+	"lynx":            "Atari - Lynx",
+	"ngp":             "SNK - Neo Geo Pocket",
+	"ngpc":            "SNK - Neo Geo Pocket Color",
+	"wonderswan":      "Bandai - WonderSwan",
+	"wonderswancolor": "Bandai - WonderSwan Color",
+	"colecovision":    "Coleco - ColecoVision",
+	"msx":             "Microsoft - MSX",
+}
+
+// libretroFolders maps media types to libretro-thumbnails sub folders
+var libretroFolders = []struct {
+	Type   MediaType
+	Folder string
+}{
+	{MediaBoxFront, "Named_Boxarts"},
+	{MediaScreenshot, "Named_Snaps"},
+	{MediaTitle, "Named_Titles"},
+	{MediaWheel, "Named_Logos"},
+}
+
+// libretroUnsafeChars are replaced by "_" in thumbnail file names
+var libretroUnsafeChars = strings.NewReplacer(
+	"&", "_", "*", "_", "/", "_", ":", "_", "`", "_",
+	"<", "_", ">", "_", "?", "_", "\\", "_", "|", "_",
+)
+
+// trailingTagPattern matches the last "(...)" or "[...]" group of a name
+var trailingTagPattern = regexp.MustCompile(`\s*(\([^()]*\)|\[[^\[\]]*\])\s*$`)
+
+// LibretroConfig configures the libretro-thumbnails provider
+type LibretroConfig struct {
+	// BaseURL is the thumbnails root. Besides http(s) URLs it accepts
+	// file:// URLs pointing at a local copy of the thumbnail repositories.
+	BaseURL string
+	Client  *httpclient.Client // Shared HTTP layer, defaults to a private one
+}
+
+// Libretro is a Provider that resolves art by No-Intro name using the
+// libretro-thumbnails folder layout
+type Libretro struct {
+	baseURL *url.URL
+	client  *http.Client
+}
+
+// NewLibretro creates a libretro-thumbnails provider
+func NewLibretro(config LibretroConfig) (*Libretro, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultLibretroURL
+	}
+
+	baseURL, err := url.Parse(strings.TrimSuffix(config.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid libretro thumbnails url: %w", err)
+	}
+
+	switch baseURL.Scheme {
+	case "http", "https", "file":
+	default:
+		return nil, fmt.Errorf("unsupported libretro thumbnails scheme: %q", baseURL.Scheme)
+	}
+
+	client := config.Client
+	if client == nil {
+		client = httpclient.New(httpclient.Options{})
+	}
+
+	return &Libretro{baseURL: baseURL, client: client.HTTPClient()}, nil
+}
+
+func (l *Libretro) Name() string {
+	return "libretro"
+}
+
+func (l *Libretro) MediaTypes() []MediaType {
+	types := make([]MediaType, 0, len(libretroFolders))
+	for _, folder := range libretroFolders {
+		types = append(types, folder.Type)
+	}
+	return types
+}
+
+// ResolvesTags reports that LookupByName needs the full No-Intro name: it
+// drops the tags itself, most specific name first
+func (l *Libretro) ResolvesTags() bool {
+	return true
+}
+
+// LookupByHash always fails: libretro-thumbnails has no checksum index, and
+// reporting a name match as a hash match would skip review. Lookup falls
+// through to LookupByName, which gets the canonical DAT name when known.
+func (l *Libretro) LookupByHash(ctx context.Context, query Query) (*Result, error) {
+	return nil, ErrNotFound
+}
+
+// LookupByName tries the full name first and then drops trailing tags one by
+// one, so "Game (USA) (Rev 1)" falls back to "Game (USA)" and then "Game".
+func (l *Libretro) LookupByName(ctx context.Context, name, systemID string) ([]Result, error) {
+	folder, ok := libretroSystems[systemID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSystem, systemID)
+	}
+
+	for _, candidate := range LibretroNameCandidates(name) {
+		result := Result{
+			Provider: l.Name(),
+			ID:       candidate,
+			Title:    candidate,
+			SystemID: systemID,
+		}
+
+		for _, media := range libretroFolders {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
+			mediaURL := l.thumbnailURL(folder, media.Folder, candidate)
+			exists, err := l.exists(ctx, mediaURL)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				result.Media = append(result.Media, Media{
+					Type:   media.Type,
+					URL:    mediaURL,
+					Format: "png",
+				})
+			}
+		}
+
+		if len(result.Media) > 0 {
+			return []Result{result}, nil
+		}
+	}
+
+	return nil, ErrNotFound
+}
+
+// LibretroThumbnailName applies libretro's character substitution rules
+func LibretroThumbnailName(name string) string {
+	return libretroUnsafeChars.Replace(name)
+}
+
+// LibretroNameCandidates lists the names tried for a game, most specific first
+func LibretroNameCandidates(name string) []string {
+	name = strings.TrimSpace(name)
+	candidates := []string{name}
+	for {
+		base := strings.TrimSpace(trailingTagPattern.ReplaceAllString(name, ""))
+		if base == name || base == "" {
+			return candidates
+		}
+		candidates = append(candidates, base)
+		name = base
+	}
+}
+
+func (l *Libretro) thumbnailURL(system, folder, name string) string {
+	u := *l.baseURL
+	u.Path = u.Path + "/" + system + "/" + folder + "/" + LibretroThumbnailName(name) + ".png"
+	return u.String()
+}
+
+// exists checks a thumbnail with a HEAD request, or a stat for file:// roots
+func (l *Libretro) exists(ctx context.Context, mediaURL string) (bool, error) {
+	u, err := url.Parse(mediaURL)
+	if err != nil {
+		return false, err
+	}
+
+	if u.Scheme == "file" {
+		info, err := os.Stat(filepath.FromSlash(u.Path))
+		return err == nil && !info.IsDir(), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, mediaURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("libretro request failed: %w", err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	case http.StatusTooManyRequests:
+		return false, ErrQuotaExceeded
+	default:
+		return false, fmt.Errorf("libretro returned status %d for %s", resp.StatusCode, mediaURL)
+	}
+}
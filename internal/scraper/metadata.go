@@ -0,0 +1,137 @@
+package scraper
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LocalizedText is one variant of a text, tagged with a region code ("us",
+// "eu", "jp", "wor") or a language code ("en", "fr") depending on the field
+type LocalizedText struct {
+	Code string
+	Text string
+}
+
+// Localized holds the variants of a text in provider order
+type Localized []LocalizedText
+
+// Pick returns the variant of the first code in order that has one, or the
+// first variant when none does
+func (l Localized) Pick(order []string) string {
+	for _, code := range order {
+		for _, variant := range l {
+			if variant.Code == code {
+				return variant.Text
+			}
+		}
+	}
+	if len(l) > 0 {
+		return l[0].Text
+	}
+	return ""
+}
+
+// Add appends a variant, ignoring empty texts
+func (l *Localized) Add(code, text string) {
+	if text = strings.TrimSpace(text); text != "" {
+		*l = append(*l, LocalizedText{Code: NormalizeCode(code), Text: text})
+	}
+}
+
+// GameMetadata is the normalised description of a game. Localized fields
+// keep every variant the provider returned, so changing the preferred
+// languages or regions needs no new scrape. Empty fields are unknown.
+type GameMetadata struct {
+	Titles       Localized   // By region
+	Synopsis     Localized   // By language
+	Genres       []Localized // Each genre by language
+	ReleaseDates Localized   // By region, "YYYY-MM-DD", "YYYY-MM" or "YYYY"
+	Developer    string
+	Publisher    string
+	Players      string  // "1", "1-2", ...
+	Rating       float64 // Between 0 and 1, zero when unknown
+}
+
+// MetadataPrefs orders the variants of the localized fields, most wanted
+// first. Codes are lowercase.
+type MetadataPrefs struct {
+	TitleRegions       []string
+	SynopsisLanguages  []string
+	GenreLanguages     []string
+	ReleaseDateRegions []string
+}
+
+// Title returns the preferred localized title
+func (m *GameMetadata) Title(prefs MetadataPrefs) string {
+	return m.Titles.Pick(prefs.TitleRegions)
+}
+
+// Description returns the synopsis in the preferred language
+func (m *GameMetadata) Description(prefs MetadataPrefs) string {
+	return m.Synopsis.Pick(prefs.SynopsisLanguages)
+}
+
+// GenreNames returns the genres in the preferred language
+func (m *GameMetadata) GenreNames(prefs MetadataPrefs) []string {
+	names := make([]string, 0, len(m.Genres))
+	for _, genre := range m.Genres {
+		if name := genre.Pick(prefs.GenreLanguages); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ReleaseDate returns the release date of the preferred region
+func (m *GameMetadata) ReleaseDate(prefs MetadataPrefs) string {
+	return m.ReleaseDates.Pick(prefs.ReleaseDateRegions)
+}
+
+// NormalizeCode lowercases a region or language code
+func NormalizeCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// NormalizeDate converts the date formats used by providers ("1991-06-23",
+// "1991/06", "1991") to "YYYY-MM-DD", "YYYY-MM" or "YYYY". Unknown formats
+// yield an empty string.
+func NormalizeDate(date string) string {
+	date, _, _ = strings.Cut(strings.TrimSpace(date), " ")
+	parts := strings.FieldsFunc(date, func(r rune) bool { return r == '-' || r == '/' })
+	if len(parts) == 0 || len(parts) > 3 || len(parts[0]) != 4 {
+		return ""
+	}
+
+	limits := []int{9999, 12, 31}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 1 || n > limits[i] {
+			// Providers use "00" for an unknown month or day
+			if i > 0 && n == 0 && err == nil {
+				return strings.Join(parts[:i], "-")
+			}
+			return ""
+		}
+		if i > 0 {
+			parts[i] = strconv.Itoa(100 + n)[1:]
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// NormalizePlayers converts player counts such as "1 - 2", "1 to 4" or "2"
+// to "1-2", "1-4" and "2"
+func NormalizePlayers(players string) string {
+	var counts []string
+	for _, field := range strings.FieldsFunc(players, func(r rune) bool { return r < '0' || r > '9' }) {
+		counts = append(counts, strings.TrimLeft(field, "0"))
+	}
+	switch {
+	case len(counts) == 0 || counts[0] == "":
+		return ""
+	case len(counts) == 1 || counts[len(counts)-1] == counts[0]:
+		return counts[0]
+	default:
+		return counts[0] + "-" + counts[len(counts)-1]
+	}
+}
@@ -0,0 +1,69 @@
+package scraper
+
+import (
+	"errors"
+	"net/url"
+	"slices"
+)
+
+// credentialParams are query parameters that carry account secrets, such
+// as the ScreenScraper developer and user logins. They are never written to
+// disk or to the log.
+var credentialParams = []string{"devid", "devpassword", "ssid", "sspassword"}
+
+// RedactURL returns the URL without its credential query parameters. An
+// unparsable URL is dropped entirely, since it may still hold a secret.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	query := u.Query()
+	found := false
+	for _, name := range credentialParams {
+		if query.Has(name) {
+			query.Del(name)
+			found = true
+		}
+	}
+	if found {
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// RedactError strips the credentials from the URL carried by a *url.Error,
+// which net/http returns for transport failures and prints in full
+func RedactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = RedactURL(urlErr.URL)
+	}
+	return err
+}
+
+// Redacted returns the result without credentials in its media URLs, for
+// storing it. The result itself is returned when it holds none, a copy
+// otherwise, since callers keep using theirs.
+func (r *Result) Redacted() *Result {
+	if r == nil {
+		return nil
+	}
+	var media []Media
+	for i, m := range r.Media {
+		clean := RedactURL(m.URL)
+		if clean == m.URL {
+			continue
+		}
+		if media == nil {
+			media = slices.Clone(r.Media)
+		}
+		media[i].URL = clean
+	}
+	if media == nil {
+		return r
+	}
+	redacted := *r
+	redacted.Media = media
+	return &redacted
+}
@@ -0,0 +1,159 @@
+package scraper
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	"retroart-sdl2/internal/library"
+)
+
+// Region is a region code known by RetroArt, as used by the providers
+type Region struct {
+	Code string
+	Name string
+}
+
+// Regions lists the known region codes in display order
+var Regions = []Region{
+	{"wor", "World"}, {"us", "USA"}, {"eu", "Europe"}, {"jp", "Japan"},
+	{"ss", "ScreenScraper"}, {"asi", "Asia"}, {"au", "Australia"},
+	{"br", "Brazil"}, {"ca", "Canada"}, {"cn", "China"}, {"de", "Germany"},
+	{"sp", "Spain"}, {"fr", "France"}, {"it", "Italy"}, {"kr", "Korea"},
+	{"nl", "Netherlands"}, {"ru", "Russia"}, {"se", "Sweden"},
+	{"tw", "Taiwan"}, {"uk", "United Kingdom"},
+}
+
+// regionNames maps the region names of No-Intro/Redump titles and the
+// GoodTools letters to region codes
+var regionNames = map[string][]string{
+	"world": {"wor"}, "usa": {"us"}, "europe": {"eu"}, "japan": {"jp"},
+	"asia": {"asi"}, "australia": {"au"}, "brazil": {"br"}, "canada": {"ca"},
+	"china": {"cn"}, "france": {"fr"}, "germany": {"de"}, "hong kong": {"cn"},
+	"italy": {"it"}, "korea": {"kr"}, "netherlands": {"nl"}, "russia": {"ru"},
+	"spain": {"sp"}, "sweden": {"se"}, "taiwan": {"tw"}, "united kingdom": {"uk"},
+
+	"w": {"wor"}, "u": {"us"}, "e": {"eu"}, "j": {"jp"}, "ue": {"us", "eu"},
+	"ju": {"jp", "us"}, "je": {"jp", "eu"}, "jue": {"jp", "us", "eu"},
+	"uej": {"us", "eu", "jp"}, "a": {"au"}, "b": {"br"}, "c": {"cn"},
+	"f": {"fr"}, "g": {"de"}, "i": {"it"}, "k": {"kr"}, "s": {"sp"},
+	"sw": {"se"}, "hk": {"cn"}, "r": {"ru"},
+}
+
+var regionTagPattern = regexp.MustCompile(`\(([^()]+)\)`)
+
+// RegionCodes converts a region name of a ROM title ("USA", "Europe", "U",
+// "JUE") to region codes. Unknown names, including the language tags of
+// No-Intro titles such as "Fr", yield nothing.
+func RegionCodes(name string) []string {
+	return regionNames[NormalizeCode(name)]
+}
+
+// GameRegions returns the region codes of a ROM: those of the DAT title
+// when the ROM was identified, else those of the file name tags, e.g.
+// "Sonic (USA, Europe).md" gives us and eu. Extra names, such as the
+// region found by a DAT hash lookup, come first.
+func GameRegions(game library.Game, extra ...string) []string {
+	var codes []string
+	add := func(name string) {
+		for _, code := range RegionCodes(name) {
+			if !slices.Contains(codes, code) {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	for _, name := range extra {
+		add(name)
+	}
+	title := game.Title
+	if title == "" {
+		title = game.FileName
+	}
+	for _, match := range regionTagPattern.FindAllStringSubmatch(title, -1) {
+		for _, part := range strings.Split(match[1], ",") {
+			add(strings.TrimSpace(part))
+		}
+	}
+	return codes
+}
+
+// RegionResolver orders regions for a ROM: the regions of the ROM first,
+// then the configured order, each region followed by its fallback chain.
+// The result only depends on its inputs, so reruns pick the same media.
+type RegionResolver struct {
+	Order     []string            // Configured priority, most wanted first
+	Fallbacks map[string][]string // Regions tried after a region, e.g. fr: eu, wor
+}
+
+// Chain returns the region priority for a ROM with the given regions,
+// using order instead of the configured one when it is not empty
+func (r *RegionResolver) Chain(romRegions, order []string) []string {
+	var fallbacks map[string][]string
+	if r != nil {
+		fallbacks = r.Fallbacks
+		if len(order) == 0 {
+			order = r.Order
+		}
+	}
+
+	var chain []string
+	var visit func(code string)
+	visit = func(code string) {
+		code = NormalizeCode(code)
+		if code == "" || slices.Contains(chain, code) {
+			return
+		}
+		chain = append(chain, code)
+		for _, next := range fallbacks[code] {
+			visit(next)
+		}
+	}
+
+	for _, code := range romRegions {
+		visit(code)
+	}
+	for _, code := range order {
+		visit(code)
+	}
+	return chain
+}
+
+// Prefs returns metadata preferences with the title and release date
+// regions resolved for a ROM
+func (r *RegionResolver) Prefs(prefs MetadataPrefs, romRegions []string) MetadataPrefs {
+	prefs.TitleRegions = r.Chain(romRegions, prefs.TitleRegions)
+	prefs.ReleaseDateRegions = r.Chain(romRegions, prefs.ReleaseDateRegions)
+	return prefs
+}
+
+// PickMedia returns the media whose region comes first in the chain of the
+// ROM. Media without a region follow the chain, media of other regions come
+// last. Ties are broken by region and URL, never by provider order, which
+// may change between requests.
+func (r *RegionResolver) PickMedia(media []Media, romRegions []string) (Media, bool) {
+	if len(media) == 0 {
+		return Media{}, false
+	}
+	chain := r.Chain(romRegions, nil)
+
+	rank := func(m Media) int {
+		region := NormalizeCode(m.Region)
+		if i := slices.Index(chain, region); i >= 0 {
+			return i
+		}
+		if region == "" {
+			return len(chain)
+		}
+		return len(chain) + 1
+	}
+
+	return slices.MinFunc(media, func(a, b Media) int {
+		return cmp.Or(
+			cmp.Compare(rank(a), rank(b)),
+			cmp.Compare(NormalizeCode(a.Region), NormalizeCode(b.Region)),
+			cmp.Compare(a.URL, b.URL),
+		)
+	}), true
+}
@@ -0,0 +1,116 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/library/dat"
+	"retroart-sdl2/internal/library/hash"
+	"retroart-sdl2/internal/match"
+)
+
+// Identifier turns scanned games into lookup queries. Both fields are
+// optional: without a hasher only names are used, and without a DAT index
+// file names are sent as they are.
+type Identifier struct {
+	Hasher *hash.Hasher
+	Index  *dat.Index
+}
+
+// Query builds a lookup query from a scanned game. Checksums are added when
+// a hasher is set, and a DAT match replaces the file name with the canonical
+// title. Hashing failures only disable hash lookups.
+func (id *Identifier) Query(game library.Game) Query {
+	query := Query{
+		SystemID: game.SystemID,
+		FileName: game.FileName,
+		Name:     game.DisplayName(),
+		Size:     game.Size,
+	}
+
+	if id == nil || id.Hasher == nil {
+		return query
+	}
+
+	hashes, err := id.Hasher.Hash(game.Path)
+	if errors.Is(err, hash.ErrUnsupportedArchive) {
+		return query
+	}
+	if err != nil {
+		log.Printf("Scraper: failed to hash %s: %v", game.Path, err)
+		return query
+	}
+	query.CRC32 = hashes.CRC32
+	query.MD5 = hashes.MD5
+	query.SHA1 = hashes.SHA1
+	// Providers expect the ROM size with its header; caches written before
+	// FileSize existed only have the file size of the game
+	if hashes.FileSize > 0 {
+		query.Size = hashes.FileSize
+	}
+
+	if entry, ok := id.Index.Lookup(hashes); ok {
+		query.Name = entry.Name
+		query.Region = entry.Region
+		query.Serial = entry.Serial
+	}
+
+	return query
+}
+
+// Lookup tries a hash lookup and falls back to name matches ranked by
+// title similarity, best first. Every result carries its confidence.
+func Lookup(ctx context.Context, provider Provider, query Query) ([]Result, error) {
+	result, err := provider.LookupByHash(ctx, query)
+	if err == nil {
+		result.Confidence = 1
+		return []Result{*result}, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	results, err := LookupName(ctx, provider, query.Name, query.SystemID)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, ErrNotFound
+	}
+	return RankResults(query.Name, results), nil
+}
+
+// tagResolver is implemented by providers that resolve names through their
+// own tag fallback, such as file name based thumbnail repositories, and need
+// the region and revision tags that match.Clean strips
+type tagResolver interface {
+	ResolvesTags() bool
+}
+
+// LookupName looks a name up on a provider. The name is cleaned from file
+// name tags first, unless the provider resolves tags itself.
+func LookupName(ctx context.Context, provider Provider, name, systemID string) ([]Result, error) {
+	if resolver, ok := provider.(tagResolver); !ok || !resolver.ResolvesTags() {
+		name = match.Clean(name)
+	}
+	return provider.LookupByName(ctx, name, systemID)
+}
+
+// RankResults sorts results by title similarity to name, best first. Both
+// the name and the result titles are cleaned before comparing.
+func RankResults(name string, results []Result) []Result {
+	titles := make([]string, len(results))
+	for i, result := range results {
+		titles[i] = match.Clean(result.Title)
+	}
+
+	ranked := make([]Result, 0, len(results))
+	for _, candidate := range match.Rank(match.Clean(name), titles) {
+		result := results[candidate.Index]
+		result.Confidence = candidate.Confidence
+		ranked = append(ranked, result)
+	}
+	return ranked
+}
@@ -0,0 +1,61 @@
+package scraper
+
+import (
+	"context"
+	"testing"
+)
+
+// nameProvider records the names it is asked for and returns fixed titles
+type nameProvider struct {
+	titles   []string
+	tags     bool
+	searched []string
+}
+
+func (p *nameProvider) Name() string { return "fake" }
+
+func (p *nameProvider) MediaTypes() []MediaType { return nil }
+
+func (p *nameProvider) ResolvesTags() bool { return p.tags }
+
+func (p *nameProvider) LookupByHash(ctx context.Context, query Query) (*Result, error) {
+	return nil, ErrNotFound
+}
+
+func (p *nameProvider) LookupByName(ctx context.Context, name, systemID string) ([]Result, error) {
+	p.searched = append(p.searched, name)
+	results := make([]Result, len(p.titles))
+	for i, title := range p.titles {
+		results[i] = Result{Provider: p.Name(), ID: title, Title: title, SystemID: systemID}
+	}
+	return results, nil
+}
+
+func TestLookupCleansName(t *testing.T) {
+	provider := &nameProvider{titles: []string{"Super Mario Kart", "Super Mario World (USA)"}}
+	query := Query{SystemID: "snes", Name: "Super Mario World (USA) (Rev 1) [!]"}
+
+	results, err := Lookup(context.Background(), provider, query)
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+
+	if len(provider.searched) != 1 || provider.searched[0] != "Super Mario World" {
+		t.Errorf("searched %q, want the cleaned name", provider.searched)
+	}
+	if results[0].Title != "Super Mario World (USA)" || results[0].Confidence != 1 {
+		t.Errorf("best result = %q at %.2f, want the exact title at 1", results[0].Title, results[0].Confidence)
+	}
+}
+
+func TestLookupKeepsTagsForTagResolvers(t *testing.T) {
+	provider := &nameProvider{titles: []string{"Super Mario World (USA)"}, tags: true}
+	query := Query{SystemID: "snes", Name: "Super Mario World (USA) (Rev 1)"}
+
+	if _, err := Lookup(context.Background(), provider, query); err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if provider.searched[0] != query.Name {
+		t.Errorf("searched %q, want %q", provider.searched[0], query.Name)
+	}
+}
@@ -0,0 +1,131 @@
+// Package scraper defines the interface implemented by artwork/metadata
+// providers and the types shared between them.
+package scraper
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrNotFound is returned when the provider has no entry for the game
+	ErrNotFound = errors.New("game not found")
+	// ErrUnauthorized is returned when the provider rejects the credentials
+	ErrUnauthorized = errors.New("invalid provider credentials")
+	// ErrQuotaExceeded is returned when the provider refuses more requests
+	ErrQuotaExceeded = errors.New("provider quota exceeded")
+	// ErrUnsupportedSystem is returned when the provider does not know the system
+	ErrUnsupportedSystem = errors.New("system not supported by provider")
+)
+
+// MediaType identifies a kind of artwork
+type MediaType string
+
+const (
+	MediaBoxFront   MediaType = "box-front"
+	MediaBoxBack    MediaType = "box-back"
+	MediaBox3D      MediaType = "box-3d"
+	MediaScreenshot MediaType = "screenshot"
+	MediaTitle      MediaType = "title"
+	MediaWheel      MediaType = "wheel"
+	MediaMarquee    MediaType = "marquee"
+	MediaFanart     MediaType = "fanart"
+	MediaVideo      MediaType = "video"
+
+	// MediaMixed is composed locally from other media, providers never
+	// return it
+	MediaMixed MediaType = "mixed"
+)
+
+// AllMediaTypes lists every media type known by RetroArt
+var AllMediaTypes = []MediaType{
+	MediaBoxFront, MediaBoxBack, MediaBox3D, MediaScreenshot, MediaTitle,
+	MediaWheel, MediaMarquee, MediaFanart, MediaVideo,
+}
+
+// Query describes the game being looked up. Hash fields are optional; empty
+// values are simply not sent to the provider.
+type Query struct {
+	SystemID string // library.SystemDefinition ID
+	FileName string // ROM file name with extension
+	Name     string // Game name used for name based lookups
+	Region   string // Region of the ROM when known, e.g. "USA"
+	Serial   string // Product serial when known
+	Size     int64
+	CRC32    string
+	MD5      string
+	SHA1     string
+}
+
+// Media is a downloadable artwork returned by a provider
+type Media struct {
+	Type   MediaType
+	URL    string
+	Region string
+	Format string // File extension without the dot, e.g. "png"
+	Size   int64  // Zero when unknown
+	MD5    string // Empty when unknown
+	SHA1   string // Empty when unknown
+}
+
+// Result is a game matched by a provider
+type Result struct {
+	Provider string
+	ID       string
+	Title    string
+	SystemID string
+	Media    []Media
+	Metadata GameMetadata
+
+	// Confidence is set by Lookup: 1 for hash matches, the title
+	// similarity for name matches
+	Confidence float64
+
+	// Regions are the region codes of the ROM the result was matched for,
+	// the DAT region first, as returned by GameRegions. The job engine sets
+	// them, so exports and the UI order media and texts like the scrape did.
+	Regions []string
+}
+
+// MediaOfType returns the media of the given type in provider order
+func (r *Result) MediaOfType(mediaType MediaType) []Media {
+	var media []Media
+	for _, m := range r.Media {
+		if m.Type == mediaType {
+			media = append(media, m)
+		}
+	}
+	return media
+}
+
+// DisplayTitle returns the preferred localized title, or the matching title
+// when the provider has no localized ones
+func (r *Result) DisplayTitle(prefs MetadataPrefs) string {
+	if title := r.Metadata.Title(prefs); title != "" {
+		return title
+	}
+	return r.Title
+}
+
+// Provider is implemented by every scraping backend
+type Provider interface {
+	// Name returns a short identifier for the provider
+	Name() string
+
+	// LookupByHash finds a game using the ROM checksums in the query
+	LookupByHash(ctx context.Context, query Query) (*Result, error)
+
+	// LookupByName finds games matching a name inside a system, best first
+	LookupByName(ctx context.Context, name, systemID string) ([]Result, error)
+
+	// MediaTypes lists the kinds of artwork the provider can return
+	MediaTypes() []MediaType
+}
+
+// Authorizer is implemented by providers whose media URLs need the account
+// credentials. Results carry the URLs without them (see RedactURL), so they
+// can be stored and logged; AuthorizeURL adds them back right before a
+// request. URLs of other hosts are returned unchanged.
+type Authorizer interface {
+	AuthorizeURL(rawURL string) string
+}
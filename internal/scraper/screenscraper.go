@@ -0,0 +1,416 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"retroart-sdl2/internal/httpclient"
+)
+
+// DefaultScreenScraperURL is the base URL of the ScreenScraper v2 API
+const DefaultScreenScraperURL = "https://api.screenscraper.fr/api2"
+
+// screenScraperSystems maps library system IDs to ScreenScraper "systemeid"
+var screenScraperSystems = map[string]int{
+	"megadrive":       1,
+	"mastersystem":    2,
+	"nes":             3,
+	"snes":            4,
+	"cps1":            6,
+	"cps2":            7,
+	"cps3":            8,
+	"gb":              9,
+	"gbc":             10,
+	"vb":              11,
+	"gba":             12,
+	"n64":             14,
+	"nds":             15,
+	"sega32x":         19,
+	"segacd":          20,
+	"gamegear":        21,
+	"saturn":          22,
+	"dreamcast":       23,
+	"ngp":             25,
+	"atari2600":       26,
+	"lynx":            28,
+	"pcengine":        31,
+	"atari7800":       41,
+	"wonderswan":      45,
+	"wonderswancolor": 46,
+	"colecovision":    48,
+	"gw":              52,
+	"psx":             57,
+	"psp":             61,
+	"arcade":          75,
+	"ngpc":            82,
+	"fds":             106,
+	"sg1000":          109,
+	"msx":             113,
+	"pcenginecd":      114,
+	"neogeo":          142,
+	"pokemini":        211,
+	"pico8":           234,
+}
+
+// screenScraperMediaTypes maps ScreenScraper media "type" values to MediaType
+var screenScraperMediaTypes = map[string]MediaType{
+	"box-2D":        MediaBoxFront,
+	"box-2D-back":   MediaBoxBack,
+	"box-3D":        MediaBox3D,
+	"ss":            MediaScreenshot,
+	"sstitle":       MediaTitle,
+	"wheel":         MediaWheel,
+	"wheel-hd":      MediaWheel,
+	"screenmarquee": MediaMarquee,
+	"fanart":        MediaFanart,
+	"video":         MediaVideo,
+}
+
+// screenScraperTitleRegions is the region order of the title used for
+// matching and display, the world title first
+var screenScraperTitleRegions = []string{"wor", "us", "eu", "ss", "jp"}
+
+// ScreenScraperConfig holds the endpoint and credentials used by the client
+type ScreenScraperConfig struct {
+	BaseURL     string // Defaults to DefaultScreenScraperURL
+	DevID       string
+	DevPassword string
+	SoftName    string
+	Username    string // Optional user account, raises thread and quota limits
+	Password    string
+	Client      *httpclient.Client // Shared HTTP layer, defaults to a private one
+}
+
+// ScreenScraper is a Provider backed by the ScreenScraper "jeuInfos" API
+type ScreenScraper struct {
+	config ScreenScraperConfig
+	client *httpclient.Client
+	host   string
+}
+
+// NewScreenScraper creates a ScreenScraper provider
+func NewScreenScraper(config ScreenScraperConfig) *ScreenScraper {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultScreenScraperURL
+	}
+	if config.SoftName == "" {
+		config.SoftName = "retroart"
+	}
+
+	client := config.Client
+	if client == nil {
+		client = httpclient.New(httpclient.Options{})
+	}
+
+	host := ""
+	if u, err := url.Parse(config.BaseURL); err == nil {
+		host = u.Hostname()
+	}
+
+	return &ScreenScraper{config: config, client: client, host: host}
+}
+
+func (ss *ScreenScraper) Name() string {
+	return "screenscraper"
+}
+
+func (ss *ScreenScraper) MediaTypes() []MediaType {
+	return AllMediaTypes
+}
+
+func (ss *ScreenScraper) LookupByHash(ctx context.Context, query Query) (*Result, error) {
+	params := url.Values{}
+	if query.CRC32 != "" {
+		params.Set("crc", query.CRC32)
+	}
+	if query.MD5 != "" {
+		params.Set("md5", query.MD5)
+	}
+	if query.SHA1 != "" {
+		params.Set("sha1", query.SHA1)
+	}
+	if query.FileName != "" {
+		params.Set("romnom", query.FileName)
+	}
+	if query.Size > 0 {
+		params.Set("romtaille", strconv.FormatInt(query.Size, 10))
+	}
+
+	return ss.jeuInfos(ctx, params, query.SystemID)
+}
+
+// LookupByName uses jeuInfos with only the ROM name, which ScreenScraper
+// matches against its known file names. At most one result is returned.
+func (ss *ScreenScraper) LookupByName(ctx context.Context, name, systemID string) ([]Result, error) {
+	params := url.Values{}
+	params.Set("romnom", name)
+
+	result, err := ss.jeuInfos(ctx, params, systemID)
+	if err != nil {
+		return nil, err
+	}
+	return []Result{*result}, nil
+}
+
+func (ss *ScreenScraper) jeuInfos(ctx context.Context, params url.Values, systemID string) (*Result, error) {
+	systemeID, ok := screenScraperSystems[systemID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedSystem, systemID)
+	}
+
+	ss.setCredentials(params)
+	params.Set("softname", ss.config.SoftName)
+	params.Set("output", "json")
+	params.Set("romtype", "rom")
+	params.Set("systemeid", strconv.Itoa(systemeID))
+
+	endpoint := strings.TrimSuffix(ss.config.BaseURL, "/") + "/jeuInfos.php?" + params.Encode()
+	// The endpoint holds the account passwords: errors carrying it are redacted
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", RedactError(err))
+	}
+
+	resp, err := ss.client.HTTPClient().Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("screenscraper request failed: %w", RedactError(err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read screenscraper response: %w", RedactError(err))
+	}
+
+	if err := screenScraperStatusError(resp.StatusCode, body); err != nil {
+		if errors.Is(err, ErrQuotaExceeded) {
+			quota, _ := ss.client.Quotas().Get(ss.Name())
+			quota.Name = ss.Name()
+			quota.Remaining = 0
+			quota.Updated = time.Now()
+			quota.Exceeded = true
+			ss.client.Quotas().Set(quota)
+		}
+		return nil, err
+	}
+
+	var payload ssResponse
+	if err := json.Unmarshal(body, &payload); err != nil {
+		// ScreenScraper answers some errors with plain text and status 200
+		if strings.Contains(strings.ToLower(string(body)), "erreur") {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to decode screenscraper response: %w", err)
+	}
+
+	// Cached answers carry the account usage of the day they were stored
+	if resp.Header.Get("X-Cache") == "" {
+		ss.reportQuota(payload.Response.User)
+	}
+
+	if payload.Response.Jeu.ID == "" {
+		return nil, ErrNotFound
+	}
+
+	return ss.convertGame(payload.Response.Jeu, systemID), nil
+}
+
+// setCredentials adds the developer and user logins to the query
+func (ss *ScreenScraper) setCredentials(params url.Values) {
+	params.Set("devid", ss.config.DevID)
+	params.Set("devpassword", ss.config.DevPassword)
+	if ss.config.Username != "" {
+		params.Set("ssid", ss.config.Username)
+		params.Set("sspassword", ss.config.Password)
+	}
+}
+
+// AuthorizeURL adds the logins back to a media URL of ScreenScraper, whose
+// media are served from other subdomains than the API
+func (ss *ScreenScraper) AuthorizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || !sameSite(u.Hostname(), ss.host) {
+		return rawURL
+	}
+	query := u.Query()
+	ss.setCredentials(query)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// sameSite reports whether host is apiHost or shares its parent domain,
+// e.g. neoclone.screenscraper.fr and api.screenscraper.fr
+func sameSite(host, apiHost string) bool {
+	if host == "" {
+		return false
+	}
+	if host == apiHost {
+		return true
+	}
+	if net.ParseIP(apiHost) != nil || strings.Count(apiHost, ".") < 2 {
+		return false
+	}
+	return strings.HasSuffix(host, apiHost[strings.Index(apiHost, "."):])
+}
+
+// reportQuota publishes the daily request quota of the account and lowers
+// the rate limit to the per-minute limit of the account. The configured
+// rate is never raised, even for accounts allowed more requests.
+func (ss *ScreenScraper) reportQuota(user ssUser) {
+	maxPerDay, _ := strconv.Atoi(user.MaxRequestsPerDay)
+	today, _ := strconv.Atoi(user.RequestsToday)
+	if maxPerDay > 0 {
+		ss.client.Quotas().Set(httpclient.Quota{
+			Name:      ss.Name(),
+			Limit:     maxPerDay,
+			Remaining: max(maxPerDay-today, 0),
+		})
+	}
+
+	if perMinute, _ := strconv.Atoi(user.MaxRequestsPerMin); perMinute > 0 && ss.host != "" {
+		configured := ss.client.ConfiguredLimit(ss.host)
+		ss.client.SetLimit(ss.host, httpclient.Limit{Rate: min(configured.Rate, float64(perMinute)/60), Burst: 1})
+	}
+}
+
+// screenScraperStatusError maps ScreenScraper HTTP status codes to errors
+func screenScraperStatusError(status int, body []byte) error {
+	switch {
+	case status == http.StatusOK:
+		return nil
+	case status == http.StatusNotFound:
+		return ErrNotFound
+	case status == http.StatusUnauthorized, status == http.StatusForbidden:
+		return ErrUnauthorized
+	case status == http.StatusTooManyRequests, status == 430, status == 431:
+		return ErrQuotaExceeded
+	default:
+		return fmt.Errorf("screenscraper returned status %d: %s", status, strings.TrimSpace(string(body)))
+	}
+}
+
+func (ss *ScreenScraper) convertGame(jeu ssGame, systemID string) *Result {
+	var metadata GameMetadata
+	for _, name := range jeu.Names {
+		metadata.Titles.Add(name.Region, name.Text)
+	}
+	for _, synopsis := range jeu.Synopsis {
+		metadata.Synopsis.Add(synopsis.Language, synopsis.Text)
+	}
+	for _, genre := range jeu.Genres {
+		var names Localized
+		for _, name := range genre.Names {
+			names.Add(name.Language, name.Text)
+		}
+		if len(names) > 0 {
+			metadata.Genres = append(metadata.Genres, names)
+		}
+	}
+	for _, date := range jeu.Dates {
+		metadata.ReleaseDates.Add(date.Region, NormalizeDate(date.Text))
+	}
+	metadata.Developer = strings.TrimSpace(jeu.Developer.Text)
+	metadata.Publisher = strings.TrimSpace(jeu.Publisher.Text)
+	metadata.Players = NormalizePlayers(jeu.Players.Text)
+
+	// ScreenScraper rates games out of 20
+	if note, err := strconv.ParseFloat(jeu.Note.Text, 64); err == nil && note > 0 {
+		metadata.Rating = min(note/20, 1)
+	}
+
+	result := &Result{
+		Provider: ss.Name(),
+		ID:       jeu.ID,
+		Title:    metadata.Titles.Pick(screenScraperTitleRegions),
+		SystemID: systemID,
+		Metadata: metadata,
+	}
+
+	for _, m := range jeu.Medias {
+		mediaType, ok := screenScraperMediaTypes[m.Type]
+		if !ok || m.URL == "" {
+			continue
+		}
+		size, _ := strconv.ParseInt(m.Size, 10, 64)
+		result.Media = append(result.Media, Media{
+			Type:   mediaType,
+			URL:    RedactURL(m.URL), // See AuthorizeURL
+			Region: m.Region,
+			Format: m.Format,
+			Size:   size,
+			MD5:    m.MD5,
+			SHA1:   m.SHA1,
+		})
+	}
+
+	return result
+}
+
+// ssResponse mirrors the subset of the jeuInfos JSON used by RetroArt
+type ssResponse struct {
+	Response struct {
+		Jeu  ssGame `json:"jeu"`
+		User ssUser `json:"ssuser"`
+	} `json:"response"`
+}
+
+// ssUser holds the account limits ScreenScraper returns with every answer
+type ssUser struct {
+	MaxThreads        string `json:"maxthreads"`
+	MaxRequestsPerMin string `json:"maxrequestspermin"`
+	RequestsToday     string `json:"requeststoday"`
+	MaxRequestsPerDay string `json:"maxrequestsperday"`
+}
+
+type ssGame struct {
+	ID        string           `json:"id"`
+	Names     []ssRegionText   `json:"noms"`
+	Synopsis  []ssLanguageText `json:"synopsis"`
+	Dates     []ssRegionText   `json:"dates"`
+	Developer ssText           `json:"developpeur"`
+	Publisher ssText           `json:"editeur"`
+	Players   ssText           `json:"joueurs"`
+	Genres    []ssGenre        `json:"genres"`
+	Note      ssText           `json:"note"`
+	Medias    []ssMedia        `json:"medias"`
+}
+
+type ssGenre struct {
+	ID    string           `json:"id"`
+	Names []ssLanguageText `json:"noms"`
+}
+
+type ssLanguageText struct {
+	Language string `json:"langue"`
+	Text     string `json:"text"`
+}
+
+type ssText struct {
+	Text string `json:"text"`
+}
+
+type ssRegionText struct {
+	Region string `json:"region"`
+	Text   string `json:"text"`
+}
+
+type ssMedia struct {
+	Type   string `json:"type"`
+	Parent string `json:"parent"`
+	URL    string `json:"url"`
+	Region string `json:"region"`
+	Format string `json:"format"`
+	Size   string `json:"size"`
+	CRC    string `json:"crc"`
+	MD5    string `json:"md5"`
+	SHA1   string `json:"sha1"`
+}
@@ -0,0 +1,154 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"retroart-sdl2/internal/httpclient"
+)
+
+const ssGameJSON = `{"response":{"ssuser":{"maxrequestspermin":"6000","requeststoday":"10","maxrequestsperday":"20000"},` +
+	`"jeu":{"id":"42","noms":[{"region":"us","text":"Super Mario World"}]}}}`
+
+func TestScreenScraperKeepsConfiguredRate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, ssGameJSON)
+	}))
+	defer server.Close()
+
+	client := httpclient.New(httpclient.Options{Limit: httpclient.Limit{Rate: 1, Burst: 1}})
+	ss := NewScreenScraper(ScreenScraperConfig{BaseURL: server.URL, Client: client})
+
+	if _, err := ss.LookupByName(context.Background(), "Super Mario World", "snes"); err != nil {
+		t.Fatalf("LookupByName: %v", err)
+	}
+	quota, ok := client.Quotas().Get(ss.Name())
+	if !ok || quota.Limit != 20000 || quota.Remaining != 19990 {
+		t.Errorf("quota = %+v, want 19990 of 20000", quota)
+	}
+
+	// The account allows 100 requests per second, the config only one
+	start := time.Now()
+	if _, err := ss.LookupByName(context.Background(), "Super Mario World", "snes"); err != nil {
+		t.Fatalf("LookupByName: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 500*time.Millisecond {
+		t.Errorf("second request after %s, the account limit raised the configured rate", elapsed)
+	}
+}
+
+func TestScreenScraperQuotaExceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(430)
+	}))
+	defer server.Close()
+
+	client := httpclient.New(httpclient.Options{MaxRetries: -1})
+	ss := NewScreenScraper(ScreenScraperConfig{BaseURL: server.URL, Client: client})
+
+	_, err := ss.LookupByName(context.Background(), "Super Mario World", "snes")
+	if !errors.Is(err, ErrQuotaExceeded) {
+		t.Fatalf("err = %v, want ErrQuotaExceeded", err)
+	}
+	quota, ok := client.Quotas().Get(ss.Name())
+	if !ok || !quota.Exceeded || quota.Remaining != 0 {
+		t.Errorf("quota = %+v, want it marked exceeded", quota)
+	}
+}
+
+func TestScreenScraperErrorsHideCredentials(t *testing.T) {
+	// A closed server: the request fails in the transport, whose error
+	// carries the request URL
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := httpclient.New(httpclient.Options{MaxRetries: -1})
+	ss := NewScreenScraper(ScreenScraperConfig{
+		BaseURL:     server.URL,
+		Client:      client,
+		DevID:       "dev",
+		DevPassword: "devsecret",
+		Username:    "user",
+		Password:    "usersecret",
+	})
+
+	_, err := ss.LookupByName(context.Background(), "Super Mario World", "snes")
+	if err == nil {
+		t.Fatal("LookupByName succeeded against a closed server")
+	}
+	for _, secret := range []string{"devsecret", "usersecret", "devid", "ssid"} {
+		if strings.Contains(err.Error(), secret) {
+			t.Errorf("error contains %q: %v", secret, err)
+		}
+	}
+	if !strings.Contains(err.Error(), "romnom=") {
+		t.Errorf("error lost the rest of the URL: %v", err)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"https://example.com/media.php?devid=a&devpassword=b&ssid=c&sspassword=d&media=box-2D",
+			"https://example.com/media.php?media=box-2D"},
+		{"https://example.com/a.png?b=2&a=1", "https://example.com/a.png?b=2&a=1"},
+		{"file:///art/a.png", "file:///art/a.png"},
+		{"://bad?sspassword=x", ""},
+	}
+	for _, tt := range tests {
+		if got := RedactURL(tt.url); got != tt.want {
+			t.Errorf("RedactURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestScreenScraperMediaURLs(t *testing.T) {
+	media := `"medias":[{"type":"box-2D","region":"us","format":"png","url":` +
+		`"https://neoclone.screenscraper.fr/api2/mediaJeu.php?devid=dev&devpassword=devsecret&ssid=user&sspassword=usersecret&jeuid=42&media=box-2D(us)"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, strings.Replace(ssGameJSON, `"id":"42",`, `"id":"42",`+media+`,`, 1))
+	}))
+	defer server.Close()
+
+	config := ScreenScraperConfig{
+		BaseURL:     server.URL,
+		Client:      httpclient.New(httpclient.Options{}),
+		DevID:       "dev",
+		DevPassword: "devsecret",
+		Username:    "user",
+		Password:    "usersecret",
+	}
+	results, err := NewScreenScraper(config).LookupByName(context.Background(), "Super Mario World", "snes")
+	if err != nil {
+		t.Fatalf("LookupByName: %v", err)
+	}
+	if len(results[0].Media) != 1 {
+		t.Fatalf("media = %+v, want one", results[0].Media)
+	}
+	stored := results[0].Media[0].URL
+	if strings.Contains(stored, "secret") || !strings.Contains(stored, "jeuid=42") {
+		t.Errorf("result media URL = %q, want it without credentials", stored)
+	}
+
+	// Credentials are added back only for the ScreenScraper media hosts
+	config.BaseURL = DefaultScreenScraperURL
+	ss := NewScreenScraper(config)
+	authorized, _ := url.Parse(ss.AuthorizeURL(stored))
+	if query := authorized.Query(); query.Get("devpassword") != "devsecret" || query.Get("sspassword") != "usersecret" ||
+		query.Get("jeuid") != "42" {
+		t.Errorf("authorized URL = %s, want the credentials and the media parameters", authorized)
+	}
+	for _, other := range []string{"https://thumbnails.libretro.com/a.png", "file:///art/a.png", "https://screenscraper.fr.example.com/a.png"} {
+		if got := ss.AuthorizeURL(other); got != other {
+			t.Errorf("AuthorizeURL(%q) = %q, want it unchanged", other, got)
+		}
+	}
+}
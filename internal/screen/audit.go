@@ -0,0 +1,436 @@
+package screen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/TotallyGamerJet/clay"
+
+	"retroart-sdl2/internal/audit"
+	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/job"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/output"
+	"retroart-sdl2/internal/scraper"
+	"retroart-sdl2/internal/theme"
+	"retroart-sdl2/internal/ui"
+	"retroart-sdl2/internal/ui/widgets"
+)
+
+// maxAuditDetails limita quantos arquivos aparecem na lista de problemas
+const maxAuditDetails = 8
+
+// auditResult é o resultado de uma auditoria em background
+type auditResult struct {
+	generation int
+	report     *audit.Report
+	err        error
+}
+
+// Audit confere a arte da biblioteca contra o layout de saída e oferece a
+// limpeza de órfãos e arquivos quebrados e o reenvio do que falta ao engine
+type Audit struct {
+	navigator  Navigator
+	systems    []library.System
+	engine     *job.Engine
+	writer     *output.Writer
+	mediaTypes []scraper.MediaType
+
+	report  *audit.Report
+	running bool
+	status  string
+	isError bool
+
+	// pending é a exclusão esperando confirmação, nil quando nenhuma
+	pending      func()
+	pendingLabel string
+
+	runButton      *widgets.Button
+	orphansButton  *widgets.Button
+	brokenButton   *widgets.Button
+	requeueButton  *widgets.Button
+	backButton     *widgets.Button
+	confirmButtons []*widgets.Button
+	cancel         context.CancelFunc
+	resultCh       chan auditResult
+	// generation identifica a auditoria mais recente; resultados de uma
+	// execução substituída são descartados
+	generation int
+}
+
+func NewAudit(systems []library.System, engine *job.Engine, writer *output.Writer, mediaTypes []scraper.MediaType) *Audit {
+	a := &Audit{
+		systems:    systems,
+		engine:     engine,
+		writer:     writer,
+		mediaTypes: mediaTypes,
+		resultCh:   make(chan auditResult, 1),
+	}
+
+	a.initializeWidgets()
+	a.InitializeFocus()
+
+	return a
+}
+
+func (a *Audit) initializeWidgets() {
+	a.runButton = widgets.NewButton("audit-run-btn", "Run Audit", clay.SizingFixed(240),
+		clay.SizingFixed(45), theme.StylePrimary, a.start)
+
+	a.orphansButton = widgets.NewButton("audit-orphans-btn", "Delete Orphans", clay.SizingFixed(240),
+		clay.SizingFixed(45), theme.StyleDanger, func() {
+			if a.report == nil || len(a.report.Orphans) == 0 {
+				return
+			}
+			a.confirm(fmt.Sprintf("Delete %d orphaned files", len(a.report.Orphans)), func() {
+				a.deleted(a.report.DeleteOrphans())
+			})
+		})
+
+	a.brokenButton = widgets.NewButton("audit-broken-btn", "Delete Broken", clay.SizingFixed(240),
+		clay.SizingFixed(45), theme.StyleDanger, func() {
+			if a.report == nil || len(a.report.Broken) == 0 {
+				return
+			}
+			a.confirm(fmt.Sprintf("Delete %d broken files", len(a.report.Broken)), func() {
+				a.deleted(a.report.DeleteBroken())
+				// Os arquivos apagados passam a faltar
+				a.start()
+			})
+		})
+
+	a.requeueButton = widgets.NewButton("audit-requeue-btn", "Re-queue Missing", clay.SizingFixed(240),
+		clay.SizingFixed(45), theme.StyleSecondary, a.requeue)
+
+	a.backButton = widgets.NewButton("audit-back-btn", "Back", clay.SizingFixed(240),
+		clay.SizingFixed(45), theme.StyleSecondary, func() {
+			if a.navigator != nil {
+				a.navigator.GoBack()
+			}
+		})
+
+	a.confirmButtons = []*widgets.Button{
+		widgets.NewButton("audit-confirm-yes-btn", "Delete", clay.SizingFixed(240),
+			clay.SizingFixed(45), theme.StyleDanger, func() {
+				pending := a.pending
+				a.pending = nil
+				if pending != nil {
+					pending()
+				}
+			}),
+		widgets.NewButton("audit-confirm-no-btn", "Keep files", clay.SizingFixed(240),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				a.pending = nil
+			}),
+	}
+}
+
+func (a *Audit) InitializeFocus() {
+	layout := ui.GetLayout()
+	if layout != nil {
+		layout.RegisterFocusable(a.runButton)
+		layout.RegisterFocusable(a.orphansButton)
+		layout.RegisterFocusable(a.brokenButton)
+		layout.RegisterFocusable(a.requeueButton)
+		layout.RegisterFocusable(a.backButton)
+		for _, btn := range a.confirmButtons {
+			layout.RegisterFocusable(btn)
+		}
+	}
+}
+
+// start roda a auditoria em background, cancelando uma anterior
+func (a *Audit) start() {
+	if a.writer == nil {
+		return
+	}
+	if a.cancel != nil {
+		a.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancel = cancel
+	a.running = true
+	a.pending = nil
+	a.generation++
+	generation := a.generation
+
+	go func() {
+		report, err := audit.Run(ctx, a.systems, audit.Options{Writer: a.writer, MediaTypes: a.mediaTypes})
+		// Uma execução cancelada não bloqueia esperando o Update
+		select {
+		case a.resultCh <- auditResult{generation: generation, report: report, err: err}:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// confirm pede confirmação antes de uma exclusão em massa
+func (a *Audit) confirm(label string, action func()) {
+	a.pending = action
+	a.pendingLabel = label
+}
+
+func (a *Audit) deleted(count int, err error) {
+	if err != nil {
+		log.Printf("Audit: %v", err)
+		a.status = fmt.Sprintf("Deleted %d files, then failed: %v", count, err)
+		a.isError = true
+		return
+	}
+	log.Printf("Audit: deleted %d files", count)
+	a.status = fmt.Sprintf("Deleted %d files", count)
+	a.isError = false
+}
+
+// requeue envia ao engine os jogos com mídia faltando
+func (a *Audit) requeue() {
+	if a.engine == nil || a.report == nil || len(a.report.Missing) == 0 {
+		return
+	}
+	if err := a.engine.StartTasks(a.report.Tasks()); err != nil {
+		log.Printf("Audit: failed to re-queue: %v", err)
+		if !errors.Is(err, job.ErrRunning) {
+			a.status = "Could not start the scrape"
+			a.isError = true
+			return
+		}
+	}
+
+	if a.navigator != nil {
+		a.navigator.NavigateTo("progress")
+	}
+}
+
+func (a *Audit) Update() {
+	select {
+	case result := <-a.resultCh:
+		if result.generation != a.generation {
+			break
+		}
+		a.running = false
+		if result.err != nil {
+			log.Printf("Audit: %v", result.err)
+			a.status = "Audit failed"
+			a.isError = true
+			return
+		}
+		a.report = result.report
+		a.status = ""
+	default:
+	}
+
+	a.runButton.Label = "Run Audit"
+	if a.running {
+		a.runButton.Label = "Auditing..."
+	}
+	var orphans, broken, missing int
+	if a.report != nil {
+		orphans, broken, missing = len(a.report.Orphans), len(a.report.Broken), len(a.report.Missing)
+	}
+	a.orphansButton.Label = fmt.Sprintf("Delete Orphans (%d)", orphans)
+	a.brokenButton.Label = fmt.Sprintf("Delete Broken (%d)", broken)
+	a.requeueButton.Label = fmt.Sprintf("Re-queue Missing (%d)", missing)
+}
+
+func (a *Audit) Render() {
+	mainStyle := theme.GetMainContainerStyle()
+	contentStyle := theme.GetContentContainerStyle()
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("main-container"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  clay.SizingGrow(float32(core.WINDOW_WIDTH)),
+				Height: clay.SizingGrow(float32(core.WINDOW_HEIGHT)),
+			},
+			Padding:         clay.Padding{Left: spacing.LG, Right: spacing.LG, Top: spacing.LG, Bottom: spacing.LG},
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+				Y: clay.ALIGN_Y_CENTER,
+			},
+		},
+		BackgroundColor: mainStyle.BackgroundColor,
+	}, func() {
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID("content-container"),
+			Layout: clay.LayoutConfig{
+				Sizing: clay.Sizing{
+					Width:  clay.SizingPercent(0.9),
+					Height: clay.SizingFit(0, 0),
+				},
+				Padding:         contentStyle.Padding,
+				ChildGap:        spacing.MD,
+				LayoutDirection: clay.TOP_TO_BOTTOM,
+			},
+			CornerRadius:    clay.CornerRadiusAll(contentStyle.CornerRadius),
+			BackgroundColor: contentStyle.BackgroundColor,
+			Border:          contentStyle.Border,
+		}, func() {
+			widgets.TextXLarge("Artwork audit", ds.Colors.TextPrimary)
+			widgets.TextSmall(fmt.Sprintf("Layout: %s", a.layoutName()), ds.Colors.TextMuted)
+
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID("audit-body"),
+				Layout: clay.LayoutConfig{
+					Sizing: clay.Sizing{
+						Width: clay.SizingGrow(0),
+					},
+					ChildGap:        spacing.LG,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				a.renderButtons()
+				a.renderReport()
+			})
+
+			if a.status != "" {
+				color := ds.Colors.Success
+				if a.isError {
+					color = ds.Colors.Danger
+				}
+				widgets.TextSmall(a.status, color)
+			}
+		})
+	})
+}
+
+// renderButtons mostra as ações, ou a confirmação de uma exclusão
+func (a *Audit) renderButtons() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("buttons-container"),
+		Layout: clay.LayoutConfig{
+			ChildGap:        spacing.MD,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+	}, func() {
+		if a.pending != nil {
+			widgets.TextBase(a.pendingLabel+"?", ds.Colors.Warning)
+			for _, btn := range a.confirmButtons {
+				btn.Render()
+			}
+			return
+		}
+
+		a.runButton.Render()
+		if a.report != nil {
+			a.orphansButton.Render()
+			a.brokenButton.Render()
+			a.requeueButton.Render()
+		}
+		a.backButton.Render()
+	})
+}
+
+// renderReport mostra os totais e os primeiros arquivos com problema
+func (a *Audit) renderReport() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("audit-report"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width: clay.SizingGrow(0),
+			},
+			ChildGap:        spacing.XS,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+		Clip: clay.ClipElementConfig{Vertical: true},
+	}, func() {
+		report := a.report
+		if report == nil {
+			message := "Run an audit to check the artwork of every game."
+			if a.running {
+				message = "Reading the artwork of every game..."
+			}
+			widgets.TextBaseWrapped(message, ds.Colors.TextMuted)
+			return
+		}
+
+		widgets.TextBase(fmt.Sprintf("%d games checked", report.Games), ds.Colors.TextPrimary)
+		widgets.TextSmall(fmt.Sprintf("Orphaned files: %d", len(report.Orphans)), ds.Colors.TextSecondary)
+		var missing []string
+		for _, mediaType := range a.mediaTypes {
+			if count := report.MissingCount(mediaType); count > 0 {
+				missing = append(missing, fmt.Sprintf("%s %d", mediaType, count))
+			}
+		}
+		if len(missing) == 0 {
+			missing = append(missing, "none")
+		}
+		widgets.TextSmall("Missing: "+strings.Join(missing, ", "), ds.Colors.TextSecondary)
+		widgets.TextSmall(fmt.Sprintf("Empty or unreadable: %d", len(report.Broken)), ds.Colors.TextSecondary)
+		widgets.TextSmall(fmt.Sprintf("Wrong size: %d", len(report.WrongSize)), ds.Colors.TextSecondary)
+
+		var details []string
+		for _, path := range report.Orphans {
+			details = append(details, "Orphan: "+a.shortPath(path))
+		}
+		for _, file := range report.Broken {
+			details = append(details, fmt.Sprintf("Broken: %s (%s)", a.shortPath(file.Path), file.Reason))
+		}
+		for _, file := range report.WrongSize {
+			details = append(details, fmt.Sprintf("Size: %s (%s)", a.shortPath(file.Path), file.Reason))
+		}
+		for _, detail := range details[:min(len(details), maxAuditDetails)] {
+			widgets.TextSmall(detail, ds.Colors.TextMuted)
+		}
+		if len(details) > maxAuditDetails {
+			widgets.TextSmall(fmt.Sprintf("... and %d more", len(details)-maxAuditDetails), ds.Colors.TextMuted)
+		}
+	})
+}
+
+func (a *Audit) layoutName() string {
+	if a.writer == nil {
+		return "none"
+	}
+	return a.writer.Layout.Name()
+}
+
+// shortPath mostra só a pasta de mídia e o nome do arquivo
+func (a *Audit) shortPath(path string) string {
+	return filepath.Join(filepath.Base(filepath.Dir(path)), filepath.Base(path))
+}
+
+func (a *Audit) HandleInput(inputType input.InputType) {
+	if inputType == input.InputBack {
+		switch {
+		case a.pending != nil:
+			a.pending = nil
+		case a.navigator != nil:
+			a.navigator.GoBack()
+		}
+		return
+	}
+
+	layout := ui.GetLayout()
+	if layout == nil || !layout.HandleSpatialInput(inputType) {
+		log.Printf("Audit: Input %d not handled", inputType)
+	}
+}
+
+func (a *Audit) OnEnter(navigator Navigator) {
+	a.navigator = navigator
+	a.status = ""
+	if a.report == nil && !a.running {
+		a.start()
+	}
+	log.Println("Entering Audit screen")
+}
+
+func (a *Audit) OnExit() {
+	a.pending = nil
+	log.Println("Exiting Audit screen")
+}
@@ -0,0 +1,368 @@
+package screen
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/TotallyGamerJet/clay"
+
+	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/job"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/output"
+	"retroart-sdl2/internal/scraper"
+	"retroart-sdl2/internal/theme"
+	"retroart-sdl2/internal/ui"
+	"retroart-sdl2/internal/ui/widgets"
+)
+
+// detailArtSize é o tamanho da arte mostrada ao lado dos metadados
+const detailArtSize = 320
+
+// detailArtTypes define a ordem de preferência da arte mostrada
+var detailArtTypes = []scraper.MediaType{
+	scraper.MediaMixed, scraper.MediaBoxFront, scraper.MediaBox3D, scraper.MediaScreenshot, scraper.MediaTitle,
+}
+
+// detailResult é o resultado do carregamento em background de um jogo
+type detailResult struct {
+	index  int
+	result *scraper.Result
+	art    image.Image
+}
+
+// GameDetail mostra os metadados de um jogo ao lado da arte já gravada.
+// É aberta por Open para um jogo; Previous e Next percorrem os jogos do
+// mesmo sistema.
+type GameDetail struct {
+	navigator Navigator
+	engine    *job.Engine
+	writer    *output.Writer
+	prefs     scraper.MetadataPrefs
+	regions   *scraper.RegionResolver
+
+	systems map[string]library.System // Sistemas por ID
+	games   []library.Game            // Jogos do sistema do jogo aberto
+	index   int
+	result  *scraper.Result
+	loading bool
+
+	art     *widgets.Image
+	buttons []*widgets.Button
+	ctx     context.Context
+	cancel  context.CancelFunc
+	loadCh  chan detailResult
+}
+
+func NewGameDetail(systems []library.System, engine *job.Engine, writer *output.Writer, prefs scraper.MetadataPrefs, regions *scraper.RegionResolver) *GameDetail {
+	d := &GameDetail{
+		engine:  engine,
+		writer:  writer,
+		prefs:   prefs,
+		regions: regions,
+		systems: make(map[string]library.System, len(systems)),
+		loadCh:  make(chan detailResult, 1),
+	}
+	for _, system := range systems {
+		d.systems[system.ID()] = system
+	}
+
+	d.initializeWidgets()
+	d.InitializeFocus()
+
+	return d
+}
+
+func (d *GameDetail) initializeWidgets() {
+	d.art = widgets.NewImage("detail-art", clay.SizingFixed(detailArtSize), clay.SizingFixed(detailArtSize))
+
+	d.buttons = []*widgets.Button{
+		widgets.NewButton("detail-prev-btn", "Previous", clay.SizingFixed(200),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				d.show(d.index - 1)
+			}),
+		widgets.NewButton("detail-next-btn", "Next", clay.SizingFixed(200),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				d.show(d.index + 1)
+			}),
+		widgets.NewButton("detail-back-btn", "Back", clay.SizingFixed(200),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				if d.navigator != nil {
+					d.navigator.GoBack()
+				}
+			}),
+	}
+}
+
+func (d *GameDetail) InitializeFocus() {
+	layout := ui.GetLayout()
+	if layout != nil {
+		for _, btn := range d.buttons {
+			layout.RegisterFocusable(btn)
+		}
+	}
+}
+
+// Open escolhe o jogo mostrado na próxima entrada da tela
+func (d *GameDetail) Open(game library.Game) {
+	d.games = []library.Game{game}
+	d.index = 0
+
+	system, ok := d.systems[game.SystemID]
+	if !ok {
+		return
+	}
+	for i, candidate := range system.Games {
+		if candidate.Path == game.Path {
+			d.games = system.Games
+			d.index = i
+			return
+		}
+	}
+}
+
+// show troca o jogo exibido e carrega seus dados em background
+func (d *GameDetail) show(index int) {
+	if len(d.games) == 0 {
+		return
+	}
+	d.index = (index + len(d.games)) % len(d.games)
+	d.result = nil
+	d.art.SetImage(nil)
+	d.loading = true
+
+	go d.load(d.ctx, d.index, d.games[d.index])
+}
+
+// load busca a correspondência conhecida e decodifica a arte gravada
+func (d *GameDetail) load(ctx context.Context, index int, game library.Game) {
+	if ctx == nil {
+		return
+	}
+
+	loaded := detailResult{index: index}
+	if d.engine != nil {
+		loaded.result, _ = d.engine.Known(game)
+	}
+	if d.writer != nil {
+		loaded.art = loadArt(d.writer, game)
+	}
+
+	select {
+	case d.loadCh <- loaded:
+	case <-ctx.Done():
+	}
+}
+
+// loadArt decodifica a primeira arte gravada do jogo
+func loadArt(writer *output.Writer, game library.Game) image.Image {
+	for _, mediaType := range detailArtTypes {
+		path, ok := writer.Find(game, mediaType)
+		if !ok {
+			continue
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			log.Printf("GameDetail: %v", err)
+			continue
+		}
+		img, _, err := imaging.Decode(file)
+		file.Close()
+		if err != nil {
+			log.Printf("GameDetail: %s: %v", path, err)
+			continue
+		}
+		return imaging.Process(img, imaging.Options{
+			Width:  detailArtSize,
+			Height: detailArtSize,
+			Mode:   imaging.ModePad,
+		})
+	}
+	return nil
+}
+
+func (d *GameDetail) Update() {
+	for {
+		select {
+		case loaded := <-d.loadCh:
+			if loaded.index != d.index {
+				continue
+			}
+			d.loading = false
+			d.result = loaded.result
+			d.art.SetImage(loaded.art)
+		default:
+			return
+		}
+	}
+}
+
+func (d *GameDetail) Render() {
+	mainStyle := theme.GetMainContainerStyle()
+	contentStyle := theme.GetContentContainerStyle()
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("main-container"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  clay.SizingGrow(float32(core.WINDOW_WIDTH)),
+				Height: clay.SizingGrow(float32(core.WINDOW_HEIGHT)),
+			},
+			Padding:         clay.Padding{Left: spacing.LG, Right: spacing.LG, Top: spacing.LG, Bottom: spacing.LG},
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+				Y: clay.ALIGN_Y_CENTER,
+			},
+		},
+		BackgroundColor: mainStyle.BackgroundColor,
+	}, func() {
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID("content-container"),
+			Layout: clay.LayoutConfig{
+				Sizing: clay.Sizing{
+					Width:  clay.SizingPercent(0.95),
+					Height: clay.SizingFit(0, 0),
+				},
+				Padding:         contentStyle.Padding,
+				ChildGap:        spacing.MD,
+				LayoutDirection: clay.TOP_TO_BOTTOM,
+			},
+			CornerRadius:    clay.CornerRadiusAll(contentStyle.CornerRadius),
+			BackgroundColor: contentStyle.BackgroundColor,
+			Border:          contentStyle.Border,
+		}, func() {
+			if len(d.games) == 0 {
+				widgets.TextXLarge("Game info", ds.Colors.TextPrimary)
+				widgets.TextBase("No games found.", ds.Colors.TextSecondary)
+				d.buttons[len(d.buttons)-1].Render()
+				return
+			}
+
+			game := d.games[d.index]
+			title := game.DisplayName()
+			if d.result != nil {
+				title = d.result.DisplayTitle(d.gamePrefs())
+			}
+			widgets.TextXLarge(title, ds.Colors.TextPrimary)
+			widgets.TextSmall(fmt.Sprintf("%s | %s | %d / %d", d.systems[game.SystemID].Name(), game.FileName, d.index+1, len(d.games)), ds.Colors.TextSecondary)
+
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID("detail-body"),
+				Layout: clay.LayoutConfig{
+					Sizing: clay.Sizing{
+						Width: clay.SizingGrow(0),
+					},
+					ChildGap:        spacing.LG,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				d.art.Render()
+				d.renderMetadata()
+			})
+
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID("buttons-container"),
+				Layout: clay.LayoutConfig{
+					ChildGap:        spacing.MD,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				for _, btn := range d.buttons {
+					btn.Render()
+				}
+			})
+		})
+	})
+}
+
+// renderMetadata mostra os campos conhecidos e a sinopse com quebra de linha
+func (d *GameDetail) renderMetadata() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("detail-metadata"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  clay.SizingGrow(0),
+				Height: clay.SizingFixed(detailArtSize),
+			},
+			ChildGap:        spacing.XS,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+		Clip: clay.ClipElementConfig{Vertical: true},
+	}, func() {
+		switch {
+		case d.loading:
+			widgets.TextBase("Loading...", ds.Colors.TextMuted)
+			return
+		case d.result == nil:
+			widgets.TextBaseWrapped("No metadata yet. Scrape this system to fetch it.", ds.Colors.TextMuted)
+			return
+		}
+
+		prefs := d.gamePrefs()
+		metadata := &d.result.Metadata
+		field := func(label, value string) {
+			if value != "" {
+				widgets.TextSmallWrapped(label+": "+value, ds.Colors.TextSecondary)
+			}
+		}
+		field("Released", metadata.ReleaseDate(prefs))
+		field("Developer", metadata.Developer)
+		field("Publisher", metadata.Publisher)
+		field("Genre", strings.Join(metadata.GenreNames(prefs), ", "))
+		field("Players", metadata.Players)
+		if metadata.Rating > 0 {
+			field("Rating", fmt.Sprintf("%.1f / 5", metadata.Rating*5))
+		}
+		field("Source", d.result.Provider)
+
+		if description := metadata.Description(prefs); description != "" {
+			widgets.TextBaseWrapped(description, ds.Colors.TextPrimary)
+		}
+	})
+}
+
+// gamePrefs ordena as regiões dos textos com as regiões da ROM primeiro,
+// as mesmas que o scraping usou
+func (d *GameDetail) gamePrefs() scraper.MetadataPrefs {
+	return d.regions.Prefs(d.prefs, d.result.Regions)
+}
+
+func (d *GameDetail) HandleInput(inputType input.InputType) {
+	if inputType == input.InputBack {
+		if d.navigator != nil {
+			d.navigator.GoBack()
+		}
+		return
+	}
+
+	layout := ui.GetLayout()
+	if layout == nil || !layout.HandleSpatialInput(inputType) {
+		log.Printf("GameDetail: Input %d not handled", inputType)
+	}
+}
+
+func (d *GameDetail) OnEnter(navigator Navigator) {
+	d.navigator = navigator
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	d.show(d.index)
+	log.Println("Entering GameDetail screen")
+}
+
+func (d *GameDetail) OnExit() {
+	if d.cancel != nil {
+		d.cancel()
+	}
+	log.Println("Exiting GameDetail screen")
+}
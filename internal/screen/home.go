@@ -1,6 +1,8 @@
 package screen
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 
@@ -8,6 +10,8 @@ import (
 
 	"retroart-sdl2/internal/core"
 	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/job"
+	"retroart-sdl2/internal/library"
 	"retroart-sdl2/internal/theme"
 	"retroart-sdl2/internal/ui"
 	"retroart-sdl2/internal/ui/widgets"
@@ -16,12 +20,16 @@ import (
 type Home struct {
 	navigator    Navigator // Use Navigator interface instead of concrete Manager
 	buttons      []*widgets.Button
-	checkboxList *widgets.CheckboxList[string]
+	resumeButton *widgets.Button
+	checkboxList *widgets.CheckboxList[library.System]
 	inputText    *widgets.InputText
+	systems      []library.System
+	engine       *job.Engine
+	details      *GameDetail
 }
 
-func NewHome() *Home {
-	home := &Home{}
+func NewHome(systems []library.System, engine *job.Engine, details *GameDetail) *Home {
+	home := &Home{systems: systems, engine: engine, details: details}
 
 	home.initializeWidgets()
 	home.InitializeFocus()
@@ -52,44 +60,73 @@ func (h *Home) initializeWidgets() {
 				log.Println("Exit button pressed")
 				os.Exit(0)
 			}),
+		widgets.NewButton(
+			"review-button",
+			"Review Matches",
+			clay.SizingFixed(220),
+			clay.SizingFixed(45),
+			theme.StyleSecondary,
+			func() {
+				if h.navigator != nil {
+					h.navigator.NavigateTo("review")
+				}
+			}),
+		widgets.NewButton(
+			"game-info-button",
+			"Game Info",
+			clay.SizingFixed(220),
+			clay.SizingFixed(45),
+			theme.StyleSecondary,
+			func() {
+				h.openGameInfo()
+			}),
+		widgets.NewButton(
+			"audit-button",
+			"Artwork Audit",
+			clay.SizingFixed(220),
+			clay.SizingFixed(45),
+			theme.StyleSecondary,
+			func() {
+				if h.navigator != nil {
+					h.navigator.NavigateTo("audit")
+				}
+			}),
 		widgets.NewButton(
 			"test-selected-button",
-			"Show Selected",
+			"Scrape Selected",
 			clay.SizingFixed(220),
 			clay.SizingFixed(45),
 			theme.StyleSecondary,
 			func() {
-				selectedItems := h.checkboxList.GetSelectedItems()
-				log.Printf("Selected games: %v", selectedItems)
+				h.startScrape(h.checkboxList.GetSelectedValues())
 			}),
 	}
 
-	// Criar dados de teste para o checkbox list
-	testItems := []widgets.CheckboxListItem[string]{
-		{Label: "Arcade", Value: "game1", Selected: false},
-		{Label: "Gameboy", Value: "game2", Selected: true},
-		{Label: "Gameboy color", Value: "game3", Selected: false},
-		{Label: "Gameboy Advance", Value: "game4", Selected: false},
-		{Label: "Nintendo Entertainment System", Value: "game5", Selected: true},
-		{Label: "Super Nintendo", Value: "game6", Selected: false},
-		{Label: "Master System", Value: "game7", Selected: false},
-		{Label: "Mega Drive", Value: "game8", Selected: false},
-		{Label: "Nintendo 64", Value: "game9", Selected: false},
-		{Label: "Sega Saturn", Value: "game10", Selected: true},
-		{Label: "Atari 2600", Value: "game11", Selected: false},
-		{Label: "Game & Watch", Value: "game12", Selected: false},
-		{Label: "CPS II", Value: "game13", Selected: false},
-		{Label: "NeoGeo", Value: "game14", Selected: true},
-		{Label: "GameGear", Value: "game15", Selected: false},
-		{Label: "PlayStation", Value: "game16", Selected: false},
-		{Label: "PSP", Value: "game16", Selected: false},
+	// Só aparece quando um job anterior foi interrompido (crash, falta de energia)
+	h.resumeButton = widgets.NewButton(
+		"resume-button",
+		"Resume Scrape",
+		clay.SizingFixed(220),
+		clay.SizingFixed(45),
+		theme.StylePrimary,
+		func() {
+			h.resumeScrape()
+		})
+
+	// Lista de sistemas encontrados na pasta de ROMs
+	systemItems := make([]widgets.CheckboxListItem[library.System], 0, len(h.systems))
+	for _, system := range h.systems {
+		systemItems = append(systemItems, widgets.CheckboxListItem[library.System]{
+			Label: fmt.Sprintf("%s (%d)", system.Name(), system.GameCount()),
+			Value: system,
+		})
 	}
 
 	h.checkboxList = widgets.NewCheckboxList(
 		"consoles-checkbox-list",
 		clay.SizingGrow(0),
 		clay.SizingFixed(610),
-		testItems,
+		systemItems,
 	)
 
 	// Criar campo de entrada de texto
@@ -108,12 +145,90 @@ func (h *Home) initializeWidgets() {
 	)
 }
 
+// startScrape dispara o scraping dos sistemas selecionados em background
+func (h *Home) startScrape(systems []library.System) {
+	if h.engine == nil {
+		log.Println("Home: no job engine configured")
+		return
+	}
+	if len(systems) == 0 {
+		log.Println("Home: no systems selected")
+		return
+	}
+
+	if err := h.engine.Start(systems); err != nil {
+		log.Printf("Home: failed to start scrape: %v", err)
+		if !errors.Is(err, job.ErrRunning) {
+			return
+		}
+	}
+
+	if h.navigator != nil {
+		h.navigator.NavigateTo("progress")
+	}
+}
+
+// openGameInfo abre os detalhes do primeiro jogo do sistema em foco na lista,
+// ou do primeiro sistema marcado
+func (h *Home) openGameInfo() {
+	if h.details == nil || h.navigator == nil {
+		return
+	}
+
+	system, ok := h.checkboxList.FocusedValue()
+	if !ok {
+		selected := h.checkboxList.GetSelectedValues()
+		if len(selected) == 0 {
+			log.Println("Home: no system focused")
+			return
+		}
+		system = selected[0]
+	}
+	if len(system.Games) == 0 {
+		log.Printf("Home: %s has no games", system.Name())
+		return
+	}
+
+	h.details.Open(system.Games[0])
+	h.navigator.NavigateTo("game")
+}
+
+// resumeScrape retoma as tarefas pendentes do job interrompido
+func (h *Home) resumeScrape() {
+	if h.engine == nil {
+		return
+	}
+	if err := h.engine.ResumeInterrupted(); err != nil {
+		log.Printf("Home: failed to resume scrape: %v", err)
+		if !errors.Is(err, job.ErrRunning) {
+			return
+		}
+	}
+
+	if h.navigator != nil {
+		h.navigator.NavigateTo("progress")
+	}
+}
+
+// OnJobEvent registra o progresso do scraping
+func (h *Home) OnJobEvent(event job.Event) {
+	switch event.Type {
+	case job.EventMatched:
+		log.Printf("Scrape: %s matched '%s' on %s", event.Task.Game.FileName, event.Result.Title, event.Provider)
+	case job.EventFailed:
+		log.Printf("Scrape: %s: %v", event.Task.Game.FileName, event.Err)
+	case job.EventFinished:
+		log.Printf("Scrape: finished (err=%v)", event.Err)
+	}
+}
+
 // InitializeFocus configura os widgets no sistema de navegação espacial
 func (h *Home) InitializeFocus() {
 	layout := ui.GetLayout()
 	if layout != nil {
 		layout.RegisterFocusable(h.inputText)
 		layout.RegisterFocusable(h.checkboxList)
+		layout.RegisterFocusable(h.resumeButton)
 
 		for _, button := range h.buttons {
 			layout.RegisterFocusable(button)
@@ -126,7 +241,11 @@ func (h *Home) InitializeFocus() {
 // Implementação da interface Screen
 
 func (h *Home) Update() {
-	// Lógica de atualização se necessária
+	if h.engine != nil {
+		if pending := h.engine.Interrupted(); pending > 0 {
+			h.resumeButton.Label = fmt.Sprintf("Resume Scrape (%d left)", pending)
+		}
+	}
 }
 
 // Render - interface Screen (wrapper para o método Clay)
@@ -139,8 +258,8 @@ func (h *Home) Render() {
 		Id: clay.ID("main-container"),
 		Layout: clay.LayoutConfig{
 			Sizing: clay.Sizing{
-				Width:  clay.SizingGrow(core.WINDOW_WIDTH),
-				Height: clay.SizingGrow(core.WINDOW_HEIGHT),
+				Width:  clay.SizingGrow(float32(core.WINDOW_WIDTH)),
+				Height: clay.SizingGrow(float32(core.WINDOW_HEIGHT)),
 			},
 			Padding:         clay.Padding{Left: spacing.LG, Right: spacing.LG, Top: spacing.LG, Bottom: spacing.LG},
 			ChildGap:        spacing.MD,
@@ -174,7 +293,7 @@ func (h *Home) Render() {
 				},
 			}, func() {
 				ds := theme.DefaultDesignSystem()
-				widgets.TextLarge("Games list", ds.Colors.TextPrimary)
+				widgets.TextLarge("Systems", ds.Colors.TextPrimary)
 			})
 
 			h.checkboxList.Render()
@@ -227,6 +346,10 @@ func (h *Home) Render() {
 				// Campo de entrada de texto
 				h.inputText.Render()
 
+				if h.engine != nil && h.engine.Interrupted() > 0 {
+					h.resumeButton.Render()
+				}
+
 				// Renderizar todos os botões focáveis
 				for _, button := range h.buttons {
 					button.Render()
@@ -0,0 +1,121 @@
+package screen_test
+
+import (
+	"testing"
+
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/library"
+	"retroart-sdl2/internal/scraper"
+	"retroart-sdl2/internal/screen"
+	"retroart-sdl2/internal/ui/widgets"
+	"retroart-sdl2/internal/uitest"
+)
+
+// blankScreen é uma tela vazia, destino das navegações testadas
+type blankScreen struct {
+	navigator screen.Navigator
+}
+
+func (b *blankScreen) Update()                            {}
+func (b *blankScreen) Render()                            {}
+func (b *blankScreen) HandleInput(input.InputType)        {}
+func (b *blankScreen) OnEnter(navigator screen.Navigator) { b.navigator = navigator }
+func (b *blankScreen) OnExit()                            {}
+
+func testSystems() []library.System {
+	return []library.System{
+		{
+			Definition: library.SystemDefinition{ID: "snes"},
+			Folder:     "Super Nintendo (SFC)",
+			Games:      []library.Game{{Name: "Alpha", Path: "/roms/SFC/Alpha.sfc", SystemID: "snes"}},
+		},
+		{
+			Definition: library.SystemDefinition{ID: "megadrive"},
+			Folder:     "Mega Drive (MD)",
+			Games:      []library.Game{{Name: "Bravo", Path: "/roms/MD/Bravo.md", SystemID: "megadrive"}},
+		},
+	}
+}
+
+func TestHomeListKeepsDirectionsUntilItsEnd(t *testing.T) {
+	d := uitest.New(t)
+	d.Show("home", screen.NewHome(testSystems(), nil, nil))
+
+	// Sem foco, o primeiro input foca o primeiro widget
+	d.Play(input.NewScript().Tap(input.InputDown))
+	d.AssertFocus("consoles-checkbox-list")
+	list := uitest.Widget[*widgets.CheckboxList[library.System]](d, "consoles-checkbox-list")
+
+	// A lista consome Down enquanto tem itens abaixo
+	d.Play(input.NewScript().Tap(input.InputDown))
+	d.AssertFocus("consoles-checkbox-list")
+	if system, _ := list.FocusedValue(); system.ID() != "megadrive" {
+		t.Errorf("focused system = %q, want megadrive", system.ID())
+	}
+
+	// No fim da lista a navegação espacial leva aos botões, e Left volta
+	d.Play(input.NewScript().Tap(input.InputDown))
+	if focus := d.Focus(); focus == "consoles-checkbox-list" || focus == "" {
+		t.Fatalf("focus stayed on %q at the end of the list", focus)
+	}
+	d.Play(input.NewScript().Tap(input.InputLeft))
+	d.AssertFocus("consoles-checkbox-list")
+
+	// Confirm marca o sistema em foco
+	d.Play(input.NewScript().Tap(input.InputConfirm))
+	if selected := list.GetSelectedValues(); len(selected) != 1 || selected[0].ID() != "megadrive" {
+		t.Errorf("selected systems = %v, want megadrive", selected)
+	}
+}
+
+func TestHomeOpensGameInfoAndGoesBack(t *testing.T) {
+	d := uitest.New(t)
+	systems := testSystems()
+	details := screen.NewGameDetail(systems, nil, nil, scraper.MetadataPrefs{}, nil)
+	d.Manager.AddScreen("game", details)
+	d.Show("home", screen.NewHome(systems, nil, details))
+
+	d.Play(input.NewScript().Tap(input.InputDown, input.InputDown, input.InputRight))
+	for range 10 {
+		if d.Focus() == "game-info-button" {
+			break
+		}
+		d.Play(input.NewScript().Tap(input.InputUp))
+	}
+	d.AssertFocus("game-info-button")
+
+	d.Play(input.NewScript().Tap(input.InputConfirm))
+	if got := d.Manager.GetCurrentScreenName(); got != "game" {
+		t.Fatalf("screen after Game Info = %q, want game", got)
+	}
+
+	// Back volta pela tela, não pelo widget focado
+	d.Play(input.NewScript().Tap(input.InputBack))
+	if got := d.Manager.GetCurrentScreenName(); got != "home" {
+		t.Errorf("screen after Back = %q, want home", got)
+	}
+}
+
+func TestHomeNavigatesToReview(t *testing.T) {
+	d := uitest.New(t)
+	review := &blankScreen{}
+	d.Manager.AddScreen("review", review)
+	d.Show("home", screen.NewHome(testSystems(), nil, nil))
+
+	d.Play(input.NewScript().Tap(input.InputDown, input.InputDown, input.InputRight))
+	for range 10 {
+		if d.Focus() == "review-button" {
+			break
+		}
+		d.Play(input.NewScript().Tap(input.InputUp))
+	}
+	d.AssertFocus("review-button")
+
+	d.Play(input.NewScript().Tap(input.InputConfirm))
+	if got := d.Manager.GetCurrentScreenName(); got != "review" {
+		t.Fatalf("screen = %q, want review", got)
+	}
+	if review.navigator == nil {
+		t.Error("review screen entered without a navigator")
+	}
+}
@@ -1,10 +1,17 @@
 package screen
 
 import (
+	"time"
+
 	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/job"
 	"retroart-sdl2/internal/ui"
+	"retroart-sdl2/internal/ui/widgets"
 )
 
+// toastDuration é quanto tempo uma notificação fica visível
+const toastDuration = 3 * time.Second
+
 // Navigator interface para navegação entre telas
 // Permite que telas naveguem sem conhecer o ScreenManager diretamente
 type Navigator interface {
@@ -22,6 +29,19 @@ type Screen interface {
 	OnExit()
 }
 
+// EventHandler é implementado por telas que querem os InputEvents completos,
+// com as fases de soltura e pressão longa e os tempos. Nas demais o Manager
+// oferece o evento ao widget focado e entrega os pressionamentos e repetições
+// não consumidos em HandleInput.
+type EventHandler interface {
+	HandleInputEvent(event input.InputEvent)
+}
+
+// JobListener é implementado por telas que acompanham o progresso dos jobs
+type JobListener interface {
+	OnJobEvent(event job.Event)
+}
+
 // Gerenciador de telas
 type Manager struct {
 	screens       map[string]Screen
@@ -29,6 +49,8 @@ type Manager struct {
 	currentName   string
 	layout        *ui.Layout
 	history       []string // Histórico de navegação para GoBack()
+	engine        *job.Engine
+	toast         *widgets.Toast // Notificações mostradas sobre qualquer tela
 }
 
 func NewManager(layout *ui.Layout) *Manager {
@@ -36,6 +58,7 @@ func NewManager(layout *ui.Layout) *Manager {
 		screens: make(map[string]Screen),
 		layout:  layout,
 		history: make([]string, 0),
+		toast:   widgets.NewToast("toast"),
 	}
 }
 
@@ -84,16 +107,51 @@ func (sm *Manager) setCurrentScreenInternal(name string, addToHistory bool) {
 	}
 }
 
+// SetEngine conecta o engine de jobs cujos eventos serão drenados a cada frame
+func (sm *Manager) SetEngine(engine *job.Engine) {
+	sm.engine = engine
+}
+
+// ShowToast mostra uma notificação curta sobre a tela atual
+func (sm *Manager) ShowToast(message string) {
+	sm.toast.Show(message, toastDuration)
+}
+
 func (sm *Manager) Update() {
+	sm.drainJobEvents()
+	sm.toast.Update()
+
 	if sm.currentScreen != nil {
 		sm.currentScreen.Update()
 	}
 }
 
+// drainJobEvents entrega todos os eventos pendentes do engine para as telas
+// que implementam JobListener, mesmo as que não estão visíveis
+func (sm *Manager) drainJobEvents() {
+	if sm.engine == nil {
+		return
+	}
+
+	for {
+		select {
+		case event := <-sm.engine.Events():
+			for _, screen := range sm.screens {
+				if listener, ok := screen.(JobListener); ok {
+					listener.OnJobEvent(event)
+				}
+			}
+		default:
+			return
+		}
+	}
+}
+
 func (sm *Manager) Render() {
 	if sm.currentScreen != nil {
 		sm.layout.Render(func() {
 			sm.currentScreen.Render()
+			sm.toast.Render()
 		})
 	}
 }
@@ -105,3 +163,23 @@ func (sm *Manager) HandleInput(inputType input.InputType) {
 
 	sm.currentScreen.HandleInput(inputType)
 }
+
+// HandleEvent entrega um InputEvent à tela atual. O widget focado recebe
+// primeiro as fases que a lista usa para acelerar e para a pressão longa;
+// Back fica sempre com a tela.
+func (sm *Manager) HandleEvent(event input.InputEvent) {
+	if sm.currentScreen == nil {
+		return
+	}
+
+	if handler, ok := sm.currentScreen.(EventHandler); ok {
+		handler.HandleInputEvent(event)
+		return
+	}
+	if event.Type != input.InputBack && sm.layout != nil && sm.layout.HandleSpatialEvent(event) {
+		return
+	}
+	if event.Pressed() {
+		sm.currentScreen.HandleInput(event.Type)
+	}
+}
@@ -0,0 +1,447 @@
+package screen
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/TotallyGamerJet/clay"
+
+	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/httpclient"
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/job"
+	"retroart-sdl2/internal/theme"
+	"retroart-sdl2/internal/ui"
+	"retroart-sdl2/internal/ui/widgets"
+)
+
+// maxVisibleSystems limita quantos sistemas aparecem na lista de progresso
+const maxVisibleSystems = 8
+
+// systemProgress acumula o progresso de um sistema
+type systemProgress struct {
+	name  string
+	done  int
+	total int
+}
+
+// Progress mostra o andamento de um job de scraping
+type Progress struct {
+	navigator      Navigator
+	engine         *job.Engine
+	quotas         *httpclient.Quotas
+	progressBar    *widgets.ProgressBar
+	pauseButton    *widgets.Button
+	cancelButton   *widgets.Button
+	confirmButtons []*widgets.Button
+	confirming     bool
+
+	running    bool
+	cancelled  bool
+	systems    []*systemProgress
+	systemsMap map[string]*systemProgress
+	current    string
+	total      int
+	done       int
+	succeeded  int
+	failed     int
+	skipped    int
+	startedAt  time.Time
+	finishedAt time.Time
+}
+
+func NewProgress(engine *job.Engine, quotas *httpclient.Quotas) *Progress {
+	progress := &Progress{
+		engine:     engine,
+		quotas:     quotas,
+		systemsMap: make(map[string]*systemProgress),
+	}
+
+	progress.initializeWidgets()
+	progress.InitializeFocus()
+
+	return progress
+}
+
+func (p *Progress) initializeWidgets() {
+	p.progressBar = widgets.NewProgressBar("progress-bar", clay.SizingGrow(0))
+
+	p.pauseButton = widgets.NewButton("progress-pause-btn", "Pause", clay.SizingFixed(220),
+		clay.SizingFixed(45), theme.StylePrimary, func() {
+			if p.engine.IsPaused() {
+				p.engine.Resume()
+			} else {
+				p.engine.Pause()
+			}
+		})
+
+	p.cancelButton = widgets.NewButton("progress-cancel-btn", "Cancel", clay.SizingFixed(220),
+		clay.SizingFixed(45), theme.StyleDanger, func() {
+			if p.running {
+				p.confirming = true
+			} else if p.navigator != nil {
+				p.navigator.GoBack()
+			}
+		})
+
+	p.confirmButtons = []*widgets.Button{
+		widgets.NewButton("progress-confirm-yes-btn", "Cancel scrape", clay.SizingFixed(220),
+			clay.SizingFixed(45), theme.StyleDanger, func() {
+				p.confirming = false
+				p.engine.Cancel()
+				if p.navigator != nil {
+					p.navigator.GoBack()
+				}
+			}),
+		widgets.NewButton("progress-confirm-no-btn", "Keep scraping", clay.SizingFixed(220),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				p.confirming = false
+			}),
+	}
+}
+
+func (p *Progress) InitializeFocus() {
+	layout := ui.GetLayout()
+	if layout != nil {
+		layout.RegisterFocusable(p.pauseButton)
+		layout.RegisterFocusable(p.cancelButton)
+		for _, btn := range p.confirmButtons {
+			layout.RegisterFocusable(btn)
+		}
+	}
+}
+
+// OnJobEvent atualiza os contadores a partir dos eventos do engine
+func (p *Progress) OnJobEvent(event job.Event) {
+	switch event.Type {
+	case job.EventQueued:
+		if !p.running {
+			p.reset(event.Time)
+		}
+		p.total++
+		p.system(event.Task.System).total++
+	case job.EventStarted:
+		p.current = event.Task.Game.DisplayName()
+	case job.EventCompleted:
+		p.succeeded++
+		p.markDone(event.Task)
+	case job.EventFailed:
+		p.failed++
+		p.markDone(event.Task)
+	case job.EventSkipped:
+		p.skipped++
+		p.markDone(event.Task)
+	case job.EventFinished:
+		p.running = false
+		p.cancelled = event.Err != nil
+		p.current = ""
+		p.finishedAt = event.Time
+		p.confirming = false
+	}
+}
+
+// reset limpa o estado para um novo job
+func (p *Progress) reset(startedAt time.Time) {
+	p.running = true
+	p.cancelled = false
+	p.systems = nil
+	p.systemsMap = make(map[string]*systemProgress)
+	p.current = ""
+	p.total, p.done = 0, 0
+	p.succeeded, p.failed, p.skipped = 0, 0, 0
+	p.startedAt = startedAt
+	p.finishedAt = time.Time{}
+}
+
+func (p *Progress) system(name string) *systemProgress {
+	sp, ok := p.systemsMap[name]
+	if !ok {
+		sp = &systemProgress{name: name}
+		p.systemsMap[name] = sp
+		p.systems = append(p.systems, sp)
+	}
+	return sp
+}
+
+func (p *Progress) markDone(task job.Task) {
+	p.done++
+	p.system(task.System).done++
+}
+
+// elapsed retorna o tempo decorrido do job atual ou do último job
+func (p *Progress) elapsed() time.Duration {
+	if p.startedAt.IsZero() {
+		return 0
+	}
+	if p.running {
+		return time.Since(p.startedAt)
+	}
+	return p.finishedAt.Sub(p.startedAt)
+}
+
+// eta estima o tempo restante a partir da média por jogo
+func (p *Progress) eta() (time.Duration, bool) {
+	if !p.running || p.done == 0 {
+		return 0, false
+	}
+	perGame := p.elapsed() / time.Duration(p.done)
+	return perGame * time.Duration(p.total-p.done), true
+}
+
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	hours := int(d.Hours())
+	minutes := int(d.Minutes()) % 60
+	seconds := int(d.Seconds()) % 60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, seconds)
+	}
+	return fmt.Sprintf("%02d:%02d", minutes, seconds)
+}
+
+func (p *Progress) statusText() string {
+	switch {
+	case p.running && p.engine.IsPaused():
+		return "Paused"
+	case p.running:
+		return "Scraping..."
+	case p.cancelled:
+		return "Cancelled"
+	case p.total > 0:
+		return "Finished"
+	default:
+		return "Idle"
+	}
+}
+
+func (p *Progress) Update() {
+	if p.total > 0 {
+		p.progressBar.SetProgress(float32(p.done) / float32(p.total))
+	} else {
+		p.progressBar.SetProgress(0)
+	}
+
+	p.pauseButton.SetEnabled(p.running)
+	if p.engine.IsPaused() {
+		p.pauseButton.Label = "Resume"
+	} else {
+		p.pauseButton.Label = "Pause"
+	}
+
+	if p.running {
+		p.cancelButton.Label = "Cancel"
+	} else {
+		p.cancelButton.Label = "Close"
+	}
+}
+
+func (p *Progress) Render() {
+	mainStyle := theme.GetMainContainerStyle()
+	contentStyle := theme.GetContentContainerStyle()
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("main-container"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  clay.SizingGrow(float32(core.WINDOW_WIDTH)),
+				Height: clay.SizingGrow(float32(core.WINDOW_HEIGHT)),
+			},
+			Padding:         clay.Padding{Left: spacing.LG, Right: spacing.LG, Top: spacing.LG, Bottom: spacing.LG},
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+				Y: clay.ALIGN_Y_CENTER,
+			},
+		},
+		BackgroundColor: mainStyle.BackgroundColor,
+	}, func() {
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID("content-container"),
+			Layout: clay.LayoutConfig{
+				Sizing: clay.Sizing{
+					Width:  clay.SizingPercent(0.8),
+					Height: clay.SizingFit(0, 0),
+				},
+				Padding:         contentStyle.Padding,
+				ChildGap:        spacing.MD,
+				LayoutDirection: clay.TOP_TO_BOTTOM,
+				ChildAlignment: clay.ChildAlignment{
+					X: clay.ALIGN_X_CENTER,
+				},
+			},
+			CornerRadius:    clay.CornerRadiusAll(contentStyle.CornerRadius),
+			BackgroundColor: contentStyle.BackgroundColor,
+			Border:          contentStyle.Border,
+		}, func() {
+			widgets.TextXLarge(p.statusText(), ds.Colors.TextPrimary)
+
+			p.progressBar.Render()
+
+			widgets.TextBase(fmt.Sprintf("%d / %d games", p.done, p.total), ds.Colors.TextPrimary)
+			widgets.TextSmall(fmt.Sprintf("Scraped %d | Failed %d | Skipped %d", p.succeeded, p.failed, p.skipped), ds.Colors.TextSecondary)
+
+			timing := "Elapsed " + formatDuration(p.elapsed())
+			if eta, ok := p.eta(); ok {
+				timing += " | ETA " + formatDuration(eta)
+			}
+			widgets.TextSmall(timing, ds.Colors.TextSecondary)
+
+			if p.current != "" {
+				widgets.TextSmall("Current: "+p.current, ds.Colors.TextMuted)
+			}
+
+			p.renderQuotas()
+
+			p.renderSystems()
+
+			if p.confirming {
+				p.renderConfirm()
+			} else {
+				p.renderButtons()
+			}
+		})
+	})
+}
+
+// renderQuotas mostra as requisições restantes informadas pelos providers
+func (p *Progress) renderQuotas() {
+	if p.quotas == nil {
+		return
+	}
+	ds := theme.DefaultDesignSystem()
+
+	for _, quota := range p.quotas.All() {
+		if quota.Exceeded {
+			widgets.TextSmall(quota.Name+" quota exceeded, retry later", ds.Colors.Danger)
+			continue
+		}
+		if quota.Limit <= 0 {
+			continue
+		}
+		text := fmt.Sprintf("%s quota: %d / %d requests left", quota.Name, quota.Remaining, quota.Limit)
+		if !quota.Reset.IsZero() {
+			text += " | resets " + quota.Reset.Local().Format("15:04")
+		}
+
+		color := ds.Colors.TextSecondary
+		if quota.Fraction() < 0.1 {
+			color = ds.Colors.Danger
+		}
+		widgets.TextSmall(text, color)
+	}
+}
+
+// renderSystems mostra done/total por sistema
+func (p *Progress) renderSystems() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("progress-systems"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width: clay.SizingGrow(0),
+			},
+			Padding:         clay.PaddingAll(spacing.SM),
+			ChildGap:        spacing.XS,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+		BackgroundColor: ds.Colors.SurfaceSecondary,
+	}, func() {
+		for i, sp := range p.systems {
+			if i == maxVisibleSystems {
+				widgets.TextXSmall(fmt.Sprintf("+%d more systems", len(p.systems)-maxVisibleSystems), ds.Colors.TextMuted)
+				break
+			}
+
+			color := ds.Colors.TextSecondary
+			if sp.done == sp.total {
+				color = ds.Colors.Success
+			}
+			widgets.TextSmall(fmt.Sprintf("%s: %d / %d", sp.name, sp.done, sp.total), color)
+		}
+	})
+}
+
+func (p *Progress) renderButtons() {
+	spacing := theme.GetSpacing()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("buttons-container"),
+		Layout: clay.LayoutConfig{
+			Padding:         clay.Padding{Left: spacing.SM, Right: spacing.SM, Top: spacing.SM, Bottom: spacing.SM},
+			ChildGap:        spacing.MD,
+			LayoutDirection: clay.LEFT_TO_RIGHT,
+		},
+	}, func() {
+		p.pauseButton.Render()
+		p.cancelButton.Render()
+	})
+}
+
+// renderConfirm substitui os botões pela confirmação de cancelamento.
+// Apenas os botões renderizados entram na navegação espacial.
+func (p *Progress) renderConfirm() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("progress-confirm"),
+		Layout: clay.LayoutConfig{
+			Padding:         clay.PaddingAll(spacing.MD),
+			ChildGap:        spacing.MD,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+			},
+		},
+		BackgroundColor: ds.Colors.SurfaceTertiary,
+	}, func() {
+		widgets.TextBase("Cancel the running scrape?", ds.Colors.TextPrimary)
+
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID("progress-confirm-buttons"),
+			Layout: clay.LayoutConfig{
+				ChildGap:        spacing.MD,
+				LayoutDirection: clay.LEFT_TO_RIGHT,
+			},
+		}, func() {
+			for _, btn := range p.confirmButtons {
+				btn.Render()
+			}
+		})
+	})
+}
+
+func (p *Progress) HandleInput(inputType input.InputType) {
+	if inputType == input.InputBack {
+		switch {
+		case p.confirming:
+			p.confirming = false
+		case p.running:
+			// Não cancelar silenciosamente: pedir confirmação
+			p.confirming = true
+		case p.navigator != nil:
+			p.navigator.GoBack()
+		}
+		return
+	}
+
+	layout := ui.GetLayout()
+	if layout != nil {
+		layout.HandleSpatialInput(inputType)
+	}
+}
+
+func (p *Progress) OnEnter(navigator Navigator) {
+	p.navigator = navigator
+	log.Println("Entering Progress screen")
+}
+
+func (p *Progress) OnExit() {
+	p.confirming = false
+	log.Println("Exiting Progress screen")
+}
@@ -0,0 +1,389 @@
+package screen
+
+import (
+	"fmt"
+	"log"
+	"slices"
+
+	"github.com/TotallyGamerJet/clay"
+
+	"retroart-sdl2/internal/config"
+	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/scraper"
+	"retroart-sdl2/internal/theme"
+	"retroart-sdl2/internal/ui"
+	"retroart-sdl2/internal/ui/widgets"
+)
+
+// regionColumns é o número de colunas da grade de regiões disponíveis
+const regionColumns = 3
+
+// RegionOrder é a subpágina das configurações que ordena as regiões. A
+// confirma uma linha para pegá-la, o D-pad a move para cima ou para baixo e
+// A a solta; X remove a região da lista.
+type RegionOrder struct {
+	navigator Navigator
+	config    *config.Config
+	path      string
+
+	order   []string // Regiões em ordem de prioridade
+	grabbed int      // Linha sendo movida, -1 quando nenhuma
+	status  string
+	isError bool
+
+	rows      []*widgets.Button // Uma por posição na lista de prioridade
+	available []*widgets.Button // Uma por região conhecida
+	buttons   []*widgets.Button
+}
+
+func NewRegionOrder(cfg *config.Config, path string) *RegionOrder {
+	r := &RegionOrder{config: cfg, path: path, grabbed: -1}
+
+	r.initializeWidgets()
+	r.InitializeFocus()
+
+	return r
+}
+
+func (r *RegionOrder) initializeWidgets() {
+	for range scraper.Regions {
+		r.addRow()
+	}
+
+	for _, region := range scraper.Regions {
+		code := region.Code
+		r.available = append(r.available, widgets.NewButton("regions-add-"+code,
+			fmt.Sprintf("+ %s", code), clay.SizingGrow(0), clay.SizingFixed(36), theme.StyleSecondary, func() {
+				r.add(code)
+			}))
+	}
+
+	r.buttons = []*widgets.Button{
+		widgets.NewButton("regions-save-btn", "Save", clay.SizingFixed(220),
+			clay.SizingFixed(45), theme.StylePrimary, r.save),
+		widgets.NewButton("regions-back-btn", "Back", clay.SizingFixed(220),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				if r.navigator != nil {
+					r.navigator.GoBack()
+				}
+			}),
+	}
+}
+
+// addRow cria a linha de mais uma posição da lista de prioridade
+func (r *RegionOrder) addRow() *widgets.Button {
+	index := len(r.rows)
+	row := widgets.NewButton(fmt.Sprintf("regions-row-%d", index), "", clay.SizingGrow(0),
+		clay.SizingFixed(36), theme.StyleSecondary, func() {
+			r.toggleGrab(index)
+		})
+	r.rows = append(r.rows, row)
+	return row
+}
+
+func (r *RegionOrder) InitializeFocus() {
+	layout := ui.GetLayout()
+	if layout != nil {
+		for _, row := range r.rows {
+			layout.RegisterFocusable(row)
+		}
+		for _, btn := range r.available {
+			layout.RegisterFocusable(btn)
+		}
+		for _, btn := range r.buttons {
+			layout.RegisterFocusable(btn)
+		}
+	}
+}
+
+// load copia a ordem da configuração, criando linhas para regiões
+// desconhecidas vindas do arquivo
+func (r *RegionOrder) load() {
+	r.order = r.order[:0]
+	for _, code := range r.config.Regions {
+		if code = scraper.NormalizeCode(code); code != "" && !slices.Contains(r.order, code) {
+			r.order = append(r.order, code)
+		}
+	}
+	for len(r.rows) < len(r.order) {
+		row := r.addRow()
+		if layout := ui.GetLayout(); layout != nil {
+			layout.RegisterFocusable(row)
+		}
+	}
+	r.grabbed = -1
+}
+
+func (r *RegionOrder) toggleGrab(index int) {
+	if r.grabbed == index {
+		r.grabbed = -1
+	} else {
+		r.grabbed = index
+	}
+}
+
+// move troca a região pega com a vizinha na direção dada
+func (r *RegionOrder) move(delta int) bool {
+	target := r.grabbed + delta
+	if target < 0 || target >= len(r.order) {
+		return false
+	}
+	r.order[r.grabbed], r.order[target] = r.order[target], r.order[r.grabbed]
+	r.grabbed = target
+	return true
+}
+
+func (r *RegionOrder) add(code string) {
+	if !slices.Contains(r.order, code) {
+		r.order = append(r.order, code)
+	}
+}
+
+// remove tira da lista a região da linha focada
+func (r *RegionOrder) remove() {
+	layout := ui.GetLayout()
+	if layout == nil || layout.GetSpatialNavigation() == nil {
+		return
+	}
+	row, ok := layout.GetSpatialNavigation().GetCurrentWidget().(*widgets.Button)
+	if !ok {
+		return
+	}
+	index := slices.Index(r.rows, row)
+	if index < 0 || index >= len(r.order) || len(r.order) == 1 {
+		return
+	}
+	r.order = slices.Delete(r.order, index, index+1)
+	r.grabbed = -1
+}
+
+func (r *RegionOrder) save() {
+	r.config.Regions = slices.Clone(r.order)
+	if err := config.Save(r.path, r.config); err != nil {
+		log.Printf("RegionOrder: %v", err)
+		r.status = "Could not save settings"
+		r.isError = true
+		return
+	}
+	r.status = "Saved. Restart RetroArt to apply changes."
+	r.isError = false
+}
+
+func regionName(code string) string {
+	for _, region := range scraper.Regions {
+		if region.Code == code {
+			return region.Name
+		}
+	}
+	return "Unknown"
+}
+
+func (r *RegionOrder) Update() {
+	for i, code := range r.order {
+		row := r.rows[i]
+		row.Label = fmt.Sprintf("%d. %s - %s", i+1, code, regionName(code))
+		if i == r.grabbed {
+			row.Label = "<> " + row.Label
+			row.Config = theme.GetButtonStyle(theme.StylePrimary)
+		} else {
+			row.Config = theme.GetButtonStyle(theme.StyleSecondary)
+		}
+	}
+}
+
+func (r *RegionOrder) Render() {
+	mainStyle := theme.GetMainContainerStyle()
+	contentStyle := theme.GetContentContainerStyle()
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("main-container"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  clay.SizingGrow(float32(core.WINDOW_WIDTH)),
+				Height: clay.SizingGrow(float32(core.WINDOW_HEIGHT)),
+			},
+			Padding:         clay.Padding{Left: spacing.LG, Right: spacing.LG, Top: spacing.LG, Bottom: spacing.LG},
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+				Y: clay.ALIGN_Y_CENTER,
+			},
+		},
+		BackgroundColor: mainStyle.BackgroundColor,
+	}, func() {
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID("content-container"),
+			Layout: clay.LayoutConfig{
+				Sizing: clay.Sizing{
+					Width:  clay.SizingPercent(0.9),
+					Height: clay.SizingFit(0, 0),
+				},
+				Padding:         contentStyle.Padding,
+				ChildGap:        spacing.MD,
+				LayoutDirection: clay.TOP_TO_BOTTOM,
+				ChildAlignment: clay.ChildAlignment{
+					X: clay.ALIGN_X_CENTER,
+				},
+			},
+			CornerRadius:    clay.CornerRadiusAll(contentStyle.CornerRadius),
+			BackgroundColor: contentStyle.BackgroundColor,
+			Border:          contentStyle.Border,
+		}, func() {
+			widgets.TextXLarge("Region priority", ds.Colors.TextPrimary)
+			widgets.TextSmall("A: pick up / drop   D-pad: move   X: remove", ds.Colors.TextMuted)
+
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID("regions-columns"),
+				Layout: clay.LayoutConfig{
+					Sizing: clay.Sizing{
+						Width: clay.SizingGrow(0),
+					},
+					ChildGap:        spacing.LG,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				r.renderOrder()
+				r.renderAvailable()
+			})
+
+			if r.status != "" {
+				color := ds.Colors.Success
+				if r.isError {
+					color = ds.Colors.Danger
+				}
+				widgets.TextSmall(r.status, color)
+			}
+
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID("buttons-container"),
+				Layout: clay.LayoutConfig{
+					Padding:         clay.Padding{Left: spacing.SM, Right: spacing.SM, Top: spacing.SM, Bottom: spacing.SM},
+					ChildGap:        spacing.MD,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				for _, btn := range r.buttons {
+					btn.Render()
+				}
+			})
+		})
+	})
+}
+
+// renderOrder mostra a lista de prioridade
+func (r *RegionOrder) renderOrder() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("regions-order"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width: clay.SizingPercent(0.5),
+			},
+			ChildGap:        spacing.XS,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+	}, func() {
+		widgets.TextSmall("Priority", ds.Colors.TextSecondary)
+		for i := range r.order {
+			r.rows[i].Render()
+		}
+	})
+}
+
+// renderAvailable mostra em grade as regiões que ainda não estão na lista
+func (r *RegionOrder) renderAvailable() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	var available []*widgets.Button
+	for i, region := range scraper.Regions {
+		if !slices.Contains(r.order, region.Code) {
+			available = append(available, r.available[i])
+		}
+	}
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("regions-available"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width: clay.SizingGrow(0),
+			},
+			ChildGap:        spacing.XS,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+	}, func() {
+		widgets.TextSmall("Add a region", ds.Colors.TextSecondary)
+		for start := 0; start < len(available); start += regionColumns {
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID(fmt.Sprintf("regions-available-row-%d", start/regionColumns)),
+				Layout: clay.LayoutConfig{
+					Sizing: clay.Sizing{
+						Width: clay.SizingGrow(0),
+					},
+					ChildGap:        spacing.XS,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				for _, btn := range available[start:min(start+regionColumns, len(available))] {
+					btn.Render()
+				}
+			})
+		}
+	})
+}
+
+func (r *RegionOrder) HandleInput(inputType input.InputType) {
+	layout := ui.GetLayout()
+
+	switch inputType {
+	case input.InputBack:
+		if r.grabbed >= 0 {
+			r.grabbed = -1
+			return
+		}
+		if r.navigator != nil {
+			r.navigator.GoBack()
+		}
+		return
+	case input.InputUp, input.InputDown:
+		// Com uma linha pega, o D-pad move a região e o foco a acompanha
+		if r.grabbed >= 0 {
+			delta := 1
+			if inputType == input.InputUp {
+				delta = -1
+			}
+			if r.move(delta) && layout != nil {
+				layout.HandleSpatialInput(inputType)
+			}
+			return
+		}
+	case input.InputLeft, input.InputRight:
+		if r.grabbed >= 0 {
+			return
+		}
+	case input.InputX:
+		r.remove()
+		return
+	}
+
+	if layout == nil || !layout.HandleSpatialInput(inputType) {
+		log.Printf("RegionOrder: Input %d not handled", inputType)
+	}
+}
+
+func (r *RegionOrder) OnEnter(navigator Navigator) {
+	r.navigator = navigator
+	r.status = ""
+	r.load()
+	log.Println("Entering RegionOrder screen")
+}
+
+func (r *RegionOrder) OnExit() {
+	r.grabbed = -1
+	log.Println("Exiting RegionOrder screen")
+}
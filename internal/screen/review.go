@@ -0,0 +1,567 @@
+package screen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"log"
+
+	"github.com/TotallyGamerJet/clay"
+
+	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/imaging"
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/job"
+	"retroart-sdl2/internal/match"
+	"retroart-sdl2/internal/review"
+	"retroart-sdl2/internal/scraper"
+	"retroart-sdl2/internal/theme"
+	"retroart-sdl2/internal/ui"
+	"retroart-sdl2/internal/ui/widgets"
+)
+
+// reviewCandidates é o número de candidatos mostrados por jogo
+const reviewCandidates = 4
+
+// reviewThumbSize é o tamanho das miniaturas dos candidatos
+const reviewThumbSize = 160
+
+// maxThumbCache limita as miniaturas decodificadas mantidas em memória
+const maxThumbCache = 64
+
+// thumbMediaTypes define a ordem de preferência das miniaturas
+var thumbMediaTypes = []scraper.MediaType{
+	scraper.MediaBoxFront, scraper.MediaBox3D, scraper.MediaScreenshot, scraper.MediaTitle, scraper.MediaWheel,
+}
+
+type thumbResult struct {
+	url   string
+	image image.Image
+}
+
+type searchResult struct {
+	key     string
+	results []scraper.Result
+	err     error
+}
+
+// Review lista os jogos sem correspondência automática para escolha manual
+type Review struct {
+	navigator Navigator
+	store     *review.Store
+	engine    *job.Engine
+	details   *GameDetail
+
+	items      []review.Item
+	index      int
+	candidates []scraper.Result
+	status     string
+	searching  bool
+
+	pickButtons [reviewCandidates]*widgets.Button
+	thumbs      [reviewCandidates]*widgets.Image
+	thumbURLs   [reviewCandidates]string
+	searchInput *widgets.InputText
+	searchBtn   *widgets.Button
+	buttons     []*widgets.Button
+	backButton  *widgets.Button
+
+	ctx        context.Context
+	cancel     context.CancelFunc
+	thumbCh    chan thumbResult
+	searchCh   chan searchResult
+	thumbCache map[string]image.Image
+}
+
+func NewReview(store *review.Store, engine *job.Engine, details *GameDetail) *Review {
+	r := &Review{
+		store:      store,
+		engine:     engine,
+		details:    details,
+		thumbCh:    make(chan thumbResult, reviewCandidates*2),
+		searchCh:   make(chan searchResult, 1),
+		thumbCache: make(map[string]image.Image),
+	}
+
+	r.initializeWidgets()
+	r.InitializeFocus()
+
+	return r
+}
+
+func (r *Review) initializeWidgets() {
+	for i := range reviewCandidates {
+		index := i
+		r.thumbs[i] = widgets.NewImage(fmt.Sprintf("review-thumb-%d", i),
+			clay.SizingFixed(reviewThumbSize), clay.SizingFixed(reviewThumbSize))
+		r.pickButtons[i] = widgets.NewButton(fmt.Sprintf("review-pick-%d", i), "Use this",
+			clay.SizingFixed(200), clay.SizingFixed(40), theme.StylePrimary, func() {
+				r.pick(index)
+			})
+	}
+
+	r.searchInput = widgets.NewInputText(
+		"review-search-input",
+		"Search by name...",
+		80,
+		clay.SizingGrow(0),
+		clay.SizingFixed(40),
+		nil,
+		func(text string) {
+			r.search(text)
+		},
+	)
+	r.searchBtn = widgets.NewButton("review-search-btn", "Search", clay.SizingFixed(160),
+		clay.SizingFixed(40), theme.StyleSecondary, func() {
+			r.search(r.searchInput.Text)
+		})
+
+	r.buttons = []*widgets.Button{
+		widgets.NewButton("review-prev-btn", "Previous", clay.SizingFixed(200),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				r.show(r.index - 1)
+			}),
+		widgets.NewButton("review-next-btn", "Next", clay.SizingFixed(200),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				r.show(r.index + 1)
+			}),
+		widgets.NewButton("review-info-btn", "Game Info", clay.SizingFixed(200),
+			clay.SizingFixed(45), theme.StyleSecondary, r.openGameInfo),
+		widgets.NewButton("review-skip-btn", "Skip forever", clay.SizingFixed(200),
+			clay.SizingFixed(45), theme.StyleDanger, r.skip),
+	}
+
+	r.backButton = widgets.NewButton("review-back-btn", "Back", clay.SizingFixed(200),
+		clay.SizingFixed(45), theme.StyleSecondary, func() {
+			if r.navigator != nil {
+				r.navigator.GoBack()
+			}
+		})
+}
+
+func (r *Review) InitializeFocus() {
+	layout := ui.GetLayout()
+	if layout != nil {
+		for _, btn := range r.pickButtons {
+			layout.RegisterFocusable(btn)
+		}
+		layout.RegisterFocusable(r.searchInput)
+		layout.RegisterFocusable(r.searchBtn)
+		for _, btn := range r.buttons {
+			layout.RegisterFocusable(btn)
+		}
+		layout.RegisterFocusable(r.backButton)
+	}
+}
+
+// OnJobEvent registra jogos sem correspondência para revisão
+func (r *Review) OnJobEvent(event job.Event) {
+	if r.store == nil {
+		return
+	}
+
+	switch event.Type {
+	case job.EventSkipped:
+		if errors.Is(event.Err, job.ErrLowConfidence) {
+			r.store.Flag(review.Item{
+				Game:       event.Task.Game,
+				System:     event.Task.System,
+				Reason:     event.Reason,
+				Candidates: event.Candidates,
+			})
+		}
+	case job.EventFailed:
+		if errors.Is(event.Err, scraper.ErrNotFound) {
+			r.store.Flag(review.Item{
+				Game:   event.Task.Game,
+				System: event.Task.System,
+				Reason: "no match found",
+			})
+		}
+	case job.EventMatched:
+		r.store.Unflag(event.Task.Game)
+	case job.EventFinished:
+		r.save()
+	}
+}
+
+func (r *Review) save() {
+	if err := r.store.Save(); err != nil {
+		log.Printf("Review: %v", err)
+	}
+}
+
+func (r *Review) current() (review.Item, bool) {
+	if r.index < 0 || r.index >= len(r.items) {
+		return review.Item{}, false
+	}
+	return r.items[r.index], true
+}
+
+// show seleciona o item e carrega seus candidatos
+func (r *Review) show(index int) {
+	if len(r.items) == 0 {
+		r.index = 0
+		r.setCandidates(nil)
+		return
+	}
+	r.index = min(max(index, 0), len(r.items)-1)
+
+	item := r.items[r.index]
+	r.searching = false
+	r.searchInput.SetText(match.Clean(item.Game.Name))
+	r.setCandidates(item.Candidates)
+}
+
+// setCandidates troca os candidatos visíveis e busca suas miniaturas
+func (r *Review) setCandidates(candidates []scraper.Result) {
+	r.candidates = candidates
+	if len(r.candidates) > reviewCandidates {
+		r.candidates = r.candidates[:reviewCandidates]
+	}
+
+	for i := range reviewCandidates {
+		r.thumbs[i].SetImage(nil)
+		r.thumbURLs[i] = ""
+		if i >= len(r.candidates) {
+			continue
+		}
+
+		media, ok := thumbnailMedia(r.candidates[i])
+		if !ok {
+			continue
+		}
+		r.thumbURLs[i] = media.URL
+		if img, cached := r.thumbCache[media.URL]; cached {
+			r.thumbs[i].SetImage(img)
+			continue
+		}
+		go r.loadThumb(r.ctx, media)
+	}
+}
+
+func thumbnailMedia(result scraper.Result) (scraper.Media, bool) {
+	for _, mediaType := range thumbMediaTypes {
+		if media := result.MediaOfType(mediaType); len(media) > 0 {
+			return media[0], true
+		}
+	}
+	return scraper.Media{}, false
+}
+
+// loadThumb baixa e reduz uma miniatura em background
+func (r *Review) loadThumb(ctx context.Context, media scraper.Media) {
+	if ctx == nil || r.engine == nil {
+		return
+	}
+
+	content, err := r.engine.Fetch(ctx, media)
+	if err != nil {
+		log.Printf("Review: thumbnail %s: %v", media.URL, err)
+		return
+	}
+	defer content.Close()
+
+	img, _, err := imaging.Decode(content)
+	if err != nil {
+		log.Printf("Review: thumbnail %s: %v", media.URL, err)
+		return
+	}
+	img = imaging.Process(img, imaging.Options{
+		Width:  reviewThumbSize,
+		Height: reviewThumbSize,
+		Mode:   imaging.ModePad,
+	})
+
+	select {
+	case r.thumbCh <- thumbResult{url: media.URL, image: img}:
+	case <-ctx.Done():
+	}
+}
+
+// search busca candidatos pelo nome digitado em background
+func (r *Review) search(text string) {
+	item, ok := r.current()
+	if !ok || r.engine == nil || text == "" || r.searching {
+		return
+	}
+
+	r.searching = true
+	r.status = fmt.Sprintf("Searching \"%s\"...", text)
+	ctx, key := r.ctx, review.Key(item.Game)
+	go func() {
+		results, err := r.engine.Search(ctx, text, item.Game.SystemID)
+		select {
+		case r.searchCh <- searchResult{key: key, results: results, err: err}:
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// pick aceita um candidato e agenda o download da arte
+func (r *Review) pick(index int) {
+	item, ok := r.current()
+	if !ok || index >= len(r.candidates) {
+		return
+	}
+
+	result := r.candidates[index]
+	result.Confidence = 1
+	r.store.Decide(item.Game, review.Decision{Action: review.ActionMatch, Result: &result})
+	r.save()
+
+	r.status = fmt.Sprintf("%s matched as %s", item.Game.DisplayName(), result.Title)
+	if r.engine != nil {
+		err := r.engine.StartTasks([]job.Task{{Game: item.Game, System: item.System}})
+		if errors.Is(err, job.ErrRunning) {
+			r.status += " (artwork will be downloaded on the next run)"
+		}
+	}
+	r.removeCurrent()
+}
+
+// openGameInfo abre os detalhes do jogo em revisão
+func (r *Review) openGameInfo() {
+	item, ok := r.current()
+	if !ok || r.details == nil || r.navigator == nil {
+		return
+	}
+	r.details.Open(item.Game)
+	r.navigator.NavigateTo("game")
+}
+
+// skip marca o jogo para nunca mais ser processado
+func (r *Review) skip() {
+	item, ok := r.current()
+	if !ok {
+		return
+	}
+
+	r.store.Decide(item.Game, review.Decision{Action: review.ActionSkip})
+	r.save()
+	r.status = fmt.Sprintf("%s will be skipped", item.Game.DisplayName())
+	r.removeCurrent()
+}
+
+func (r *Review) removeCurrent() {
+	r.items = append(r.items[:r.index], r.items[r.index+1:]...)
+	r.show(r.index)
+}
+
+func (r *Review) Update() {
+	for {
+		select {
+		case thumb := <-r.thumbCh:
+			if len(r.thumbCache) >= maxThumbCache {
+				clear(r.thumbCache)
+			}
+			r.thumbCache[thumb.url] = thumb.image
+			for i, url := range r.thumbURLs {
+				if url == thumb.url {
+					r.thumbs[i].SetImage(thumb.image)
+				}
+			}
+		case found := <-r.searchCh:
+			r.searching = false
+			item, ok := r.current()
+			if !ok || review.Key(item.Game) != found.key {
+				continue
+			}
+			switch {
+			case errors.Is(found.err, scraper.ErrNotFound):
+				r.status = "No results"
+			case found.err != nil:
+				r.status = "Search failed: " + found.err.Error()
+			default:
+				r.status = fmt.Sprintf("%d results", len(found.results))
+				r.setCandidates(found.results)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (r *Review) Render() {
+	mainStyle := theme.GetMainContainerStyle()
+	contentStyle := theme.GetContentContainerStyle()
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("main-container"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  clay.SizingGrow(float32(core.WINDOW_WIDTH)),
+				Height: clay.SizingGrow(float32(core.WINDOW_HEIGHT)),
+			},
+			Padding:         clay.Padding{Left: spacing.LG, Right: spacing.LG, Top: spacing.LG, Bottom: spacing.LG},
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+				Y: clay.ALIGN_Y_CENTER,
+			},
+		},
+		BackgroundColor: mainStyle.BackgroundColor,
+	}, func() {
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID("content-container"),
+			Layout: clay.LayoutConfig{
+				Sizing: clay.Sizing{
+					Width:  clay.SizingPercent(0.95),
+					Height: clay.SizingFit(0, 0),
+				},
+				Padding:         contentStyle.Padding,
+				ChildGap:        spacing.MD,
+				LayoutDirection: clay.TOP_TO_BOTTOM,
+				ChildAlignment: clay.ChildAlignment{
+					X: clay.ALIGN_X_CENTER,
+				},
+			},
+			CornerRadius:    clay.CornerRadiusAll(contentStyle.CornerRadius),
+			BackgroundColor: contentStyle.BackgroundColor,
+			Border:          contentStyle.Border,
+		}, func() {
+			item, ok := r.current()
+			if !ok {
+				widgets.TextXLarge("Review matches", ds.Colors.TextPrimary)
+				widgets.TextBase("Nothing to review.", ds.Colors.TextSecondary)
+				if r.status != "" {
+					widgets.TextSmall(r.status, ds.Colors.TextMuted)
+				}
+				r.backButton.Render()
+				return
+			}
+
+			widgets.TextXLarge(fmt.Sprintf("Review matches (%d / %d)", r.index+1, len(r.items)), ds.Colors.TextPrimary)
+			widgets.TextLarge(item.Game.DisplayName(), ds.Colors.TextPrimary)
+			widgets.TextSmall(fmt.Sprintf("%s | %s | %s", item.System, item.Game.FileName, item.Reason), ds.Colors.TextSecondary)
+
+			r.renderCandidates()
+			r.renderSearch()
+
+			if r.status != "" {
+				widgets.TextSmall(r.status, ds.Colors.TextMuted)
+			}
+
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID("buttons-container"),
+				Layout: clay.LayoutConfig{
+					ChildGap:        spacing.MD,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				for _, btn := range r.buttons {
+					btn.Render()
+				}
+				r.backButton.Render()
+			})
+		})
+	})
+}
+
+// renderCandidates mostra os candidatos com miniatura e confiança
+func (r *Review) renderCandidates() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("review-candidates"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  clay.SizingGrow(0),
+				Height: clay.SizingFixed(reviewThumbSize + 110),
+			},
+			ChildGap:        spacing.MD,
+			LayoutDirection: clay.LEFT_TO_RIGHT,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+			},
+		},
+	}, func() {
+		if len(r.candidates) == 0 {
+			widgets.TextBase("No candidates. Try a custom search.", ds.Colors.TextMuted)
+			return
+		}
+
+		for i, candidate := range r.candidates {
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID(fmt.Sprintf("review-card-%d", i)),
+				Layout: clay.LayoutConfig{
+					Sizing: clay.Sizing{
+						Width: clay.SizingFixed(220),
+					},
+					Padding:         clay.PaddingAll(spacing.SM),
+					ChildGap:        spacing.XS,
+					LayoutDirection: clay.TOP_TO_BOTTOM,
+					ChildAlignment: clay.ChildAlignment{
+						X: clay.ALIGN_X_CENTER,
+					},
+				},
+				BackgroundColor: ds.Colors.SurfaceSecondary,
+				CornerRadius:    clay.CornerRadiusAll(8),
+			}, func() {
+				r.thumbs[i].Render()
+				widgets.TextSmall(candidate.Title, ds.Colors.TextPrimary)
+				widgets.TextXSmall(fmt.Sprintf("%s | %.0f%%", candidate.Provider, candidate.Confidence*100), ds.Colors.TextMuted)
+				r.pickButtons[i].Render()
+			})
+		}
+	})
+}
+
+func (r *Review) renderSearch() {
+	spacing := theme.GetSpacing()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("review-search"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width: clay.SizingPercent(0.7),
+			},
+			ChildGap:        spacing.SM,
+			LayoutDirection: clay.LEFT_TO_RIGHT,
+		},
+	}, func() {
+		r.searchInput.Render()
+		r.searchBtn.Render()
+	})
+}
+
+func (r *Review) HandleInput(inputType input.InputType) {
+	layout := ui.GetLayout()
+
+	if inputType == input.InputBack {
+		// Com o teclado aberto, Back é tratado pelo próprio campo
+		if r.searchInput.IsKeyboardVisible() {
+			layout.HandleSpatialInput(inputType)
+			return
+		}
+		if r.navigator != nil {
+			r.navigator.GoBack()
+		}
+		return
+	}
+
+	if layout == nil || !layout.HandleSpatialInput(inputType) {
+		log.Printf("Review: Input %d not handled", inputType)
+	}
+}
+
+func (r *Review) OnEnter(navigator Navigator) {
+	r.navigator = navigator
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.status = ""
+	r.items = r.store.Pending()
+	r.show(r.index)
+	log.Println("Entering Review screen")
+}
+
+func (r *Review) OnExit() {
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.searching = false
+	log.Println("Exiting Review screen")
+}
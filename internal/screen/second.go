@@ -36,7 +36,9 @@ func (ss *Second) initializeWidgets() {
 			}),
 		widgets.NewButton("options-btn", "Options", clay.SizingFixed(220),
 			clay.SizingFixed(45), theme.StyleSecondary, func() {
-				// Ação para opções (pode ser implementada futuramente)
+				if ss.navigator != nil {
+					ss.navigator.NavigateTo("settings")
+				}
 			}),
 		widgets.NewButton("exit-btn", "Exit", clay.SizingFixed(220),
 			clay.SizingFixed(45), theme.StyleDanger, func() {
@@ -67,8 +69,8 @@ func (ss *Second) Render() {
 		Id: clay.ID("main-container"),
 		Layout: clay.LayoutConfig{
 			Sizing: clay.Sizing{
-				Width:  clay.SizingGrow(core.WINDOW_WIDTH),
-				Height: clay.SizingGrow(core.WINDOW_HEIGHT),
+				Width:  clay.SizingGrow(float32(core.WINDOW_WIDTH)),
+				Height: clay.SizingGrow(float32(core.WINDOW_HEIGHT)),
 			},
 			Padding:         clay.Padding{Left: spacing.LG, Right: spacing.LG, Top: spacing.LG, Bottom: spacing.LG},
 			ChildGap:        spacing.XL,
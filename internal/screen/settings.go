@@ -0,0 +1,465 @@
+package screen
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/TotallyGamerJet/clay"
+
+	"retroart-sdl2/internal/config"
+	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/output"
+	"retroart-sdl2/internal/scraper"
+	"retroart-sdl2/internal/theme"
+	"retroart-sdl2/internal/ui"
+	"retroart-sdl2/internal/ui/widgets"
+)
+
+// settingsField liga um campo de texto a um valor da configuração
+type settingsField struct {
+	label  string
+	masked bool
+	input  *widgets.InputText
+	load   func(cfg *config.Config) string
+	store  func(cfg *config.Config, value string)
+}
+
+// Settings edita e salva o arquivo de configuração
+type Settings struct {
+	navigator      Navigator
+	config         *config.Config
+	path           string
+	fields         []*settingsField
+	mediaList      *widgets.CheckboxList[scraper.MediaType]
+	providerButton *widgets.Button
+	overwriteBtn   *widgets.Button
+	layoutButton   *widgets.Button
+	gamelistBtn    *widgets.Button
+	regionsButton  *widgets.Button
+	buttons        []*widgets.Button
+	provider       string
+	outputLayout   string
+	overwrite      config.OverwritePolicy
+	gamelist       bool
+	status         string
+	statusIsError  bool
+}
+
+// NewSettings creates the settings screen editing cfg, saved to path
+func NewSettings(cfg *config.Config, path string) *Settings {
+	settings := &Settings{config: cfg, path: path}
+
+	settings.initializeWidgets()
+	settings.InitializeFocus()
+	settings.loadValues()
+
+	return settings
+}
+
+func (s *Settings) initializeWidgets() {
+	s.fields = []*settingsField{
+		{
+			label: "ROMs folder",
+			load:  func(cfg *config.Config) string { return cfg.RomsRoot },
+			store: func(cfg *config.Config, value string) { cfg.RomsRoot = value },
+		},
+		{
+			label: "Artwork folder",
+			load:  func(cfg *config.Config) string { return cfg.ArtDir },
+			store: func(cfg *config.Config, value string) { cfg.ArtDir = value },
+		},
+		{
+			label: "ScreenScraper user",
+			load:  func(cfg *config.Config) string { return cfg.ScreenScraper.Username },
+			store: func(cfg *config.Config, value string) { cfg.ScreenScraper.Username = value },
+		},
+		{
+			label:  "ScreenScraper password",
+			masked: true,
+			load:   func(cfg *config.Config) string { return cfg.ScreenScraper.Password },
+			store:  func(cfg *config.Config, value string) { cfg.ScreenScraper.Password = value },
+		},
+		{
+			label: "Developer ID",
+			load:  func(cfg *config.Config) string { return cfg.ScreenScraper.DevID },
+			store: func(cfg *config.Config, value string) { cfg.ScreenScraper.DevID = value },
+		},
+		{
+			label:  "Developer password",
+			masked: true,
+			load:   func(cfg *config.Config) string { return cfg.ScreenScraper.DevPassword },
+			store:  func(cfg *config.Config, value string) { cfg.ScreenScraper.DevPassword = value },
+		},
+		{
+			label: "Mix template",
+			load:  func(cfg *config.Config) string { return cfg.MixTemplate },
+			store: func(cfg *config.Config, value string) { cfg.MixTemplate = value },
+		},
+		{
+			label: "Window size",
+			load: func(cfg *config.Config) string {
+				return fmt.Sprintf("%dx%d", cfg.Window.Width, cfg.Window.Height)
+			},
+			store: func(cfg *config.Config, value string) {
+				var width, height int
+				if _, err := fmt.Sscanf(value, "%dx%d", &width, &height); err == nil && width > 0 && height > 0 {
+					cfg.Window = config.WindowConfig{Width: width, Height: height}
+				}
+			},
+		},
+		{
+			label: "Font file",
+			load:  func(cfg *config.Config) string { return cfg.Theme.FontPath },
+			store: func(cfg *config.Config, value string) { cfg.Theme.FontPath = value },
+		},
+	}
+
+	for i, field := range s.fields {
+		field.input = widgets.NewInputText(
+			fmt.Sprintf("settings-field-%d", i),
+			field.label,
+			120,
+			clay.SizingGrow(0),
+			clay.SizingFixed(36),
+			nil,
+			nil,
+		)
+		field.input.SetMasked(field.masked)
+	}
+
+	mediaTypes := append([]scraper.MediaType{scraper.MediaMixed}, scraper.AllMediaTypes...)
+	mediaItems := make([]widgets.CheckboxListItem[scraper.MediaType], 0, len(mediaTypes))
+	for _, mediaType := range mediaTypes {
+		mediaItems = append(mediaItems, widgets.CheckboxListItem[scraper.MediaType]{
+			Label: string(mediaType),
+			Value: mediaType,
+		})
+	}
+	s.mediaList = widgets.NewCheckboxList("settings-media-list", clay.SizingGrow(0), clay.SizingFixed(220), mediaItems)
+
+	s.providerButton = widgets.NewButton("settings-provider-btn", "", clay.SizingGrow(0),
+		clay.SizingFixed(40), theme.StyleSecondary, s.cycleProvider)
+
+	s.overwriteBtn = widgets.NewButton("settings-overwrite-btn", "", clay.SizingGrow(0),
+		clay.SizingFixed(40), theme.StyleSecondary, s.cycleOverwrite)
+
+	s.layoutButton = widgets.NewButton("settings-layout-btn", "", clay.SizingGrow(0),
+		clay.SizingFixed(40), theme.StyleSecondary, s.cycleLayout)
+
+	s.regionsButton = widgets.NewButton("settings-regions-btn", "", clay.SizingGrow(0),
+		clay.SizingFixed(40), theme.StyleSecondary, func() {
+			// Guarda as edições em memória, recarregadas ao voltar da subpágina
+			s.storeValues()
+			if s.navigator != nil {
+				s.navigator.NavigateTo("regions")
+			}
+		})
+
+	s.gamelistBtn = widgets.NewButton("settings-gamelist-btn", "", clay.SizingGrow(0),
+		clay.SizingFixed(40), theme.StyleSecondary, func() { s.gamelist = !s.gamelist })
+
+	s.buttons = []*widgets.Button{
+		widgets.NewButton("settings-save-btn", "Save", clay.SizingFixed(220),
+			clay.SizingFixed(45), theme.StylePrimary, s.save),
+		widgets.NewButton("settings-back-btn", "Back", clay.SizingFixed(220),
+			clay.SizingFixed(45), theme.StyleSecondary, func() {
+				if s.navigator != nil {
+					s.navigator.GoBack()
+				}
+			}),
+	}
+}
+
+func (s *Settings) InitializeFocus() {
+	layout := ui.GetLayout()
+	if layout != nil {
+		for _, field := range s.fields {
+			layout.RegisterFocusable(field.input)
+		}
+		layout.RegisterFocusable(s.mediaList)
+		layout.RegisterFocusable(s.providerButton)
+		layout.RegisterFocusable(s.overwriteBtn)
+		layout.RegisterFocusable(s.layoutButton)
+		layout.RegisterFocusable(s.gamelistBtn)
+		layout.RegisterFocusable(s.regionsButton)
+		for _, btn := range s.buttons {
+			layout.RegisterFocusable(btn)
+		}
+	}
+}
+
+// loadValues copia a configuração para os widgets
+func (s *Settings) loadValues() {
+	for _, field := range s.fields {
+		field.input.SetText(field.load(s.config))
+	}
+
+	selected := make(map[string]bool, len(s.config.MediaTypes))
+	for _, mediaType := range s.config.MediaTypes {
+		selected[mediaType] = true
+	}
+	for i := range s.mediaList.Items {
+		s.mediaList.Items[i].Selected = selected[string(s.mediaList.Items[i].Value)]
+	}
+
+	s.provider = s.config.Provider
+	s.overwrite = s.config.Overwrite
+	s.outputLayout = s.config.OutputLayout
+	s.gamelist = s.config.ExportGamelist
+}
+
+// storeValues copia os valores dos widgets para a configuração
+func (s *Settings) storeValues() {
+	for _, field := range s.fields {
+		field.store(s.config, strings.TrimSpace(field.input.Text))
+	}
+
+	s.config.MediaTypes = s.config.MediaTypes[:0]
+	for _, mediaType := range s.mediaList.GetSelectedValues() {
+		s.config.MediaTypes = append(s.config.MediaTypes, string(mediaType))
+	}
+
+	s.config.Provider = s.provider
+	s.config.Overwrite = s.overwrite
+	s.config.OutputLayout = s.outputLayout
+	s.config.ExportGamelist = s.gamelist
+}
+
+func (s *Settings) save() {
+	s.storeValues()
+	if err := config.Save(s.path, s.config); err != nil {
+		log.Printf("Settings: %v", err)
+		s.status = "Could not save settings"
+		s.statusIsError = true
+		return
+	}
+	s.status = "Saved. Restart RetroArt to apply changes."
+	s.statusIsError = false
+}
+
+func (s *Settings) cycleProvider() {
+	if s.provider == config.ProviderScreenScraper {
+		s.provider = config.ProviderLibretro
+	} else {
+		s.provider = config.ProviderScreenScraper
+	}
+}
+
+func (s *Settings) cycleOverwrite() {
+	policies := config.OverwritePolicies
+	for i, policy := range policies {
+		if policy == s.overwrite {
+			s.overwrite = policies[(i+1)%len(policies)]
+			return
+		}
+	}
+	s.overwrite = policies[0]
+}
+
+func (s *Settings) cycleLayout() {
+	names := output.LayoutNames
+	for i, name := range names {
+		if name == s.outputLayout {
+			s.outputLayout = names[(i+1)%len(names)]
+			return
+		}
+	}
+	s.outputLayout = names[0]
+}
+
+func (s *Settings) Update() {
+	s.providerButton.Label = "Provider: " + s.provider
+	s.overwriteBtn.Label = "Overwrite: " + string(s.overwrite)
+	s.layoutButton.Label = "Frontend: " + s.outputLayout
+	s.regionsButton.Label = "Regions: " + strings.Join(s.config.Regions, " > ")
+	if s.gamelist {
+		s.gamelistBtn.Label = "gamelist.xml: On"
+	} else {
+		s.gamelistBtn.Label = "gamelist.xml: Off"
+	}
+}
+
+func (s *Settings) Render() {
+	mainStyle := theme.GetMainContainerStyle()
+	contentStyle := theme.GetContentContainerStyle()
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("main-container"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  clay.SizingGrow(float32(core.WINDOW_WIDTH)),
+				Height: clay.SizingGrow(float32(core.WINDOW_HEIGHT)),
+			},
+			Padding:         clay.Padding{Left: spacing.LG, Right: spacing.LG, Top: spacing.LG, Bottom: spacing.LG},
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+				Y: clay.ALIGN_Y_CENTER,
+			},
+		},
+		BackgroundColor: mainStyle.BackgroundColor,
+	}, func() {
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID("content-container"),
+			Layout: clay.LayoutConfig{
+				Sizing: clay.Sizing{
+					Width:  clay.SizingPercent(0.9),
+					Height: clay.SizingFit(0, 0),
+				},
+				Padding:         contentStyle.Padding,
+				ChildGap:        spacing.MD,
+				LayoutDirection: clay.TOP_TO_BOTTOM,
+				ChildAlignment: clay.ChildAlignment{
+					X: clay.ALIGN_X_CENTER,
+				},
+			},
+			CornerRadius:    clay.CornerRadiusAll(contentStyle.CornerRadius),
+			BackgroundColor: contentStyle.BackgroundColor,
+			Border:          contentStyle.Border,
+		}, func() {
+			widgets.TextXLarge("Settings", ds.Colors.TextPrimary)
+
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID("settings-columns"),
+				Layout: clay.LayoutConfig{
+					Sizing: clay.Sizing{
+						Width: clay.SizingGrow(0),
+					},
+					ChildGap:        spacing.LG,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				s.renderFields()
+				s.renderOptions()
+			})
+
+			if s.status != "" {
+				color := ds.Colors.Success
+				if s.statusIsError {
+					color = ds.Colors.Danger
+				}
+				widgets.TextSmall(s.status, color)
+			}
+
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID("buttons-container"),
+				Layout: clay.LayoutConfig{
+					Padding:         clay.Padding{Left: spacing.SM, Right: spacing.SM, Top: spacing.SM, Bottom: spacing.SM},
+					ChildGap:        spacing.MD,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+				},
+			}, func() {
+				for _, btn := range s.buttons {
+					btn.Render()
+				}
+			})
+		})
+	})
+}
+
+// renderFields mostra os campos de texto com seus rótulos
+func (s *Settings) renderFields() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("settings-fields"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width: clay.SizingPercent(0.6),
+			},
+			ChildGap:        spacing.XS,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+	}, func() {
+		for i, field := range s.fields {
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID(fmt.Sprintf("settings-field-row-%d", i)),
+				Layout: clay.LayoutConfig{
+					Sizing: clay.Sizing{
+						Width: clay.SizingGrow(0),
+					},
+					ChildGap:        spacing.SM,
+					LayoutDirection: clay.LEFT_TO_RIGHT,
+					ChildAlignment: clay.ChildAlignment{
+						Y: clay.ALIGN_Y_CENTER,
+					},
+				},
+			}, func() {
+				clay.UI()(clay.ElementDeclaration{
+					Id: clay.ID(fmt.Sprintf("settings-field-label-%d", i)),
+					Layout: clay.LayoutConfig{
+						Sizing: clay.Sizing{
+							Width: clay.SizingFixed(220),
+						},
+					},
+				}, func() {
+					widgets.TextSmall(field.label, ds.Colors.TextSecondary)
+				})
+				field.input.Render()
+			})
+		}
+	})
+}
+
+// renderOptions mostra os tipos de mídia e as opções cíclicas
+func (s *Settings) renderOptions() {
+	spacing := theme.GetSpacing()
+	ds := theme.DefaultDesignSystem()
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("settings-options"),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width: clay.SizingGrow(0),
+			},
+			ChildGap:        spacing.SM,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+	}, func() {
+		widgets.TextSmall("Media types", ds.Colors.TextSecondary)
+		s.mediaList.Render()
+		s.providerButton.Render()
+		s.overwriteBtn.Render()
+		s.layoutButton.Render()
+		s.gamelistBtn.Render()
+		s.regionsButton.Render()
+	})
+}
+
+func (s *Settings) HandleInput(inputType input.InputType) {
+	layout := ui.GetLayout()
+
+	if inputType == input.InputBack {
+		// Com o teclado aberto, Back é tratado pelo próprio campo
+		if layout != nil && layout.GetSpatialNavigation() != nil {
+			if field, ok := layout.GetSpatialNavigation().GetCurrentWidget().(*widgets.InputText); ok && field.IsKeyboardVisible() {
+				layout.HandleSpatialInput(inputType)
+				return
+			}
+		}
+		if s.navigator != nil {
+			s.navigator.GoBack()
+		}
+		return
+	}
+
+	if layout == nil || !layout.HandleSpatialInput(inputType) {
+		log.Printf("Settings: Input %d not handled", inputType)
+	}
+}
+
+func (s *Settings) OnEnter(navigator Navigator) {
+	s.navigator = navigator
+	s.status = ""
+	s.loadValues()
+	log.Println("Entering Settings screen")
+}
+
+func (s *Settings) OnExit() {
+	log.Println("Exiting Settings screen")
+}
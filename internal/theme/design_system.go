@@ -254,16 +254,35 @@ func GetFontIdForSize(fontSize uint16) uint16 {
 
 // FontSystem gerencia o carregamento de fontes baseado na tipografia
 type FontSystem struct {
-	fonts []sdl2.Font
+	fonts     []sdl2.Font
+	fontPaths []string
+}
+
+// defaultFontPaths lista os caminhos de fontes tentados em ordem
+var defaultFontPaths = []string{
+	"assets/DejaVuSansCondensed.ttf",
+	"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
+	"/usr/share/fonts/TTF/DejaVuSans.ttf",
+	"/System/Library/Fonts/Helvetica.ttc",
+	"/usr/share/fonts/liberation/LiberationSans-Regular.ttf",
 }
 
 // NewFontSystem cria um novo sistema de fontes
 func NewFontSystem() *FontSystem {
 	return &FontSystem{
-		fonts: make([]sdl2.Font, 0),
+		fonts:     make([]sdl2.Font, 0),
+		fontPaths: defaultFontPaths,
 	}
 }
 
+// SetPreferredFont faz com que a fonte informada seja tentada antes das padrão
+func (fs *FontSystem) SetPreferredFont(path string) {
+	if path == "" {
+		return
+	}
+	fs.fontPaths = append([]string{path}, defaultFontPaths...)
+}
+
 // GetAllTypographySizes retorna todos os tamanhos de fonte definidos na tipografia
 func GetAllTypographySizes() []uint16 {
 	ds := DefaultDesignSystem()
@@ -304,16 +323,7 @@ func (fs *FontSystem) InitializeFonts() error {
 
 // loadFontWithSize carrega uma fonte com tamanho específico
 func (fs *FontSystem) loadFontWithSize(size int) (*ttf.Font, error) {
-	// Lista de possíveis caminhos de fontes no sistema
-	fontPaths := []string{
-		"assets/DejaVuSansCondensed.ttf",
-		"/usr/share/fonts/truetype/dejavu/DejaVuSans.ttf",
-		"/usr/share/fonts/TTF/DejaVuSans.ttf",
-		"/System/Library/Fonts/Helvetica.ttc",
-		"/usr/share/fonts/liberation/LiberationSans-Regular.ttf",
-	}
-
-	for _, fontPath := range fontPaths {
+	for _, fontPath := range fs.fontPaths {
 		font, err := ttf.OpenFont(fontPath, size)
 		if err == nil {
 			log.Printf("Successfully loaded font from: %s (size %d)", fontPath, size)
@@ -0,0 +1,25 @@
+package theme
+
+import "github.com/TotallyGamerJet/clay"
+
+// ProgressBarStyle contém configurações para barras de progresso
+type ProgressBarStyle struct {
+	Height          float32
+	CornerRadius    float32
+	FontSize        uint16
+	BackgroundColor clay.Color
+	FillColor       clay.Color
+	TextColor       clay.Color
+}
+
+// GetProgressBarStyle retorna a configuração de estilo para barras de progresso
+func (ds DesignSystem) GetProgressBarStyle() ProgressBarStyle {
+	return ProgressBarStyle{
+		Height:          28,
+		CornerRadius:    ds.Border.Radius.Medium,
+		FontSize:        ds.Typography.Small,
+		BackgroundColor: ds.Colors.SurfaceSecondary,
+		FillColor:       ds.Colors.Primary,
+		TextColor:       ds.Colors.TextPrimary,
+	}
+}
@@ -7,6 +7,8 @@ type Theme interface {
 	GetCheckboxListStyle() CheckboxListStyle
 	GetInputTextStyle() InputTextStyle
 	GetVirtualKeyboardStyle() VirtualKeyboardStyle
+	GetProgressBarStyle() ProgressBarStyle
+	GetToastStyle() ToastStyle
 	GetMainContainerStyle() ContainerStyle
 	GetContentContainerStyle() ContainerStyle
 }
@@ -48,6 +50,16 @@ func (t *DefaultTheme) GetVirtualKeyboardStyle() VirtualKeyboardStyle {
 	return t.designSystem.GetVirtualKeyboardStyle()
 }
 
+// GetProgressBarStyle retorna o estilo para barras de progresso
+func (t *DefaultTheme) GetProgressBarStyle() ProgressBarStyle {
+	return t.designSystem.GetProgressBarStyle()
+}
+
+// GetToastStyle retorna o estilo para notificações temporárias
+func (t *DefaultTheme) GetToastStyle() ToastStyle {
+	return t.designSystem.GetToastStyle()
+}
+
 // GetMainContainerStyle retorna o estilo para container principal
 func (t *DefaultTheme) GetMainContainerStyle() ContainerStyle {
 	return t.designSystem.GetMainContainerStyle()
@@ -96,6 +108,16 @@ func GetVirtualKeyboardStyle() VirtualKeyboardStyle {
 	return GetCurrentTheme().GetVirtualKeyboardStyle()
 }
 
+// GetProgressBarStyle é uma função de conveniência para obter estilos de barra de progresso
+func GetProgressBarStyle() ProgressBarStyle {
+	return GetCurrentTheme().GetProgressBarStyle()
+}
+
+// GetToastStyle é uma função de conveniência para obter estilos de notificação
+func GetToastStyle() ToastStyle {
+	return GetCurrentTheme().GetToastStyle()
+}
+
 // GetMainContainerStyle é uma função de conveniência para obter estilos de container principal
 func GetMainContainerStyle() ContainerStyle {
 	return GetCurrentTheme().GetMainContainerStyle()
@@ -0,0 +1,40 @@
+package theme
+
+import "github.com/TotallyGamerJet/clay"
+
+// ToastStyle contém configurações para as notificações temporárias
+type ToastStyle struct {
+	Padding         clay.Padding
+	ChildGap        uint16
+	CornerRadius    float32
+	FontSize        uint16
+	BackgroundColor clay.Color
+	TextColor       clay.Color
+	Border          clay.BorderElementConfig
+}
+
+// GetToastStyle retorna a configuração de estilo para notificações temporárias
+func (ds DesignSystem) GetToastStyle() ToastStyle {
+	return ToastStyle{
+		Padding: clay.Padding{
+			Left:   ds.Spacing.LG,
+			Right:  ds.Spacing.LG,
+			Top:    ds.Spacing.SM,
+			Bottom: ds.Spacing.SM,
+		},
+		ChildGap:        ds.Spacing.SM,
+		CornerRadius:    ds.Border.Radius.Medium,
+		FontSize:        ds.Typography.Small,
+		BackgroundColor: ds.Colors.SurfaceTertiary,
+		TextColor:       ds.Colors.TextPrimary,
+		Border: clay.BorderElementConfig{
+			Color: ds.Colors.Border,
+			Width: clay.BorderWidth{
+				Left:   ds.Border.Width.XSmall,
+				Right:  ds.Border.Width.XSmall,
+				Top:    ds.Border.Width.XSmall,
+				Bottom: ds.Border.Width.XSmall,
+			},
+		},
+	}
+}
@@ -19,3 +19,11 @@ type Focusable interface {
 	// HandleInput processa input quando focado, retorna true se consumiu o input
 	HandleInput(inputType input.InputType) bool
 }
+
+// EventHandler é implementado por widgets que usam as fases e os tempos dos
+// InputEvents, como a aceleração enquanto uma direção fica pressionada e a
+// pressão longa
+type EventHandler interface {
+	// HandleInputEvent processa o evento quando focado, retorna true se consumiu
+	HandleInputEvent(event input.InputEvent) bool
+}
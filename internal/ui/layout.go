@@ -217,6 +217,16 @@ func (l *Layout) HandleSpatialInput(inputType input.InputType) bool {
 	return false
 }
 
+// HandleSpatialEvent oferece um InputEvent ao widget focado quando ele
+// implementa EventHandler. Devolve false quando o evento não foi consumido e
+// deve seguir por HandleSpatialInput.
+func (l *Layout) HandleSpatialEvent(event input.InputEvent) bool {
+	if l.spatialNav != nil {
+		return l.spatialNav.HandleEvent(event)
+	}
+	return false
+}
+
 // GetElementBoundingBox retorna o bounding box de um elemento específico
 func (l *Layout) GetElementBoundingBox(elementID string) *clay.BoundingBox {
 	if l.spatialNav != nil {
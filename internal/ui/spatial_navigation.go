@@ -171,6 +171,25 @@ func (sn *SpatialNavigation) HandleInput(inputType input.InputType) bool {
 	return false
 }
 
+// HandleEvent entrega o evento ao widget focado se ele implementa
+// EventHandler. Não há navegação espacial aqui: eventos não consumidos voltam
+// para a tela, que usa HandleInput.
+func (sn *SpatialNavigation) HandleEvent(event input.InputEvent) bool {
+	if !sn.enabled {
+		return false
+	}
+
+	currentElement := sn.getCurrentElement()
+	if currentElement == nil {
+		return false
+	}
+	handler, ok := currentElement.Widget.(EventHandler)
+	if !ok {
+		return false
+	}
+	return handler.HandleInputEvent(event)
+}
+
 // navigateInDirection encontra o próximo elemento na direção especificada
 func (sn *SpatialNavigation) navigateInDirection(current *ElementPosition, dirX, dirY float32) bool {
 	bestElement := sn.findBestElementInDirection(current, dirX, dirY)
@@ -331,6 +350,11 @@ func (sn *SpatialNavigation) GetCurrentWidget() Focusable {
 	return sn.focusables[sn.currentFocus]
 }
 
+// GetWidget retorna o widget focável registrado com o ID, ou nil
+func (sn *SpatialNavigation) GetWidget(id string) Focusable {
+	return sn.focusables[id]
+}
+
 // GetElementBoundingBox retorna o bounding box de um elemento específico
 func (sn *SpatialNavigation) GetElementBoundingBox(elementID string) *clay.BoundingBox {
 	for i := range sn.elements {
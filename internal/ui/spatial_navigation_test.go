@@ -0,0 +1,142 @@
+package ui_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/TotallyGamerJet/clay"
+
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/screen"
+	"retroart-sdl2/internal/theme"
+	"retroart-sdl2/internal/ui"
+	"retroart-sdl2/internal/ui/widgets"
+	"retroart-sdl2/internal/uitest"
+)
+
+// gridScreen mostra dois botões em cada linha e uma lista embaixo:
+//
+//	a b
+//	c d
+//	list
+type gridScreen struct {
+	rows    [][]*widgets.Button
+	list    *widgets.CheckboxList[int]
+	clicked string
+}
+
+func newGridScreen() *gridScreen {
+	g := &gridScreen{}
+	for _, row := range [][]string{{"a", "b"}, {"c", "d"}} {
+		var buttons []*widgets.Button
+		for _, id := range row {
+			buttons = append(buttons, widgets.NewButton(id, id, clay.SizingFixed(200), clay.SizingFixed(45),
+				theme.StylePrimary, func() { g.clicked = id }))
+		}
+		g.rows = append(g.rows, buttons)
+	}
+
+	var items []widgets.CheckboxListItem[int]
+	for i := range 3 {
+		items = append(items, widgets.CheckboxListItem[int]{Label: fmt.Sprintf("Item %d", i+1), Value: i})
+	}
+	g.list = widgets.NewCheckboxList("list", clay.SizingFixed(420), clay.SizingFixed(300), items)
+
+	layout := ui.GetLayout()
+	for _, row := range g.rows {
+		for _, button := range row {
+			layout.RegisterFocusable(button)
+		}
+	}
+	layout.RegisterFocusable(g.list)
+	return g
+}
+
+func (g *gridScreen) Update() {}
+
+func (g *gridScreen) Render() {
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID("grid"),
+		Layout: clay.LayoutConfig{
+			ChildGap:        16,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+		},
+	}, func() {
+		for i, row := range g.rows {
+			clay.UI()(clay.ElementDeclaration{
+				Id:     clay.ID(fmt.Sprintf("grid-row-%d", i)),
+				Layout: clay.LayoutConfig{ChildGap: 16, LayoutDirection: clay.LEFT_TO_RIGHT},
+			}, func() {
+				for _, button := range row {
+					button.Render()
+				}
+			})
+		}
+		g.list.Render()
+	})
+}
+
+func (g *gridScreen) HandleInput(inputType input.InputType) {
+	ui.GetLayout().HandleSpatialInput(inputType)
+}
+
+func (g *gridScreen) OnEnter(screen.Navigator) {}
+func (g *gridScreen) OnExit()                  {}
+
+func TestSpatialNavigationMovesByDirection(t *testing.T) {
+	d := uitest.New(t)
+	d.Show("grid", newGridScreen())
+	d.AssertFocus("")
+
+	steps := []struct {
+		input input.InputType
+		want  string
+	}{
+		{input.InputDown, "a"}, // Sem foco, foca o primeiro widget
+		{input.InputRight, "b"},
+		{input.InputDown, "d"},
+		{input.InputLeft, "c"},
+		{input.InputUp, "a"},
+		{input.InputUp, "a"}, // Nada acima: o foco fica
+		{input.InputLeft, "a"},
+	}
+	for _, step := range steps {
+		d.Play(input.NewScript().Tap(step.input))
+		if got := d.Focus(); got != step.want {
+			t.Fatalf("focus after %v = %q, want %q", step.input, got, step.want)
+		}
+	}
+}
+
+func TestSpatialNavigationGivesInputToFocusedWidget(t *testing.T) {
+	d := uitest.New(t)
+	grid := newGridScreen()
+	d.Show("grid", grid)
+
+	// Confirm vai para o botão focado
+	d.Play(input.NewScript().Tap(input.InputDown, input.InputRight, input.InputConfirm))
+	if grid.clicked != "b" {
+		t.Errorf("clicked = %q, want b", grid.clicked)
+	}
+
+	// A lista consome as direções até o último item; depois a navegação
+	// espacial volta a mover o foco
+	d.Play(input.NewScript().Tap(input.InputDown, input.InputDown))
+	d.AssertFocus("list")
+	d.Play(input.NewScript().Tap(input.InputDown, input.InputDown))
+	d.AssertFocus("list")
+	if grid.list.FocusedIndex != 2 {
+		t.Errorf("list focused index = %d, want 2", grid.list.FocusedIndex)
+	}
+	d.Play(input.NewScript().Tap(input.InputDown))
+	d.AssertFocus("list")
+
+	d.Play(input.NewScript().Tap(input.InputUp, input.InputUp))
+	if grid.list.FocusedIndex != 0 {
+		t.Errorf("list focused index = %d, want 0", grid.list.FocusedIndex)
+	}
+	d.Play(input.NewScript().Tap(input.InputUp))
+	if got := d.Focus(); got != "c" && got != "d" {
+		t.Errorf("focus after leaving the list = %q, want the row above", got)
+	}
+}
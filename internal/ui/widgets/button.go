@@ -54,6 +54,11 @@ func (b *Button) CanFocus() bool {
 	return b.enabled
 }
 
+// SetEnabled habilita/desabilita o botão
+func (b *Button) SetEnabled(enabled bool) {
+	b.enabled = enabled
+}
+
 func (b *Button) HandleInput(inputType input.InputType) bool {
 	if inputType == input.InputConfirm && b.OnClick != nil {
 		b.OnClick()
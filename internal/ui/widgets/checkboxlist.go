@@ -5,6 +5,8 @@ import (
 	"log"
 	"retroart-sdl2/internal/input"
 	"retroart-sdl2/internal/theme"
+	"strings"
+	"unicode"
 
 	"github.com/TotallyGamerJet/clay"
 )
@@ -45,6 +47,22 @@ type CheckboxList[T any] struct {
 	itemHeight       float32
 	scrollDownHeight float32
 	scrollUpHeight   float32
+	confirmHeld      bool // Confirm pressionado na lista, espera a soltura
+	longPressed      bool // A pressão longa do Confirm atual já agiu
+}
+
+// fastScrollItems é quanto o analógico direito anda a cada evento
+const fastScrollItems = 5
+
+// scrollSteps acelera a lista além da repetição do input: quanto mais tempo
+// uma direção fica pressionada, mais itens cada repetição anda
+var scrollSteps = []struct {
+	held  uint64 // ms pressionado
+	items int
+}{
+	{held: 4000, items: 10},
+	{held: 2000, items: 3},
+	{held: 0, items: 1},
 }
 
 // NewCheckboxList creates and returns a new CheckboxList widget with the specified parameters.
@@ -330,6 +348,16 @@ func (cl *CheckboxList[T]) GetSelectedValues() []T {
 	return values
 }
 
+// FocusedValue retorna o valor do item em foco, ou do último item focado
+// quando a lista perdeu o foco
+func (cl *CheckboxList[T]) FocusedValue() (T, bool) {
+	if cl.FocusedIndex < 0 || cl.FocusedIndex >= len(cl.Items) {
+		var zero T
+		return zero, false
+	}
+	return cl.Items[cl.FocusedIndex].Value, true
+}
+
 // ScrollUp move o foco para o item anterior
 func (cl *CheckboxList[T]) ScrollUp() bool {
 	if !cl.HasFocus || len(cl.Items) == 0 {
@@ -371,6 +399,91 @@ func (cl *CheckboxList[T]) ScrollDown() bool {
 	return false
 }
 
+// scrollBy move o foco count itens, para cima quando negativo. Devolve
+// false quando o foco já estava na ponta.
+func (cl *CheckboxList[T]) scrollBy(count int) bool {
+	moved := false
+	for ; count < 0 && cl.ScrollUp(); count++ {
+		moved = true
+	}
+	for ; count > 0 && cl.ScrollDown(); count-- {
+		moved = true
+	}
+	return moved
+}
+
+// pageUp e pageDown movem o foco uma página inteira
+func (cl *CheckboxList[T]) pageUp() bool {
+	return cl.scrollBy(-cl.getMaxVisibleItems())
+}
+
+func (cl *CheckboxList[T]) pageDown() bool {
+	return cl.scrollBy(cl.getMaxVisibleItems())
+}
+
+// letter devolve a inicial que agrupa um item; números e símbolos ficam
+// juntos em '#'
+func (cl *CheckboxList[T]) letter(index int) rune {
+	label := strings.TrimSpace(cl.Items[index].Label)
+	for _, r := range label {
+		if unicode.IsLetter(r) {
+			return unicode.ToUpper(r)
+		}
+		break
+	}
+	return '#'
+}
+
+// nextLetter move o foco para o primeiro item da próxima inicial
+func (cl *CheckboxList[T]) nextLetter() bool {
+	if cl.FocusedIndex < 0 {
+		return false
+	}
+	current := cl.letter(cl.FocusedIndex)
+	for i := cl.FocusedIndex + 1; i < len(cl.Items); i++ {
+		if cl.letter(i) != current {
+			return cl.scrollBy(i - cl.FocusedIndex)
+		}
+	}
+	return false
+}
+
+// previousLetter move o foco para o primeiro item da inicial anterior
+func (cl *CheckboxList[T]) previousLetter() bool {
+	if cl.FocusedIndex <= 0 {
+		return false
+	}
+	// Início do grupo atual, depois o início do grupo antes dele
+	start := cl.FocusedIndex
+	for start > 0 && cl.letter(start-1) == cl.letter(cl.FocusedIndex) {
+		start--
+	}
+	if start == 0 {
+		return false
+	}
+	previous := start - 1
+	for previous > 0 && cl.letter(previous-1) == cl.letter(start-1) {
+		previous--
+	}
+	return cl.scrollBy(previous - cl.FocusedIndex)
+}
+
+// SetAll marca ou desmarca todos os itens
+func (cl *CheckboxList[T]) SetAll(selected bool) {
+	for i := range cl.Items {
+		cl.Items[i].Selected = selected
+	}
+}
+
+func (cl *CheckboxList[T]) allSelected() bool {
+	for _, item := range cl.Items {
+		if !item.Selected {
+			return false
+		}
+	}
+	return true
+}
+
 func (cl *CheckboxList[T]) toggleFocusedItem() {
 	if !cl.HasFocus || cl.FocusedIndex < 0 || cl.FocusedIndex >= len(cl.Items) {
 		return
@@ -390,6 +503,7 @@ func (cl *CheckboxList[T]) IsFocused() bool {
 
 func (cl *CheckboxList[T]) OnFocusChanged(focused bool) {
 	cl.HasFocus = focused
+	cl.confirmHeld = false
 	if focused && cl.FocusedIndex == -1 && len(cl.Items) > 0 {
 		cl.FocusedIndex = cl.ScrollOffset
 	}
@@ -412,6 +526,74 @@ func (cl *CheckboxList[T]) HandleInput(inputType input.InputType) bool {
 	case input.InputConfirm:
 		cl.toggleFocusedItem()
 		return true
+	case input.InputL1:
+		return cl.pageUp()
+	case input.InputR1:
+		return cl.pageDown()
+	case input.InputL2:
+		return cl.previousLetter()
+	case input.InputR2:
+		return cl.nextLetter()
+	case input.InputScrollUp:
+		return cl.scrollBy(-fastScrollItems)
+	case input.InputScrollDown:
+		return cl.scrollBy(fastScrollItems)
+	default:
+		return false
+	}
+}
+
+// HandleInputEvent acelera a rolagem enquanto uma direção fica pressionada.
+// Confirm marca o item na soltura; mantido pressionado, marca todos os itens,
+// ou desmarca todos quando já estão todos marcados.
+func (cl *CheckboxList[T]) HandleInputEvent(event input.InputEvent) bool {
+	if !cl.HasFocus {
+		return false
+	}
+
+	switch event.Type {
+	case input.InputUp, input.InputDown, input.InputScrollUp, input.InputScrollDown:
+		if !event.Pressed() {
+			return false
+		}
+		steps := 1
+		for _, step := range scrollSteps {
+			if event.Held() >= step.held {
+				steps = step.items
+				break
+			}
+		}
+		if event.Type == input.InputScrollUp || event.Type == input.InputScrollDown {
+			steps *= fastScrollItems
+		}
+		if event.Type == input.InputUp || event.Type == input.InputScrollUp {
+			steps = -steps
+		}
+		return cl.scrollBy(steps)
+
+	case input.InputConfirm:
+		switch event.Phase {
+		case input.PhasePress:
+			cl.confirmHeld = true
+			cl.longPressed = false
+		case input.PhaseLongPress:
+			if !cl.confirmHeld {
+				return false
+			}
+			cl.longPressed = true
+			cl.SetAll(!cl.allSelected())
+			log.Printf("CheckboxList: long press on %s, all items selected=%v", cl.ID, cl.allSelected())
+		case input.PhaseRelease:
+			if !cl.confirmHeld {
+				return false
+			}
+			if !cl.longPressed {
+				cl.toggleFocusedItem()
+			}
+			cl.confirmHeld = false
+		}
+		return true
+
 	default:
 		return false
 	}
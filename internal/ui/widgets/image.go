@@ -0,0 +1,92 @@
+package widgets
+
+import (
+	"image"
+	"image/draw"
+	"log"
+	"unsafe"
+
+	"github.com/TotallyGamerJet/clay"
+	"github.com/veandco/go-sdl2/sdl"
+)
+
+// Image shows a decoded image scaled to its box. While no image is set a
+// placeholder of the same size is drawn, so layouts do not jump when
+// thumbnails arrive.
+type Image struct {
+	ID          string
+	Width       clay.SizingAxis
+	Height      clay.SizingAxis
+	Placeholder clay.Color
+	surface     *sdl.Surface
+}
+
+// NewImage creates an empty image widget
+func NewImage(id string, width, height clay.SizingAxis) *Image {
+	return &Image{
+		ID:          id,
+		Width:       width,
+		Height:      height,
+		Placeholder: getDesignSystem().Colors.SurfaceSecondary,
+	}
+}
+
+// SetImage replaces the displayed image; nil clears it. Must be called on
+// the main thread.
+func (img *Image) SetImage(src image.Image) {
+	img.Free()
+	if src == nil {
+		return
+	}
+
+	bounds := src.Bounds()
+	surface, err := sdl.CreateRGBSurfaceWithFormat(0, int32(bounds.Dx()), int32(bounds.Dy()), 32, uint32(sdl.PIXELFORMAT_ABGR8888))
+	if err != nil {
+		log.Printf("Image: failed to create surface for '%s': %v", img.ID, err)
+		return
+	}
+
+	// ABGR8888 is R, G, B, A in memory, the layout of image.NRGBA
+	rgba := &image.NRGBA{
+		Pix:    surface.Pixels(),
+		Stride: int(surface.Pitch),
+		Rect:   image.Rect(0, 0, bounds.Dx(), bounds.Dy()),
+	}
+	draw.Draw(rgba, rgba.Rect, src, bounds.Min, draw.Src)
+	img.surface = surface
+}
+
+// HasImage reports whether an image is set
+func (img *Image) HasImage() bool {
+	return img.surface != nil
+}
+
+// Free releases the SDL surface
+func (img *Image) Free() {
+	if img.surface != nil {
+		img.surface.Free()
+		img.surface = nil
+	}
+}
+
+// Render draws the image or its placeholder
+func (img *Image) Render() {
+	if img.surface == nil {
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID(img.ID),
+			Layout: clay.LayoutConfig{
+				Sizing: clay.Sizing{Width: img.Width, Height: img.Height},
+			},
+			BackgroundColor: img.Placeholder,
+		}, nil)
+		return
+	}
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID(img.ID),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{Width: img.Width, Height: img.Height},
+		},
+		Image: clay.ImageElementConfig{ImageData: unsafe.Pointer(img.surface)},
+	}, nil)
+}
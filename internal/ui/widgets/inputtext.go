@@ -4,6 +4,7 @@ import (
 	"log"
 	"retroart-sdl2/internal/input"
 	"retroart-sdl2/internal/theme"
+	"strings"
 
 	"github.com/TotallyGamerJet/clay"
 )
@@ -22,6 +23,7 @@ type InputText struct {
 	focused     bool
 	enabled     bool
 	showCursor  bool
+	masked      bool
 	keyboard    *VirtualKeyboard
 }
 
@@ -246,13 +248,22 @@ func (it *InputText) Render() {
 }
 
 func (it *InputText) getDisplayText() string {
+	text := it.Text
+	if it.masked {
+		text = strings.Repeat("*", len(it.Text))
+	}
 	if it.focused && it.showCursor {
 		// Adicionar cursor visual na posição atual
-		left := it.Text[:it.CursorPos]
-		right := it.Text[it.CursorPos:]
+		left := text[:it.CursorPos]
+		right := text[it.CursorPos:]
 		return left + "|" + right
 	}
-	return it.Text
+	return text
+}
+
+// SetMasked esconde o texto atrás de asteriscos, para senhas
+func (it *InputText) SetMasked(masked bool) {
+	it.masked = masked
 }
 
 // SetEnabled habilita/desabilita o campo de texto
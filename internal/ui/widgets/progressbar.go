@@ -0,0 +1,78 @@
+package widgets
+
+import (
+	"fmt"
+	"retroart-sdl2/internal/theme"
+
+	"github.com/TotallyGamerJet/clay"
+)
+
+// ProgressBar is a non focusable horizontal bar showing a completion ratio
+type ProgressBar struct {
+	ID       string
+	Width    clay.SizingAxis
+	Config   theme.ProgressBarStyle
+	progress float32
+}
+
+// NewProgressBar creates a progress bar with the given width
+func NewProgressBar(id string, width clay.SizingAxis) *ProgressBar {
+	return &ProgressBar{
+		ID:     id,
+		Width:  width,
+		Config: theme.GetProgressBarStyle(),
+	}
+}
+
+// SetProgress sets the completion ratio, clamped to [0, 1]
+func (pb *ProgressBar) SetProgress(progress float32) {
+	pb.progress = max(0, min(1, progress))
+}
+
+// Progress returns the current completion ratio
+func (pb *ProgressBar) Progress() float32 {
+	return pb.progress
+}
+
+// Render draws the bar with the fill proportional to the progress and the
+// percentage floating on top of it
+func (pb *ProgressBar) Render() {
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID(pb.ID),
+		Layout: clay.LayoutConfig{
+			Sizing: clay.Sizing{
+				Width:  pb.Width,
+				Height: clay.SizingFixed(pb.Config.Height),
+			},
+		},
+		CornerRadius:    clay.CornerRadiusAll(pb.Config.CornerRadius),
+		BackgroundColor: pb.Config.BackgroundColor,
+	}, func() {
+		if pb.progress > 0 {
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID(pb.ID + "-fill"),
+				Layout: clay.LayoutConfig{
+					Sizing: clay.Sizing{
+						Width:  clay.SizingPercent(pb.progress),
+						Height: clay.SizingGrow(0),
+					},
+				},
+				CornerRadius:    clay.CornerRadiusAll(pb.Config.CornerRadius),
+				BackgroundColor: pb.Config.FillColor,
+			}, nil)
+		}
+
+		clay.UI()(clay.ElementDeclaration{
+			Id: clay.ID(pb.ID + "-label"),
+			Floating: clay.FloatingElementConfig{
+				AttachTo: clay.ATTACH_TO_PARENT,
+				AttachPoints: clay.FloatingAttachPoints{
+					Parent:  clay.ATTACH_POINT_CENTER_CENTER,
+					Element: clay.ATTACH_POINT_CENTER_CENTER,
+				},
+			},
+		}, func() {
+			Text(fmt.Sprintf("%.0f%%", pb.progress*100), pb.Config.FontSize, pb.Config.TextColor)
+		})
+	})
+}
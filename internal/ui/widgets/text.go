@@ -82,3 +82,33 @@ func TextMuted(content string, fontSize uint16) {
 	ds := getDesignSystem()
 	Text(content, fontSize, ds.Colors.TextMuted)
 }
+
+// Wrapped text, for paragraphs such as game descriptions. The parent must
+// bound the width, e.g. with clay.SizingGrow or clay.SizingFixed, or the
+// text grows on a single line.
+
+// TextWrapped creates text broken into lines at word boundaries
+func TextWrapped(content string, fontSize uint16, color clay.Color) {
+	fontId := theme.GetFontIdForSize(fontSize)
+
+	textConfig := &clay.TextElementConfig{
+		FontId:    fontId,
+		FontSize:  fontSize,
+		TextColor: color,
+		WrapMode:  clay.TEXT_WRAP_WORDS,
+	}
+
+	clay.Text(content, textConfig)
+}
+
+// TextSmallWrapped creates wrapped text with Small typography size
+func TextSmallWrapped(content string, color clay.Color) {
+	ds := getDesignSystem()
+	TextWrapped(content, ds.Typography.Small, color)
+}
+
+// TextBaseWrapped creates wrapped text with Base typography size
+func TextBaseWrapped(content string, color clay.Color) {
+	ds := getDesignSystem()
+	TextWrapped(content, ds.Typography.Base, color)
+}
@@ -0,0 +1,97 @@
+package widgets
+
+import (
+	"fmt"
+	"time"
+
+	"retroart-sdl2/internal/theme"
+
+	"github.com/TotallyGamerJet/clay"
+)
+
+// maxToasts limita quantas notificações aparecem ao mesmo tempo
+const maxToasts = 3
+
+type toastMessage struct {
+	text  string
+	until time.Time
+}
+
+// Toast mostra notificações curtas flutuando sobre a tela atual. Não recebe
+// foco; cada mensagem some sozinha depois da sua duração.
+type Toast struct {
+	ID       string
+	Config   theme.ToastStyle
+	messages []toastMessage
+}
+
+// NewToast cria o widget de notificações
+func NewToast(id string) *Toast {
+	return &Toast{
+		ID:     id,
+		Config: theme.GetToastStyle(),
+	}
+}
+
+// Show adiciona uma mensagem visível pela duração dada. Quando já há
+// mensagens demais, a mais antiga sai.
+func (t *Toast) Show(text string, duration time.Duration) {
+	t.messages = append(t.messages, toastMessage{text: text, until: time.Now().Add(duration)})
+	if len(t.messages) > maxToasts {
+		t.messages = t.messages[len(t.messages)-maxToasts:]
+	}
+}
+
+// Update remove as mensagens expiradas
+func (t *Toast) Update() {
+	now := time.Now()
+	visible := t.messages[:0]
+	for _, message := range t.messages {
+		if now.Before(message.until) {
+			visible = append(visible, message)
+		}
+	}
+	t.messages = visible
+}
+
+// Render desenha as mensagens na parte de baixo da janela, acima de tudo
+func (t *Toast) Render() {
+	if len(t.messages) == 0 {
+		return
+	}
+
+	clay.UI()(clay.ElementDeclaration{
+		Id: clay.ID(t.ID),
+		Floating: clay.FloatingElementConfig{
+			AttachTo: clay.ATTACH_TO_ROOT,
+			Offset:   clay.Vector2{X: 0, Y: -24},
+			ZIndex:   100,
+			AttachPoints: clay.FloatingAttachPoints{
+				Parent:  clay.ATTACH_POINT_CENTER_BOTTOM,
+				Element: clay.ATTACH_POINT_CENTER_BOTTOM,
+			},
+			PointerCaptureMode: clay.POINTER_CAPTURE_MODE_PASSTHROUGH,
+		},
+		Layout: clay.LayoutConfig{
+			ChildGap:        t.Config.ChildGap,
+			LayoutDirection: clay.TOP_TO_BOTTOM,
+			ChildAlignment: clay.ChildAlignment{
+				X: clay.ALIGN_X_CENTER,
+			},
+		},
+	}, func() {
+		for i, message := range t.messages {
+			clay.UI()(clay.ElementDeclaration{
+				Id: clay.ID(fmt.Sprintf("%s-%d", t.ID, i)),
+				Layout: clay.LayoutConfig{
+					Padding: t.Config.Padding,
+				},
+				CornerRadius:    clay.CornerRadiusAll(t.Config.CornerRadius),
+				BackgroundColor: t.Config.BackgroundColor,
+				Border:          t.Config.Border,
+			}, func() {
+				Text(message.text, t.Config.FontSize, t.Config.TextColor)
+			})
+		}
+	})
+}
@@ -0,0 +1,182 @@
+// Package uitest dirige telas com inputs roteirizados para testes de telas e
+// widgets. Roda com o driver de vídeo dummy do SDL, sem janela nem
+// dispositivos, e deixa o teste conferir o foco e o estado dos widgets.
+//
+//	d := uitest.New(t)
+//	d.Show("home", screen.NewHome(systems, nil, nil))
+//	d.Play(input.NewScript().Tap(input.InputDown).LongPress(input.InputConfirm))
+//	d.AssertFocus("consoles-checkbox-list")
+//	list := uitest.Widget[*widgets.CheckboxList[library.System]](d, "consoles-checkbox-list")
+//
+// O Layout é um singleton, então os testes que usam o Driver não podem rodar
+// em paralelo.
+package uitest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/veandco/go-sdl2/sdl"
+	"github.com/veandco/go-sdl2/ttf"
+
+	"retroart-sdl2/internal/core"
+	"retroart-sdl2/internal/input"
+	"retroart-sdl2/internal/screen"
+	"retroart-sdl2/internal/theme"
+	"retroart-sdl2/internal/ui"
+)
+
+// fontAsset é a fonte do projeto, procurada a partir do diretório do teste
+const fontAsset = "assets/DejaVuSansCondensed.ttf"
+
+var (
+	setupOnce sync.Once
+	setupErr  error
+	renderer  *sdl.Renderer
+	layout    *ui.Layout
+)
+
+// setup inicia o SDL com o driver dummy e cria o Layout, uma vez por
+// processo. A janela e o renderer ficam abertos até o fim dos testes.
+func setup() error {
+	setupOnce.Do(func() {
+		os.Setenv("SDL_VIDEODRIVER", "dummy")
+		if err := sdl.Init(sdl.INIT_VIDEO); err != nil {
+			setupErr = fmt.Errorf("failed to initialize SDL: %w", err)
+			return
+		}
+		if err := ttf.Init(); err != nil {
+			setupErr = fmt.Errorf("failed to initialize TTF: %w", err)
+			return
+		}
+
+		window, err := sdl.CreateWindow("RetroArt test", 0, 0, core.WINDOW_WIDTH, core.WINDOW_HEIGHT, sdl.WINDOW_HIDDEN)
+		if err != nil {
+			setupErr = fmt.Errorf("failed to create window: %w", err)
+			return
+		}
+		renderer, err = sdl.CreateRenderer(window, -1, sdl.RENDERER_SOFTWARE)
+		if err != nil {
+			setupErr = fmt.Errorf("failed to create renderer: %w", err)
+			return
+		}
+
+		fontSystem := theme.NewFontSystem()
+		fontSystem.SetPreferredFont(findAsset(fontAsset))
+		if err := fontSystem.InitializeFonts(); err != nil {
+			setupErr = fmt.Errorf("failed to initialize fonts: %w", err)
+			return
+		}
+		layout, setupErr = ui.NewLayout(renderer, fontSystem)
+	})
+	return setupErr
+}
+
+// findAsset procura o arquivo subindo a partir do diretório atual, já que
+// os testes rodam no diretório do pacote e não na raiz do módulo
+func findAsset(name string) string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	for {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Driver roda frames de uma tela: entrega os inputs, atualiza e desenha,
+// como o loop principal do App
+type Driver struct {
+	tb      testing.TB
+	Manager *screen.Manager
+	frame   uint64
+}
+
+// New prepara um Driver com a navegação espacial vazia. Deve ser chamado
+// antes de criar as telas, que registram seus widgets ao serem construídas.
+func New(tb testing.TB) *Driver {
+	tb.Helper()
+	if err := setup(); err != nil {
+		tb.Fatalf("uitest: %v", err)
+	}
+	layout.GetSpatialNavigation().Clear()
+	return &Driver{tb: tb, Manager: screen.NewManager(layout)}
+}
+
+// Show adiciona a tela, a torna atual e roda um frame, para a navegação
+// conhecer a posição dos widgets antes do primeiro input
+func (d *Driver) Show(name string, s screen.Screen) {
+	d.Manager.AddScreen(name, s)
+	d.Manager.SetCurrentScreen(name)
+	d.Frame(nil)
+}
+
+// Frame roda um frame com os eventos dados
+func (d *Driver) Frame(events []input.InputEvent) {
+	for _, event := range events {
+		d.Manager.HandleEvent(event)
+	}
+	d.Manager.Update()
+
+	renderer.SetDrawColor(0, 0, 0, 255)
+	renderer.Clear()
+	d.Manager.Render()
+	renderer.Present()
+	d.frame++
+}
+
+// Play roda o script até o último frame
+func (d *Driver) Play(script *input.Script) {
+	playback := script.Playback()
+	for range script.Frames() {
+		d.Frame(playback.Next())
+	}
+}
+
+// Replay roda uma reprodução até o último evento, por exemplo de uma
+// gravação carregada com input.LoadRecording
+func (d *Driver) Replay(playback *input.Playback) {
+	for !playback.Done() {
+		d.Frame(playback.Next())
+	}
+}
+
+// Frames devolve quantos frames já rodaram
+func (d *Driver) Frames() uint64 {
+	return d.frame
+}
+
+// Focus devolve o ID do widget focado, vazio quando nenhum
+func (d *Driver) Focus() string {
+	return layout.GetSpatialNavigation().GetCurrentFocus()
+}
+
+// AssertFocus falha o teste quando o foco não está no widget
+func (d *Driver) AssertFocus(id string) {
+	d.tb.Helper()
+	if got := d.Focus(); got != id {
+		d.tb.Errorf("focus is on %q after %d frames, want %q", got, d.frame, id)
+	}
+}
+
+// Widget devolve o widget registrado com o ID como W, para o teste conferir
+// o seu estado. Falha o teste quando não existe ou é de outro tipo.
+func Widget[W ui.Focusable](d *Driver, id string) W {
+	d.tb.Helper()
+	widget, ok := layout.GetSpatialNavigation().GetWidget(id).(W)
+	if !ok {
+		d.tb.Fatalf("no widget %q of type %T", id, widget)
+	}
+	return widget
+}